@@ -94,6 +94,8 @@ func Test_TinkerStyle_CommunityCustomization_ValueDrift_ToResearchDebugSession(t
 	}
 
 	synapse := NewSynapse(configs)
+	// Repeated signals differ only in timestamp; keep each one instead of deduplicating
+	synapse.ConflictResolver = nil
 
 	compositionListener := &TestPatternListener{}
 	compositionWatcher := NewPatternCompositionWatcher(spec, synapse, compositionListener)
@@ -86,6 +86,8 @@ func Test_TinkerStyle_SpecLockedFineTune_DriftToReleaseGate(t *testing.T) {
 	}
 
 	synapse := NewSynapse(configs)
+	// Repeated signals differ only in timestamp; keep each one instead of deduplicating
+	synapse.ConflictResolver = nil
 
 	compositionListener := &TestCompositionListener{}
 	compositionWatcher := NewPatternCompositionWatcher(spec, synapse, compositionListener)
@@ -0,0 +1,319 @@
+package event_network
+
+import (
+	"container/heap"
+	"errors"
+	"sync"
+)
+
+// BoundedEventNetwork caps how many events a network keeps, so a streaming deployment
+// ingesting continuously does not grow without bound.
+//
+// Once more than MaxEvents events were added through it, the oldest (by Timestamp) leaf
+// events that nothing was derived from are evicted. Events that contributed to a derived
+// event, and derived events themselves, are never evicted, so the cap is soft: when every
+// remaining event is referenced the network stays above it.
+//
+// The event being added is never evicted by its own insertion. While eviction is held
+// (see EvictionHolder) nothing is evicted; ReleaseEviction catches up.
+//
+// Evictions drop the event's edges (see RemoveEvent) and, when Memory is set, bump its
+// revisions so cached structural queries do not return evicted events.
+type BoundedEventNetwork struct {
+	EventNetwork
+
+	// MaxEvents is the number of events to keep; <= 0 disables eviction.
+	MaxEvents int
+	// Memory (optional) is notified of evictions, as MemoizedNetwork does on RemoveEvent.
+	Memory StructuralMemory
+
+	mu sync.Mutex
+	// events added through this network that are still stored
+	tracked map[EventID]*boundedEntry
+	// the tracked events that are currently evictable leaves
+	leaves boundedHeap
+	seq    uint64
+	holds  int
+}
+
+// EvictionHolder is an optional EventNetwork extension for networks that evict events on
+// their own, such as BoundedEventNetwork. A derived event is stored before it is linked to
+// its contributors, so SynapseRuntime holds eviction while it ingests or materializes:
+// otherwise a contributor could be evicted in between. Holds nest.
+type EvictionHolder interface {
+	HoldEviction()
+	// ReleaseEviction ends a hold; releasing the last one runs the deferred eviction.
+	ReleaseEviction() error
+}
+
+func NewBoundedEventNetwork(base EventNetwork, maxEvents int) *BoundedEventNetwork {
+	return &BoundedEventNetwork{
+		EventNetwork: base,
+		MaxEvents:    maxEvents,
+		tracked:      make(map[EventID]*boundedEntry),
+	}
+}
+
+func (b *BoundedEventNetwork) AddEvent(event Event) (EventID, error) {
+	stored, err := b.AddEventFull(event)
+	return stored.ID, err
+}
+
+// AddEventFull stores event, then evicts down to MaxEvents. An eviction error is
+// returned together with the stored event.
+func (b *BoundedEventNetwork) AddEventFull(event Event) (Event, error) {
+	stored, err := b.EventNetwork.AddEventFull(event)
+	if err != nil {
+		return stored, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.track(stored)
+	return stored, b.evict(stored.ID)
+}
+
+func (b *BoundedEventNetwork) AddEdge(from EventID, to EventID, relation string) error {
+	if err := b.EventNetwork.AddEdge(from, to, relation); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// from now contributes to a derived event and to has a contributor: neither is evictable
+	b.pin(from)
+	b.pin(to)
+	return nil
+}
+
+func (b *BoundedEventNetwork) RemoveEvent(id EventID) error {
+	in, err := b.EventNetwork.InEdges(id)
+	if err != nil {
+		return err
+	}
+	out, err := b.EventNetwork.OutEdges(id)
+	if err != nil {
+		return err
+	}
+	if err := b.EventNetwork.RemoveEvent(id); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.untrack(id)
+	// Neighbours may have lost their last edge
+	var errs []error
+	for _, edge := range in {
+		errs = append(errs, b.reconsider(edge.From))
+	}
+	for _, edge := range out {
+		errs = append(errs, b.reconsider(edge.To))
+	}
+	return errors.Join(errs...)
+}
+
+// UpdateEvent delegates to the base network when it supports EventUpdater.
+func (b *BoundedEventNetwork) UpdateEvent(event Event) error {
+	updater, ok := b.EventNetwork.(EventUpdater)
+	if !ok {
+		return errors.New("network does not support replacing events")
+	}
+	if err := updater.UpdateEvent(event); err != nil {
+		return err
+	}
+	stored, err := b.EventNetwork.GetByID(event.ID)
+	if err != nil {
+		return err
+	}
+
+	// The timestamp may have changed: re-sort the event
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if entry, ok := b.tracked[event.ID]; ok {
+		entry.event = stored
+		if entry.index >= 0 {
+			heap.Fix(&b.leaves, entry.index)
+		}
+	}
+	return nil
+}
+
+// ParentlessByType delegates to the base network when it supports ParentlessIndex,
+// otherwise it filters GetByType results by Parents().
+func (b *BoundedEventNetwork) ParentlessByType(eventType EventType) ([]Event, error) {
+	if idx, ok := b.EventNetwork.(ParentlessIndex); ok {
+		return idx.ParentlessByType(eventType)
+	}
+	return parentlessByTypeSlow(b.EventNetwork, eventType)
+}
+
+func (b *BoundedEventNetwork) HoldEviction() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.holds++
+}
+
+func (b *BoundedEventNetwork) ReleaseEviction() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.holds > 0 {
+		b.holds--
+	}
+	return b.evict(EventID{})
+}
+
+// Len returns how many events added through this network are still stored.
+func (b *BoundedEventNetwork) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.tracked)
+}
+
+// evict removes the oldest evictable events (other than keep) until at most MaxEvents
+// remain. It does nothing while eviction is held.
+func (b *BoundedEventNetwork) evict(keep EventID) error {
+	if b.MaxEvents <= 0 || b.holds > 0 {
+		return nil
+	}
+
+	var kept *boundedEntry
+	defer func() {
+		if kept != nil {
+			heap.Push(&b.leaves, kept)
+		}
+	}()
+
+	for len(b.tracked) > b.MaxEvents && b.leaves.Len() > 0 {
+		entry := heap.Pop(&b.leaves).(*boundedEntry)
+		if entry.event.ID == keep {
+			kept = entry
+			continue
+		}
+		// Edges added on the base network directly are only noticed here
+		evictable, err := b.evictable(entry.event.ID)
+		if err != nil {
+			heap.Push(&b.leaves, entry)
+			return err
+		}
+		if !evictable {
+			continue
+		}
+
+		if err := b.EventNetwork.RemoveEvent(entry.event.ID); err != nil {
+			heap.Push(&b.leaves, entry)
+			return err
+		}
+		delete(b.tracked, entry.event.ID)
+		if b.Memory != nil {
+			notifyEventRemoved(b.Memory, entry.event, nil, nil)
+		}
+	}
+	return nil
+}
+
+// evictable reports whether id is a leaf nothing was derived from.
+func (b *BoundedEventNetwork) evictable(id EventID) (bool, error) {
+	in, err := b.EventNetwork.InEdges(id)
+	if err != nil || len(in) > 0 {
+		return false, err
+	}
+	out, err := b.EventNetwork.OutEdges(id)
+	if err != nil {
+		return false, err
+	}
+	return len(out) == 0, nil
+}
+
+// track starts tracking a newly stored event. It has no edges yet, so it is evictable.
+func (b *BoundedEventNetwork) track(ev Event) {
+	if b.tracked == nil {
+		b.tracked = make(map[EventID]*boundedEntry)
+	}
+	b.seq++
+	entry := &boundedEntry{event: ev, seq: b.seq}
+	b.tracked[ev.ID] = entry
+	heap.Push(&b.leaves, entry)
+}
+
+// untrack stops tracking id.
+func (b *BoundedEventNetwork) untrack(id EventID) {
+	entry, ok := b.tracked[id]
+	if !ok {
+		return
+	}
+	if entry.index >= 0 {
+		heap.Remove(&b.leaves, entry.index)
+	}
+	delete(b.tracked, id)
+}
+
+// pin takes id out of the evictable leaves.
+func (b *BoundedEventNetwork) pin(id EventID) {
+	if entry, ok := b.tracked[id]; ok && entry.index >= 0 {
+		heap.Remove(&b.leaves, entry.index)
+	}
+}
+
+// reconsider puts id back among the evictable leaves once it has no edges left.
+func (b *BoundedEventNetwork) reconsider(id EventID) error {
+	entry, ok := b.tracked[id]
+	if !ok || entry.index >= 0 {
+		return nil
+	}
+	evictable, err := b.evictable(id)
+	if err != nil || !evictable {
+		return err
+	}
+	heap.Push(&b.leaves, entry)
+	return nil
+}
+
+// boundedEntry is an event tracked by BoundedEventNetwork.
+type boundedEntry struct {
+	event Event
+	// insertion order, breaks Timestamp ties
+	seq uint64
+	// position in leaves, -1 when the event is not evictable
+	index int
+}
+
+// boundedHeap is a container/heap of evictable entries, oldest first.
+type boundedHeap []*boundedEntry
+
+func (h boundedHeap) Len() int { return len(h) }
+
+func (h boundedHeap) Less(i, j int) bool {
+	if !h[i].event.Timestamp.Equal(h[j].event.Timestamp) {
+		return h[i].event.Timestamp.Before(h[j].event.Timestamp)
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h boundedHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *boundedHeap) Push(x any) {
+	entry := x.(*boundedEntry)
+	entry.index = len(*h)
+	*h = append(*h, entry)
+}
+
+func (h *boundedHeap) Pop() any {
+	old := *h
+	entry := old[len(old)-1]
+	old[len(old)-1] = nil
+	entry.index = -1
+	*h = old[:len(old)-1]
+	return entry
+}
@@ -0,0 +1,162 @@
+package event_network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoundedEventNetwork_EvictsOldestLeaves(t *testing.T) {
+	baseTime := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	memory := NewInMemoryStructuralMemory()
+	network := NewBoundedEventNetwork(NewInMemoryEventNetwork(), 3)
+	network.Memory = memory
+
+	add := func(minutes int) EventID {
+		id, err := network.AddEvent(Event{
+			EventType:   CpuStatusChanged,
+			EventDomain: InfraDomain,
+			Timestamp:   baseTime.Add(time.Duration(minutes) * time.Minute),
+		})
+		require.NoError(t, err)
+		return id
+	}
+
+	// Inserted out of order: eviction follows Timestamp, not insertion
+	second := add(2)
+	first := add(0)
+	middle := add(1)
+	require.Equal(t, 3, network.Len())
+
+	rev := memory.TypeRev(CpuStatusChanged)
+	third := add(3)
+	require.Equal(t, 3, network.Len())
+	_, err := network.GetByID(first)
+	require.Error(t, err, "oldest leaf is evicted")
+	require.Greater(t, memory.TypeRev(CpuStatusChanged), rev, "eviction bumps the type revision")
+
+	fourth := add(4)
+	_, err = network.GetByID(middle)
+	require.Error(t, err)
+
+	events, err := network.GetByType(CpuStatusChanged)
+	require.NoError(t, err)
+	require.Equal(t, []EventID{second, third, fourth}, eventIDs(events))
+}
+
+func TestBoundedEventNetwork_KeepsReferencedEvents(t *testing.T) {
+	baseTime := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	network := NewBoundedEventNetwork(NewInMemoryEventNetwork(), 2)
+
+	add := func(eventType EventType, minutes int) EventID {
+		id, err := network.AddEvent(Event{
+			EventType:   eventType,
+			EventDomain: InfraDomain,
+			Timestamp:   baseTime.Add(time.Duration(minutes) * time.Minute),
+		})
+		require.NoError(t, err)
+		return id
+	}
+
+	contributor := add(CpuStatusChanged, 0)
+	isolated := add(CpuStatusChanged, 1)
+
+	// Held until the derived event is linked: the contributor would otherwise be evicted first
+	network.HoldEviction()
+	derived := add(CpuCritical, 1)
+	require.NoError(t, network.AddEdge(contributor, derived, "trigger"))
+	require.Equal(t, 3, network.Len())
+	require.NoError(t, network.ReleaseEviction())
+	_, err := network.GetByID(isolated)
+	require.Error(t, err, "the unreferenced leaf is evicted on release")
+	require.Equal(t, 2, network.Len())
+
+	// The cap is soft: the contributor and the derived event stay
+	latest := add(CpuStatusChanged, 2)
+	require.Equal(t, 3, network.Len())
+	_, err = network.GetByID(latest)
+	require.NoError(t, err, "the added event is never evicted by its own insertion")
+	children, err := network.Children(derived)
+	require.NoError(t, err)
+	require.Equal(t, []EventID{contributor}, eventIDs(children))
+
+	t.Run("contributors survive materialization", func(t *testing.T) {
+		synapse := newSynapseStoringDuplicates([]PatternConfig{})
+		bounded := NewBoundedEventNetwork(synapse.Network, 2)
+		bounded.Memory = synapse.Memory
+		synapse.Network = bounded
+
+		peers := NewCondition().HasPeers(MinorTremors, Conditions{
+			Counter: &Counter{HowMany: 1, HowManyOrMore: true},
+		})
+		synapse.RegisterRule(MinorTremors, NewDeriveEventRule("tremors", peers, getMinorTremorDerivedEventTemplate()))
+
+		for i := 0; i < 2; i++ {
+			_, err := synapse.Ingest(Event{
+				EventType:   CpuStatusChanged,
+				EventDomain: InfraDomain,
+				Timestamp:   baseTime.Add(time.Duration(i) * time.Minute),
+			})
+			require.NoError(t, err)
+		}
+		_, err := synapse.Ingest(createMinorTremorsEvent(baseTime.Add(2 * time.Minute)))
+		require.NoError(t, err)
+		result, err := synapse.IngestWithResult(createMinorTremorsEvent(baseTime.Add(3 * time.Minute)))
+		require.NoError(t, err)
+		require.Len(t, result.Derived, 1)
+
+		children, err := bounded.Children(result.Derived[0].ID)
+		require.NoError(t, err)
+		require.Len(t, children, 2)
+		require.NoError(t, synapse.Validate())
+	})
+}
+
+func TestBoundedEventNetwork_TracksEvictableLeaves(t *testing.T) {
+	baseTime := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	network := NewBoundedEventNetwork(NewInMemoryEventNetwork(), 3)
+
+	add := func(eventType EventType, minutes int) EventID {
+		id, err := network.AddEvent(Event{
+			EventType:   eventType,
+			EventDomain: InfraDomain,
+			Timestamp:   baseTime.Add(time.Duration(minutes) * time.Minute),
+		})
+		require.NoError(t, err)
+		return id
+	}
+
+	contributor := add(CpuStatusChanged, 0)
+	derived := add(CpuCritical, 1)
+	require.NoError(t, network.AddEdge(contributor, derived, "trigger"))
+	isolated := add(CpuStatusChanged, 2)
+	require.Len(t, network.leaves, 1, "only the isolated leaf is evictable")
+
+	// Once the derived event is gone, its contributor is an unreferenced leaf again
+	require.NoError(t, network.RemoveEvent(derived))
+	require.Len(t, network.leaves, 2)
+
+	// Moving the isolated leaf before the contributor makes it the oldest
+	require.NoError(t, network.UpdateEvent(Event{
+		ID:          isolated,
+		EventType:   CpuStatusChanged,
+		EventDomain: InfraDomain,
+		Timestamp:   baseTime.Add(-time.Minute),
+	}))
+	add(CpuStatusChanged, 3)
+	add(CpuStatusChanged, 4)
+	_, err := network.GetByID(isolated)
+	require.Error(t, err, "the re-timed leaf is evicted first")
+	_, err = network.GetByID(contributor)
+	require.NoError(t, err)
+	require.Equal(t, 3, network.Len())
+}
+
+func eventIDs(events []Event) []EventID {
+	ids := make([]EventID, 0, len(events))
+	for _, ev := range events {
+		ids = append(ids, ev.ID)
+	}
+	return ids
+}
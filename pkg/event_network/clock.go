@@ -0,0 +1,19 @@
+package event_network
+
+import "time"
+
+// Clock supplies the current time. Components that reason about recency take a Clock
+// so tests (and replays) can control time instead of depending on the wall clock.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the wall clock.
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time { return time.Now() }
+
+// ClockFunc adapts a plain function to Clock.
+type ClockFunc func() time.Time
+
+func (f ClockFunc) Now() time.Time { return f() }
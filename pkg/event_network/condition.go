@@ -1,5 +1,7 @@
 package event_network
 
+import "time"
+
 /*
 ========================
 Condition (static)
@@ -30,6 +32,16 @@ func (c *Condition) Or() *Condition {
 	return c
 }
 
+func (c *Condition) Xor() *Condition {
+	c.tokens = append(c.tokens, specToken{kind: tkOp, op: opXor})
+	return c
+}
+
+func (c *Condition) Not() *Condition {
+	c.tokens = append(c.tokens, specToken{kind: tkOp, op: opNot})
+	return c
+}
+
 func (c *Condition) Group() *Condition {
 	c.tokens = append(c.tokens, specToken{kind: tkLParen})
 	return c
@@ -52,6 +64,18 @@ func (c *Condition) IsTypeOf(eventType EventType, cond Conditions) *Condition {
 	return c
 }
 
+func (c *Condition) IsAnyOfTypes(eventTypes []EventType, cond Conditions) *Condition {
+	c.tokens = append(c.tokens, specToken{
+		kind: tkTerm,
+		term: specTerm{
+			kind:       termIsAnyOfTypes,
+			eventTypes: append([]EventType(nil), eventTypes...),
+			cond:       cond,
+		},
+	})
+	return c
+}
+
 func (c *Condition) InDomain(domain EventDomain) *Condition {
 	c.tokens = append(c.tokens, specToken{
 		kind: tkTerm,
@@ -77,6 +101,19 @@ func (c *Condition) HasDescendants(eventType EventType, cond Conditions) *Condit
 	return c.addRelation(termHasDescendants, eventType, cond)
 }
 
+func (c *Condition) HasExactlyNDescendants(eventType EventType, n int, maxDepth int) *Condition {
+	c.tokens = append(c.tokens, specToken{
+		kind: tkTerm,
+		term: specTerm{
+			kind:       termHasExactlyNDescendants,
+			eventType:  eventType,
+			cond:       Conditions{MaxDepth: maxDepth},
+			exactCount: n,
+		},
+	})
+	return c
+}
+
 func (c *Condition) HasSiblings(eventType EventType, cond Conditions) *Condition {
 	return c.addRelation(termHasSiblings, eventType, cond)
 }
@@ -85,10 +122,89 @@ func (c *Condition) HasPeers(eventType EventType, cond Conditions) *Condition {
 	return c.addRelation(termHasPeers, eventType, cond)
 }
 
+func (c *Condition) HasSiblingDerivedType(derivedType EventType, cond Conditions) *Condition {
+	return c.addRelation(termHasSiblingDerivedType, derivedType, cond)
+}
+
 func (c *Condition) HasCousin(eventType EventType, cond Conditions) *Condition {
 	return c.addRelation(termHasCousin, eventType, cond)
 }
 
+func (c *Condition) PeerPropertyRelated(
+	eventType EventType,
+	propKey string,
+	rel PropertyRelation,
+	cond Conditions,
+) *Condition {
+	c.tokens = append(c.tokens, specToken{
+		kind: tkTerm,
+		term: specTerm{
+			kind:      termPeerPropertyRelated,
+			eventType: eventType,
+			cond:      cond,
+			propKey:   propKey,
+			rel:       rel,
+		},
+	})
+	return c
+}
+
+func (c *Condition) HasPeersWhere(eventType EventType, pred PeerPredicate, cond Conditions) *Condition {
+	c.tokens = append(c.tokens, specToken{
+		kind: tkTerm,
+		term: specTerm{
+			kind:      termHasPeersWhere,
+			eventType: eventType,
+			cond:      cond,
+			peerPred:  pred,
+		},
+	})
+	return c
+}
+
+func (c *Condition) PeersScore(
+	eventType EventType,
+	halfLife TimeWindow,
+	threshold float64,
+	cond Conditions,
+) *Condition {
+	c.tokens = append(c.tokens, specToken{
+		kind: tkTerm,
+		term: specTerm{
+			kind:      termPeersScore,
+			eventType: eventType,
+			cond:      cond,
+			halfLife:  halfLife,
+			threshold: threshold,
+		},
+	})
+	return c
+}
+
+func (c *Condition) HasChildrenAcrossDomains(minDomains int, cond Conditions) *Condition {
+	c.tokens = append(c.tokens, specToken{
+		kind: tkTerm,
+		term: specTerm{
+			kind:       termHasChildrenAcrossDomains,
+			cond:       cond,
+			minDomains: minDomains,
+		},
+	})
+	return c
+}
+
+func (c *Condition) HasCompositionAncestor(compositionID string, cond Conditions) *Condition {
+	c.tokens = append(c.tokens, specToken{
+		kind: tkTerm,
+		term: specTerm{
+			kind:          termHasCompositionAncestor,
+			compositionID: compositionID,
+			cond:          cond,
+		},
+	})
+	return c
+}
+
 /*
 ========================
 Internal helpers
@@ -111,6 +227,23 @@ func (c *Condition) addRelation(
 	return c
 }
 
+// maxTimeWindow returns the widest TimeWindow of any term, or false when no term is time-bounded.
+func (c *Condition) maxTimeWindow() (time.Duration, bool) {
+	var widest time.Duration
+	var bounded bool
+	for _, tk := range c.tokens {
+		if tk.kind != tkTerm || tk.term.cond.TimeWindow == nil {
+			continue
+		}
+		tw := tk.term.cond.TimeWindow
+		if d := tw.Duration(); !bounded || d > widest {
+			widest = d
+		}
+		bounded = true
+	}
+	return widest, bounded
+}
+
 /*
 ========================
 Internal token model
@@ -125,8 +258,17 @@ type specToken struct {
 }
 
 type specTerm struct {
-	kind      termKind
-	eventType EventType
-	domain    EventDomain
-	cond      Conditions
+	kind          termKind
+	eventType     EventType
+	domain        EventDomain
+	cond          Conditions
+	propKey       string
+	rel           PropertyRelation
+	peerPred      PeerPredicate
+	eventTypes    []EventType
+	compositionID string
+	halfLife      TimeWindow
+	threshold     float64
+	minDomains    int
+	exactCount    int
 }
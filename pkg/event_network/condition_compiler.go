@@ -37,9 +37,14 @@ func (c *ConditionCompiler) Compile(
 		switch tk.kind {
 
 		case tkOp:
-			if tk.op == opAnd {
+			switch tk.op {
+			case opAnd:
 				expr.And()
-			} else {
+			case opXor:
+				expr.Xor()
+			case opNot:
+				expr.Not()
+			default:
 				expr.Or()
 			}
 
@@ -67,6 +72,9 @@ func (c *ConditionCompiler) compileTerm(
 	case termIsType:
 		expr.IsTypeOf(string(t.eventType), t.cond)
 
+	case termIsAnyOfTypes:
+		expr.IsAnyOfTypes(t.eventTypes, t.cond)
+
 	case termInDomain:
 		expr.InDomain(t.domain)
 
@@ -76,13 +84,34 @@ func (c *ConditionCompiler) compileTerm(
 	case termHasDescendants:
 		expr.HasDescendants(string(t.eventType), t.cond)
 
+	case termHasExactlyNDescendants:
+		expr.HasExactlyNDescendants(string(t.eventType), t.exactCount, t.cond.MaxDepth)
+
 	case termHasSiblings:
 		expr.HasSiblings(t.eventType, t.cond)
 
 	case termHasPeers:
 		expr.HasPeers(t.eventType, t.cond)
 
+	case termHasSiblingDerivedType:
+		expr.HasSiblingDerivedType(t.eventType, t.cond)
+
 	case termHasCousin:
 		expr.HasCousin(string(t.eventType), t.cond)
+
+	case termHasCompositionAncestor:
+		expr.HasCompositionAncestor(t.compositionID, t.cond)
+
+	case termPeerPropertyRelated:
+		expr.PeerPropertyRelated(t.eventType, t.propKey, t.rel, t.cond)
+
+	case termHasPeersWhere:
+		expr.HasPeersWhere(string(t.eventType), t.peerPred, t.cond)
+
+	case termPeersScore:
+		expr.PeersScore(t.eventType, t.halfLife, t.threshold, t.cond)
+
+	case termHasChildrenAcrossDomains:
+		expr.HasChildrenAcrossDomains(t.minDomains, t.cond)
 	}
 }
@@ -145,3 +145,66 @@ func TestConditionCompiler_NilAnchor(t *testing.T) {
 	require.Error(t, err)
 	require.Nil(t, expr)
 }
+
+func TestConditionSpec_Compile_Not(t *testing.T) {
+	net, parents, _ := buildInfraSubGraph(t)
+
+	anchor, err := net.GetByID(parents.CpuCriticalID)
+	require.NoError(t, err)
+
+	spec := NewCondition().
+		Not().
+		HasChild(MemoryStatusChanged, Conditions{}).
+		And().
+		HasChild(CpuStatusChanged, Conditions{})
+
+	expr, err := NewConditionCompiler(net).Compile(spec, &anchor)
+	require.NoError(t, err)
+
+	ok, _, err := expr.Eval()
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestConditionSpec_Compile_Xor(t *testing.T) {
+	net, parents, _ := buildInfraSubGraph(t)
+
+	anchor, err := net.GetByID(parents.CpuCriticalID)
+	require.NoError(t, err)
+
+	spec := NewCondition().
+		HasChild(CpuStatusChanged, Conditions{}).
+		Xor().
+		HasChild(MemoryStatusChanged, Conditions{})
+
+	expr, err := NewConditionCompiler(net).Compile(spec, &anchor)
+	require.NoError(t, err)
+
+	ok, _, err := expr.Eval()
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestConditionSpec_Compile_HasChildrenAcrossDomains(t *testing.T) {
+	net, parents, _ := buildInfraSubGraph(t)
+
+	anchor, err := net.GetByID(parents.ServerNodeChangeStatusID)
+	require.NoError(t, err)
+
+	spec := NewCondition().HasChildrenAcrossDomains(1, Conditions{})
+	expr, err := NewConditionCompiler(net).Compile(spec, &anchor)
+	require.NoError(t, err)
+
+	ok, matched, err := expr.Eval()
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Len(t, matched, 2)
+
+	spec = NewCondition().HasChildrenAcrossDomains(2, Conditions{})
+	expr, err = NewConditionCompiler(net).Compile(spec, &anchor)
+	require.NoError(t, err)
+
+	ok, _, err = expr.Eval()
+	require.NoError(t, err)
+	require.False(t, ok, "all infra children share one domain")
+}
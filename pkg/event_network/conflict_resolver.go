@@ -0,0 +1,175 @@
+package event_network
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// ConflictResolver decides what happens when an ingested event has the same content
+// (see ContentHash) as an event ingested earlier, e.g. a re-delivered event that differs only in timestamp.
+//
+// It returns the event to keep and whether that event replaces the stored one.
+// Returning false keeps the stored event untouched. A replacement keeps the stored ID,
+// type and domain, so existing edges stay valid. Either way no rules run for the incoming event.
+type ConflictResolver func(existing, incoming Event) (Event, bool)
+
+// KeepExisting ignores the incoming duplicate.
+func KeepExisting(existing, _ Event) (Event, bool) {
+	return existing, false
+}
+
+// ReplaceWithIncoming overwrites the stored event with the incoming one (e.g. its newer timestamp).
+func ReplaceWithIncoming(_, incoming Event) (Event, bool) {
+	return incoming, true
+}
+
+// MergeProperties keeps the stored event, overlays the incoming properties
+// on top of the stored ones and keeps the later of the two timestamps.
+func MergeProperties(existing, incoming Event) (Event, bool) {
+	merged := existing
+	merged.Properties = make(EventProps, len(existing.Properties)+len(incoming.Properties))
+	for k, v := range existing.Properties {
+		merged.Properties[k] = v
+	}
+	for k, v := range incoming.Properties {
+		merged.Properties[k] = v
+	}
+	if incoming.Timestamp.After(existing.Timestamp) {
+		merged.Timestamp = incoming.Timestamp
+	}
+	return merged, true
+}
+
+// EventUpdater is an optional EventNetwork extension used by SynapseRuntime
+// to apply ConflictResolver replacements.
+type EventUpdater interface {
+	// UpdateEvent overwrites the stored event with the same ID.
+	// Type and domain cannot change; a zero Timestamp keeps the stored one.
+	UpdateEvent(event Event) error
+}
+
+// ContentHash identifies an event by its content: type, domain and properties.
+// ID and Timestamp are ignored, so re-deliveries of the same event hash alike.
+// Properties are hashed as in HashConditions: numbers are normalized, so float64(3)
+// decoded from JSON and int 3 hash alike.
+func ContentHash(event Event) string {
+	h := sha256.New()
+	writeString(h, event.EventType)
+	writeString(h, event.EventDomain)
+	writeValue(h, event.Properties)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// contentIndex maps ContentHash to the ID of the event ingested with that content.
+// mu also serializes conflict resolution with storing, see addOrResolve.
+type contentIndex struct {
+	mu  sync.Mutex
+	ids map[string]contentEntry
+	// order lists insertions oldest first, for ContentIndexLimit; refs whose seq no
+	// longer matches ids were replaced or forgotten and are skipped.
+	order []contentRef
+	seq   uint64
+}
+
+type contentEntry struct {
+	id  EventID
+	seq uint64
+}
+
+type contentRef struct {
+	key string
+	seq uint64
+}
+
+// put records key -> id and forgets the oldest entries beyond limit (0 = unbounded).
+// Callers must hold c.mu.
+func (c *contentIndex) put(key string, id EventID, limit int) {
+	if c.ids == nil {
+		c.ids = make(map[string]contentEntry)
+	}
+	c.seq++
+	c.ids[key] = contentEntry{id: id, seq: c.seq}
+	if limit <= 0 {
+		return
+	}
+
+	c.order = append(c.order, contentRef{key: key, seq: c.seq})
+	for len(c.ids) > limit && len(c.order) > 0 {
+		oldest := c.order[0]
+		c.order = c.order[1:]
+		if c.ids[oldest.key].seq == oldest.seq {
+			delete(c.ids, oldest.key)
+		}
+	}
+	// Skipped refs pile up when entries are replaced or forgotten: compact now and then
+	if len(c.order) > 2*limit {
+		live := c.order[:0]
+		for _, ref := range c.order {
+			if c.ids[ref.key].seq == ref.seq {
+				live = append(live, ref)
+			}
+		}
+		c.order = live
+	}
+}
+
+// forget drops key if it still maps to id.
+func (c *contentIndex) forget(key string, id EventID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, ok := c.ids[key]; ok && entry.id == id {
+		delete(c.ids, key)
+	}
+}
+
+// addOrResolve stores event unless its content was ingested before, in which case
+// ConflictResolver decides (see resolveConflict) and handled is true. Lookup, resolution
+// and storing happen under one lock, so concurrent duplicates are stored only once.
+// No rules run under the lock.
+func (s *SynapseRuntime) addOrResolve(event Event) (stored Event, id EventID, handled bool, err error) {
+	s.content.mu.Lock()
+	defer s.content.mu.Unlock()
+
+	key := ContentHash(event)
+	if id, handled, err := s.resolveConflictLocked(key, event); handled {
+		return Event{}, id, true, err
+	}
+
+	stored, err = s.Network.AddEventFull(event)
+	if err != nil {
+		return Event{}, uuid.UUID{}, false, err
+	}
+	s.content.put(key, stored.ID, s.ContentIndexLimit)
+	return stored, stored.ID, false, nil
+}
+
+// resolveConflictLocked applies ConflictResolver when event (with content hash key) collides
+// with an earlier ingest. handled reports whether the collision was resolved and Ingest must
+// stop; id is then the stored event. Callers must hold s.content.mu.
+func (s *SynapseRuntime) resolveConflictLocked(key string, event Event) (id EventID, handled bool, err error) {
+	entry, ok := s.content.ids[key]
+	if !ok {
+		return uuid.UUID{}, false, nil
+	}
+	existing, err := s.Network.GetByID(entry.id)
+	if err != nil {
+		// removed since it was ingested
+		delete(s.content.ids, key)
+		return uuid.UUID{}, false, nil
+	}
+
+	resolved, replace := s.ConflictResolver(existing, event)
+	if !replace {
+		return existing.ID, true, nil
+	}
+
+	resolved.ID = existing.ID
+	if err := s.updateEvent(resolved); err != nil {
+		return uuid.UUID{}, true, err
+	}
+	return existing.ID, true, nil
+}
@@ -1,8 +1,10 @@
 package event_network
 
 import (
-	"github.com/google/uuid"
+	"errors"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type EventID = uuid.UUID
@@ -20,3 +22,15 @@ type Event struct {
 	Properties  EventProps
 	Timestamp   time.Time
 }
+
+// Valid reports whether the event carries the fields every node needs.
+// Events without a type or domain would create meaningless nodes and pollute type cohorts.
+func (e Event) Valid() error {
+	if e.EventType == "" {
+		return errors.New("invalid event: empty EventType")
+	}
+	if e.EventDomain == "" {
+		return errors.New("invalid event: empty EventDomain")
+	}
+	return nil
+}
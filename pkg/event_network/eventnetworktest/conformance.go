@@ -0,0 +1,425 @@
+// Package eventnetworktest provides a conformance suite for event_network.EventNetwork
+// implementations, kept out of event_network so production code does not link "testing".
+package eventnetworktest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jtomasevic/synapse/pkg/event_network"
+)
+
+// RunEventNetworkConformance runs the shared behavioral spec of EventNetwork against
+// networks produced by factory. Every sub-test gets a fresh, empty network.
+//
+// It locks down the semantics documented on EventNetwork, so different backends
+// (in-memory, memoized, persistent, ...) cannot silently drift apart.
+//
+// To check a custom backend, call it from a test in your own package:
+//
+//	func TestMyNetwork_Conformance(t *testing.T) {
+//		eventnetworktest.RunEventNetworkConformance(t, func() event_network.EventNetwork {
+//			return mybackend.NewNetwork()
+//		})
+//	}
+//
+// Fixture used by the relation checks (contributor -> derived):
+//
+//	a1, a2 -> A
+//	b1     -> B
+//	A, B   -> R
+//	x, y      (loose, parentless, same type as a1/a2)
+func RunEventNetworkConformance(t *testing.T, factory func() event_network.EventNetwork) {
+	t.Helper()
+
+	t.Run("AddEvent assigns ID and default timestamp", func(t *testing.T) {
+		net := factory()
+		id, err := net.AddEvent(event_network.Event{
+			EventType:   "conformance_leaf",
+			EventDomain: "conformance",
+			Properties:  event_network.EventProps{"k": "v"},
+		})
+		if err != nil {
+			t.Fatalf("AddEvent: %v", err)
+		}
+		if id == uuid.Nil {
+			t.Fatalf("AddEvent returned nil ID")
+		}
+		ev, err := net.GetByID(id)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if ev.ID != id || ev.EventType != "conformance_leaf" || ev.EventDomain != "conformance" {
+			t.Fatalf("GetByID returned %+v, want stored event %s", ev, id)
+		}
+		if ev.Timestamp.IsZero() {
+			t.Fatalf("zero timestamp was not defaulted")
+		}
+		if ev.Properties["k"] != "v" {
+			t.Fatalf("properties not preserved: %v", ev.Properties)
+		}
+	})
+
+	t.Run("AddEventFull returns the stored event", func(t *testing.T) {
+		net := factory()
+		stored, err := net.AddEventFull(event_network.Event{EventType: "conformance_leaf", EventDomain: "conformance"})
+		if err != nil {
+			t.Fatalf("AddEventFull: %v", err)
+		}
+		if stored.ID == uuid.Nil || stored.Timestamp.IsZero() {
+			t.Fatalf("AddEventFull returned incomplete event %+v", stored)
+		}
+		fetched, err := net.GetByID(stored.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if !fetched.Timestamp.Equal(stored.Timestamp) || fetched.EventType != stored.EventType {
+			t.Fatalf("GetByID %+v differs from AddEventFull %+v", fetched, stored)
+		}
+	})
+
+	t.Run("GetByTimeRange is inclusive", func(t *testing.T) {
+		net := factory()
+		base := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+		var ids []event_network.EventID
+		for i := 0; i < 3; i++ {
+			id, err := net.AddEvent(event_network.Event{EventType: "conformance_leaf", EventDomain: "conformance", Timestamp: base.Add(time.Duration(i) * time.Minute)})
+			if err != nil {
+				t.Fatalf("AddEvent: %v", err)
+			}
+			ids = append(ids, id)
+		}
+		evs, err := net.GetByTimeRange(base, base.Add(time.Minute))
+		if err != nil {
+			t.Fatalf("GetByTimeRange: %v", err)
+		}
+		expectConformanceIDs(t, "GetByTimeRange", evs, ids[0], ids[1])
+		evs, err = net.GetByTimeRange(base.Add(time.Hour), base.Add(2*time.Hour))
+		if err != nil {
+			t.Fatalf("GetByTimeRange: %v", err)
+		}
+		expectConformanceIDs(t, "GetByTimeRange outside events", evs)
+	})
+
+	t.Run("AllEvents lists every event oldest first", func(t *testing.T) {
+		net := factory()
+		base := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+		var ids []event_network.EventID
+		for _, offset := range []time.Duration{2 * time.Minute, 0, time.Minute} {
+			id, err := net.AddEvent(event_network.Event{EventType: "conformance_leaf", EventDomain: "conformance", Timestamp: base.Add(offset)})
+			if err != nil {
+				t.Fatalf("AddEvent: %v", err)
+			}
+			ids = append(ids, id)
+		}
+		evs, err := net.AllEvents()
+		if err != nil {
+			t.Fatalf("AllEvents: %v", err)
+		}
+		got := conformanceIDs(evs)
+		want := []event_network.EventID{ids[1], ids[2], ids[0]}
+		if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+			t.Fatalf("AllEvents: got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("AddEdge rejects unknown events", func(t *testing.T) {
+		net := factory()
+		id, _ := net.AddEvent(event_network.Event{EventType: "conformance_leaf", EventDomain: "conformance"})
+		if err := net.AddEdge(uuid.New(), id, "trigger"); err == nil {
+			t.Errorf("AddEdge with unknown from: expected error")
+		}
+		if err := net.AddEdge(id, uuid.New(), "trigger"); err == nil {
+			t.Errorf("AddEdge with unknown to: expected error")
+		}
+	})
+
+	t.Run("AddEdge ignores identical duplicates", func(t *testing.T) {
+		net := factory()
+		from, _ := net.AddEvent(event_network.Event{EventType: "conformance_leaf", EventDomain: "conformance"})
+		to, _ := net.AddEvent(event_network.Event{EventType: "conformance_mid", EventDomain: "conformance"})
+		for i := 0; i < 2; i++ {
+			if err := net.AddEdge(from, to, "trigger"); err != nil {
+				t.Fatalf("AddEdge: %v", err)
+			}
+		}
+		children, err := net.Children(to)
+		if err != nil {
+			t.Fatalf("Children: %v", err)
+		}
+		expectConformanceIDs(t, "Children after duplicate AddEdge", children, from)
+	})
+
+	t.Run("relations", func(t *testing.T) {
+		net := factory()
+		f := buildConformanceFixture(t, net)
+
+		check := func(name string, evs []event_network.Event, err error, want ...event_network.EventID) {
+			t.Helper()
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			expectConformanceIDs(t, name, evs, want...)
+		}
+
+		evs, err := net.Children(f.A)
+		check("Children(A)", evs, err, f.a1, f.a2)
+		evs, err = net.Children(f.R)
+		check("Children(R)", evs, err, f.A, f.B)
+		evs, err = net.Children(f.a1)
+		check("Children(a1)", evs, err)
+
+		evs, err = net.Parents(f.a1)
+		check("Parents(a1)", evs, err, f.A)
+		evs, err = net.Parents(f.A)
+		check("Parents(A)", evs, err, f.R)
+		evs, err = net.Parents(f.R)
+		check("Parents(R)", evs, err)
+
+		evs, err = net.Descendants(f.R, 1)
+		check("Descendants(R, 1)", evs, err, f.A, f.B)
+		evs, err = net.Descendants(f.R, 2)
+		check("Descendants(R, 2)", evs, err, f.A, f.B, f.a1, f.a2, f.b1)
+		evs, err = net.Descendants(f.R, 0)
+		check("Descendants(R, 0)", evs, err)
+
+		evs, err = net.Ancestors(f.a1, 1)
+		check("Ancestors(a1, 1)", evs, err, f.A)
+		evs, err = net.Ancestors(f.a1, 2)
+		check("Ancestors(a1, 2)", evs, err, f.A, f.R)
+		evs, err = net.Ancestors(f.R, 3)
+		check("Ancestors(R, 3)", evs, err)
+
+		evs, err = net.Siblings(f.a1)
+		check("Siblings(a1)", evs, err, f.a2)
+		evs, err = net.Siblings(f.A)
+		check("Siblings(A)", evs, err, f.B)
+		evs, err = net.Siblings(f.x)
+		check("Siblings(x)", evs, err)
+
+		evs, err = net.Peers(f.x)
+		check("Peers(x)", evs, err, f.y)
+		evs, err = net.Peers(f.R)
+		check("Peers(R)", evs, err)
+
+		// Cousins share ancestry up to maxDepth: b1 is reached through R.
+		evs, err = net.Cousins(f.a1, 2)
+		if err != nil {
+			t.Fatalf("Cousins(a1, 2): %v", err)
+		}
+		ids := conformanceIDSet(evs)
+		if !ids[f.b1] {
+			t.Errorf("Cousins(a1, 2): expected b1 in %v", conformanceIDs(evs))
+		}
+		if ids[f.a1] || ids[f.A] || ids[f.R] {
+			t.Errorf("Cousins(a1, 2): must not contain the event itself or its derivation path, got %v", conformanceIDs(evs))
+		}
+
+		evs, err = net.GetByType("conformance_leaf_a")
+		check("GetByType(conformance_leaf_a)", evs, err, f.a1, f.a2, f.x, f.y)
+		evs, err = net.GetByType("conformance_unknown")
+		check("GetByType(conformance_unknown)", evs, err)
+		evs, err = net.GetByDomain("conformance")
+		check("GetByDomain(conformance)", evs, err, f.a1, f.a2, f.b1, f.A, f.B, f.R, f.x, f.y)
+		evs, err = net.GetByDomain("conformance_unknown")
+		check("GetByDomain(conformance_unknown)", evs, err)
+
+		out, err := net.OutEdges(f.a1)
+		if err != nil {
+			t.Fatalf("OutEdges(a1): %v", err)
+		}
+		if len(out) != 1 || out[0] != (event_network.Edge{From: f.a1, To: f.A, Relation: "trigger"}) {
+			t.Errorf("OutEdges(a1): got %+v", out)
+		}
+		in, err := net.InEdges(f.R)
+		if err != nil {
+			t.Fatalf("InEdges(R): %v", err)
+		}
+		if len(in) != 2 || in[0].To != f.R || in[1].To != f.R {
+			t.Errorf("InEdges(R): got %+v", in)
+		}
+		if in, _ := net.InEdges(f.a1); len(in) != 0 {
+			t.Errorf("InEdges(a1): leaf must have no inbound edges, got %+v", in)
+		}
+
+		evs, err = net.GetByIDs([]event_network.EventID{f.R, f.a1})
+		if err != nil {
+			t.Fatalf("GetByIDs: %v", err)
+		}
+		if len(evs) != 2 || evs[0].ID != f.R || evs[1].ID != f.a1 {
+			t.Errorf("GetByIDs must keep request order, got %v", conformanceIDs(evs))
+		}
+	})
+
+	t.Run("RemoveEvent drops the event and its edges", func(t *testing.T) {
+		net := factory()
+		f := buildConformanceFixture(t, net)
+
+		// Warm up relations so caching backends have something to invalidate.
+		if _, err := net.Children(f.A); err != nil {
+			t.Fatalf("Children: %v", err)
+		}
+		if _, err := net.Peers(f.x); err != nil {
+			t.Fatalf("Peers: %v", err)
+		}
+
+		if err := net.RemoveEvent(f.A); err != nil {
+			t.Fatalf("RemoveEvent: %v", err)
+		}
+		if _, err := net.GetByID(f.A); err == nil {
+			t.Errorf("GetByID after RemoveEvent: expected error")
+		}
+		if err := net.RemoveEvent(f.A); err == nil {
+			t.Errorf("second RemoveEvent: expected error")
+		}
+
+		evs, err := net.Children(f.R)
+		if err != nil {
+			t.Fatalf("Children(R): %v", err)
+		}
+		expectConformanceIDs(t, "Children(R) after removing A", evs, f.B)
+
+		evs, err = net.Parents(f.a1)
+		if err != nil {
+			t.Fatalf("Parents(a1): %v", err)
+		}
+		expectConformanceIDs(t, "Parents(a1) after removing A", evs)
+
+		// a1, a2 lost their only parent and join the parentless frontier.
+		evs, err = net.Peers(f.x)
+		if err != nil {
+			t.Fatalf("Peers(x): %v", err)
+		}
+		expectConformanceIDs(t, "Peers(x) after removing A", evs, f.y, f.a1, f.a2)
+
+		evs, err = net.GetByType("conformance_mid")
+		if err != nil {
+			t.Fatalf("GetByType: %v", err)
+		}
+		expectConformanceIDs(t, "GetByType(conformance_mid) after removing A", evs, f.B)
+
+		evs, err = net.GetByDomain("conformance")
+		if err != nil {
+			t.Fatalf("GetByDomain: %v", err)
+		}
+		expectConformanceIDs(t, "GetByDomain(conformance) after removing A", evs, f.a1, f.a2, f.b1, f.B, f.R, f.x, f.y)
+	})
+
+	t.Run("missing IDs return errors", func(t *testing.T) {
+		net := factory()
+		known, _ := net.AddEvent(event_network.Event{EventType: "conformance_leaf", EventDomain: "conformance"})
+		missing := uuid.New()
+
+		if _, err := net.GetByID(missing); err == nil {
+			t.Errorf("GetByID: expected error")
+		}
+		if _, err := net.GetByIDs([]event_network.EventID{known, missing}); err == nil {
+			t.Errorf("GetByIDs: expected error")
+		}
+		if _, err := net.Children(missing); err == nil {
+			t.Errorf("Children: expected error")
+		}
+		if _, err := net.Parents(missing); err == nil {
+			t.Errorf("Parents: expected error")
+		}
+		if _, err := net.OutEdges(missing); err == nil {
+			t.Errorf("OutEdges: expected error")
+		}
+		if _, err := net.InEdges(missing); err == nil {
+			t.Errorf("InEdges: expected error")
+		}
+		if _, err := net.Descendants(missing, 1); err == nil {
+			t.Errorf("Descendants: expected error")
+		}
+		if _, err := net.Ancestors(missing, 1); err == nil {
+			t.Errorf("Ancestors: expected error")
+		}
+		if _, err := net.Siblings(missing); err == nil {
+			t.Errorf("Siblings: expected error")
+		}
+		if _, err := net.Peers(missing); err == nil {
+			t.Errorf("Peers: expected error")
+		}
+		if _, err := net.Cousins(missing, 1); err == nil {
+			t.Errorf("Cousins: expected error")
+		}
+	})
+}
+
+type conformanceFixture struct {
+	a1, a2, b1, A, B, R, x, y event_network.EventID
+}
+
+func buildConformanceFixture(t *testing.T, net event_network.EventNetwork) conformanceFixture {
+	t.Helper()
+
+	add := func(eventType event_network.EventType) event_network.EventID {
+		t.Helper()
+		id, err := net.AddEvent(event_network.Event{EventType: eventType, EventDomain: "conformance"})
+		if err != nil {
+			t.Fatalf("AddEvent(%s): %v", eventType, err)
+		}
+		return id
+	}
+	link := func(from, to event_network.EventID) {
+		t.Helper()
+		if err := net.AddEdge(from, to, "trigger"); err != nil {
+			t.Fatalf("AddEdge: %v", err)
+		}
+	}
+
+	f := conformanceFixture{
+		a1: add("conformance_leaf_a"),
+		a2: add("conformance_leaf_a"),
+		b1: add("conformance_leaf_b"),
+		A:  add("conformance_mid"),
+		B:  add("conformance_mid"),
+		R:  add("conformance_root"),
+		x:  add("conformance_leaf_a"),
+		y:  add("conformance_leaf_a"),
+	}
+	link(f.a1, f.A)
+	link(f.a2, f.A)
+	link(f.b1, f.B)
+	link(f.A, f.R)
+	link(f.B, f.R)
+	return f
+}
+
+// expectConformanceIDs fails unless evs contains exactly want, without duplicates (order ignored).
+func expectConformanceIDs(t *testing.T, name string, evs []event_network.Event, want ...event_network.EventID) {
+	t.Helper()
+
+	got := conformanceIDSet(evs)
+	if len(got) != len(evs) {
+		t.Errorf("%s: duplicate events in %v", name, conformanceIDs(evs))
+		return
+	}
+	if len(got) != len(want) {
+		t.Errorf("%s: got %d events %v, want %d %v", name, len(evs), conformanceIDs(evs), len(want), want)
+		return
+	}
+	for _, id := range want {
+		if !got[id] {
+			t.Errorf("%s: missing %s in %v", name, id, conformanceIDs(evs))
+		}
+	}
+}
+
+func conformanceIDSet(evs []event_network.Event) map[event_network.EventID]bool {
+	out := make(map[event_network.EventID]bool, len(evs))
+	for _, ev := range evs {
+		out[ev.ID] = true
+	}
+	return out
+}
+
+func conformanceIDs(evs []event_network.Event) []event_network.EventID {
+	out := make([]event_network.EventID, 0, len(evs))
+	for _, ev := range evs {
+		out = append(out, ev.ID)
+	}
+	return out
+}
@@ -0,0 +1,19 @@
+package eventnetworktest
+
+import (
+	"testing"
+
+	"github.com/jtomasevic/synapse/pkg/event_network"
+)
+
+func TestInMemoryEventNetwork_Conformance(t *testing.T) {
+	RunEventNetworkConformance(t, func() event_network.EventNetwork {
+		return event_network.NewInMemoryEventNetwork()
+	})
+}
+
+func TestMemoizedNetwork_Conformance(t *testing.T) {
+	RunEventNetworkConformance(t, func() event_network.EventNetwork {
+		return event_network.NewMemoizedNetwork(event_network.NewInMemoryEventNetwork(), event_network.NewInMemoryStructuralMemory())
+	})
+}
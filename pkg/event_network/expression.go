@@ -1,26 +1,136 @@
 package event_network
 
+import (
+	"errors"
+	"time"
+)
+
 type Counter struct {
 	HowMany       int
 	HowManyOrMore bool
+	// HowManyOrFewer accepts at most HowMany matches (including zero).
+	// Mutually exclusive with HowManyOrMore.
+	HowManyOrFewer bool
+
+	// UseRange switches to an inclusive [Min, Max] range check; HowMany/HowManyOrMore are ignored.
+	UseRange bool
+	Min      int
+	Max      int
+}
+
+// satisfiedBy reports whether n matches fulfil the counter.
+func (c *Counter) satisfiedBy(n int) (bool, error) {
+	if c.HowManyOrMore && c.HowManyOrFewer {
+		return false, errors.New("counter: HowManyOrMore and HowManyOrFewer are mutually exclusive")
+	}
+	if c.UseRange {
+		return n >= c.Min && n <= c.Max, nil
+	}
+	if c.HowManyOrMore {
+		return n >= c.HowMany, nil
+	}
+	if c.HowManyOrFewer {
+		return n <= c.HowMany, nil
+	}
+	return n == c.HowMany, nil
 }
 
 type TimeWindow struct {
 	Within   int
 	TimeUnit TimeUnit
+	// Direction of the window relative to the anchor timestamp; default Past.
+	Direction TimeDirection
+	// Converter (optional) sets the month and year lengths; nil means 30/365 days.
+	Converter *TimeUnitConverter
 }
 
+// Duration is the length of the window, converted with Converter when set.
+func (w TimeWindow) Duration() time.Duration {
+	if w.Converter != nil {
+		return w.Converter.ToDuration(w.TimeUnit, w.Within)
+	}
+	return w.TimeUnit.ToDuration(w.Within)
+}
+
+// TimeDirection says on which side of the anchor a TimeWindow extends.
+type TimeDirection int
+
+const (
+	// Past (default): [anchor - d, anchor].
+	Past TimeDirection = iota
+	// Future: [anchor, anchor + d].
+	Future
+	// Both: [anchor - d, anchor + d].
+	Both
+)
+
+// contains reports whether ts falls inside the window around anchor.
+func (w *TimeWindow) contains(anchor, ts time.Time) bool {
+	d := w.Duration()
+	from, to := anchor.Add(-d), anchor
+	switch w.Direction {
+	case Future:
+		from, to = anchor, anchor.Add(d)
+	case Both:
+		to = anchor.Add(d)
+	}
+	return !ts.Before(from) && !ts.After(to)
+}
+
+// admitsTime applies the time constraints of c (RequireTimestamp, TimeWindow) to ts.
+func (c Conditions) admitsTime(anchor, ts time.Time) bool {
+	if c.RequireTimestamp && ts.IsZero() {
+		return false
+	}
+	return c.TimeWindow == nil || c.TimeWindow.contains(anchor, ts)
+}
+
+// PropertyMatchMode controls how Conditions.PropertyValues are compared with event properties.
+type PropertyMatchMode int
+
+const (
+	// Subset (default): the event must contain every specified key/value; extra properties are allowed.
+	Subset PropertyMatchMode = iota
+	// Exact: the event's property set must equal the specified map exactly (no extra keys).
+	Exact
+)
+
 type Conditions struct {
-	MaxDepth       int // default to 1
-	Counter        *Counter
-	TimeWindow     *TimeWindow
-	PropertyValues map[string]any
-	OfEventType    EventType
+	MaxDepth          int // default to 1
+	Counter           *Counter
+	TimeWindow        *TimeWindow
+	PropertyValues    map[string]any
+	PropertyMatchMode PropertyMatchMode // default Subset
+	OfEventType       EventType
+
+	// PropertyMatchers tests property values beyond equality (see GreaterThan, LessThan, OneOf).
+	// Each key must be present and accepted by its matcher; in Exact mode its keys count
+	// towards the expected property set alongside PropertyValues.
+	// Keys, here and in PropertyValues, may be dotted paths into nested maps such as
+	// "props4.objProp"; a key that exists at the top level is taken literally.
+	// Terms with matchers are not cached by MemoizedNetwork: functions cannot be hashed.
+	PropertyMatchers map[string]PropertyMatcher
+
+	// ChildOrder (HasChild only) requires the anchor's children of these types to have
+	// arrived in this order: every child of ChildOrder[i] no later than any child of
+	// ChildOrder[i+1]. Each listed type must be present among the children.
+	ChildOrder []EventType
+
+	// RequireTimestamp excludes events with a zero Timestamp from the term. Without it a
+	// zero time counts as a real instant far in the past, so such events pass or fail
+	// TimeWindow depending on the anchor rather than on when they happened.
+	RequireTimestamp bool
 }
 
 type Expression interface {
 	And() *EventExpression
 	Or() *EventExpression
+	// Xor is true when exactly one side is true.
+	// Precedence: Not > And > Xor > Or.
+	Xor() *EventExpression
+	// Not negates the term (or Group) that immediately follows it.
+	// It binds tighter than any binary operator; Not().Not() cancels out.
+	Not() *EventExpression
 	// Group groups expressions together. Must be closed with Ungroup().
 	// Group is acting as brackets in logical expressions.
 	Group() *EventExpression
@@ -82,5 +192,36 @@ type Expression interface {
 	// HasCousin contains sibling event of given type.
 	HasCousin(eventType string, conditions Conditions) *EventExpression
 
+	// PeerPropertyRelated matches peers whose numeric property relates to the anchor's
+	// (e.g. within a tolerance), using rel(anchorVal, peerVal).
+	PeerPropertyRelated(eventType string, propKey string, rel PropertyRelation, conditions Conditions) *EventExpression
+
+	// HasPeersWhere matches peers accepted by pred(anchor, peer), for arbitrary peer selection.
+	HasPeersWhere(eventType string, pred PeerPredicate, conditions Conditions) *EventExpression
+
+	// PeersScore passes when the recency-decayed peer evidence (each peer 0.5^(age/halfLife)) exceeds threshold.
+	PeersScore(eventType string, halfLife TimeWindow, threshold float64, conditions Conditions) *EventExpression
+
+	// HasExactlyNDescendants passes when exactly n distinct descendants of eventType lie within maxDepth.
+	HasExactlyNDescendants(eventType string, n int, maxDepth int) *EventExpression
+
+	// HasSiblingDerivedType passes when the anchor and at least one sibling fed a derived event of derivedType.
+	HasSiblingDerivedType(derivedType EventType, conditions Conditions) *EventExpression
+
+	// HasChildrenAcrossDomains passes when the anchor's children span at least minDomains distinct domains.
+	HasChildrenAcrossDomains(minDomains int, conditions Conditions) *EventExpression
+
+	// HasCompositionAncestor anchor (transitively) fed a composition-derived event with the given CompositionID.
+	HasCompositionAncestor(compositionID string, conditions Conditions) *EventExpression
+
 	Eval() (bool, []Event, error)
+	// EvalBool is Eval without collecting matched events; And/Or short-circuit.
+	EvalBool() (bool, error)
+	// EvalGrouped is Eval with matched events grouped by term index (order of appearance).
+	EvalGrouped() (bool, map[int][]Event, error)
+	// EvalCandidates explains, per candidate, which checks of a single peer term passed or failed.
+	EvalCandidates(build func(x *EventExpression) *EventExpression) ([]CandidateResult, error)
+
+	// ForAnchor returns a copy of the expression bound to another anchor event.
+	ForAnchor(ev *Event) *EventExpression
 }
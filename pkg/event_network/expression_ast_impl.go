@@ -2,6 +2,9 @@ package event_network
 
 import (
 	"errors"
+	"fmt"
+	"math"
+	"time"
 )
 
 /*
@@ -24,6 +27,9 @@ const (
 const (
 	opAnd opKind = iota
 	opOr
+	opXor
+	// opNot is unary: it negates the term or group that follows it.
+	opNot
 )
 
 const (
@@ -34,6 +40,14 @@ const (
 	termHasSiblings
 	termHasPeers
 	termHasCousin
+	termPeerPropertyRelated
+	termIsAnyOfTypes
+	termHasCompositionAncestor
+	termPeersScore
+	termHasChildrenAcrossDomains
+	termHasExactlyNDescendants
+	termHasSiblingDerivedType
+	termHasPeersWhere
 )
 
 type term struct {
@@ -41,6 +55,29 @@ type term struct {
 	eventType string
 	domain    EventDomain
 	cond      Conditions
+
+	// used by termPeerPropertyRelated
+	propKey string
+	rel     PropertyRelation
+
+	// used by termHasPeersWhere
+	peerPred PeerPredicate
+
+	// used by termIsAnyOfTypes
+	eventTypes []string
+
+	// used by termHasCompositionAncestor
+	compositionID string
+
+	// used by termPeersScore
+	halfLife  TimeWindow
+	threshold float64
+
+	// used by termHasChildrenAcrossDomains
+	minDomains int
+
+	// used by termHasExactlyNDescendants
+	exactCount int
 }
 
 type token struct {
@@ -55,18 +92,60 @@ Expression builder
 ========================
 */
 
+// DefaultMaxTraversalDepth bounds depth-limited terms unless EventExpression.MaxTraversalDepth says otherwise.
+const DefaultMaxTraversalDepth = 64
+
+// ErrMaxDepthExceeded is returned by Eval when a term asks for a Conditions.MaxDepth
+// beyond the expression's MaxTraversalDepth.
+var ErrMaxDepthExceeded = errors.New("max depth exceeds traversal limit")
+
 // EventExpression is a fluent builder and evaluator for semantic expressions
 // evaluated relative to an anchor event.
 type EventExpression struct {
 	Graph  EventNetwork
 	Event  *Event
 	tokens []token
+
+	// MaxTraversalDepth caps Conditions.MaxDepth of HasDescendants, HasExactlyNDescendants,
+	// HasCousin and HasCompositionAncestor, so a huge depth can't walk a large graph unbounded.
+	// Unlimited defaults are clamped to it; explicit larger depths fail with ErrMaxDepthExceeded.
+	// <= 0 means DefaultMaxTraversalDepth.
+	MaxTraversalDepth int
 }
 
 func NewExpression(graph EventNetwork, event *Event) *EventExpression {
 	return &EventExpression{
-		Graph: graph,
-		Event: event,
+		Graph:             graph,
+		Event:             event,
+		MaxTraversalDepth: DefaultMaxTraversalDepth,
+	}
+}
+
+// traversalDepth resolves a term's Conditions.MaxDepth: <= 0 takes fallback, clamped to
+// MaxTraversalDepth; an explicit depth beyond MaxTraversalDepth is an error.
+func (e *EventExpression) traversalDepth(requested, fallback int) (int, error) {
+	limit := e.MaxTraversalDepth
+	if limit <= 0 {
+		limit = DefaultMaxTraversalDepth
+	}
+	if requested > limit {
+		return 0, fmt.Errorf("%w: %d > %d", ErrMaxDepthExceeded, requested, limit)
+	}
+	if requested <= 0 {
+		requested = fallback
+	}
+	return min(requested, limit), nil
+}
+
+// ForAnchor returns a shallow copy of the expression bound to a different anchor.
+// The token list is shared, so one built expression can be evaluated against many anchors
+// without rebuilding it. The copy's capacity is clipped: extending either expression
+// afterwards never writes into the other's tokens.
+func (e *EventExpression) ForAnchor(ev *Event) *EventExpression {
+	return &EventExpression{
+		Graph:  e.Graph,
+		Event:  ev,
+		tokens: e.tokens[:len(e.tokens):len(e.tokens)],
 	}
 }
 
@@ -80,6 +159,16 @@ func (e *EventExpression) Or() *EventExpression {
 	return e
 }
 
+func (e *EventExpression) Xor() *EventExpression {
+	e.tokens = append(e.tokens, token{kind: tkOp, op: opXor})
+	return e
+}
+
+func (e *EventExpression) Not() *EventExpression {
+	e.tokens = append(e.tokens, token{kind: tkOp, op: opNot})
+	return e
+}
+
 func (e *EventExpression) Group() *EventExpression {
 	e.tokens = append(e.tokens, token{kind: tkLParen})
 	return e
@@ -122,6 +211,24 @@ func (e *EventExpression) HasDescendants(eventType string, cond Conditions) *Eve
 	return e
 }
 
+// HasExactlyNDescendants matches when exactly n distinct descendants of eventType
+// (events derived from the anchor, walking parents upward) lie within maxDepth hops.
+// Each descendant is counted once, even when several derivation paths reach it
+// (diamonds); maxDepth <= 0 means 1. Unlike HasDescendants, n == 0 matches
+// when there are none.
+func (e *EventExpression) HasExactlyNDescendants(eventType string, n int, maxDepth int) *EventExpression {
+	e.tokens = append(e.tokens, token{
+		kind: tkTerm,
+		term: term{
+			kind:       termHasExactlyNDescendants,
+			eventType:  eventType,
+			cond:       Conditions{MaxDepth: maxDepth},
+			exactCount: n,
+		},
+	})
+	return e
+}
+
 // HasSiblings Two events are siblings if they share at least one COMMON PARENT
 // (i.e. they are derived from the same contributing event).
 //
@@ -170,6 +277,18 @@ func (e *EventExpression) HasPeers(eventType string, cond Conditions) *EventExpr
 	return e
 }
 
+// HasSiblingDerivedType matches when the anchor fed a derivation of derivedType together
+// with other contributors, i.e. a parent of that type that also has siblings of the anchor
+// as children ("this input also helped derive a memory_critical").
+// Conditions apply to those parents; matched events are the parents.
+func (e *EventExpression) HasSiblingDerivedType(derivedType EventType, cond Conditions) *EventExpression {
+	e.tokens = append(e.tokens, token{
+		kind: tkTerm,
+		term: term{kind: termHasSiblingDerivedType, eventType: derivedType, cond: cond},
+	})
+	return e
+}
+
 func (e *EventExpression) HasCousin(eventType string, cond Conditions) *EventExpression {
 	e.tokens = append(e.tokens, token{
 		kind: tkTerm,
@@ -178,6 +297,105 @@ func (e *EventExpression) HasCousin(eventType string, cond Conditions) *EventExp
 	return e
 }
 
+// PropertyRelation compares the anchor's numeric property value with a peer's.
+type PropertyRelation func(anchorVal, peerVal float64) bool
+
+// PeerPropertyRelated matches peers (same semantics as HasPeers) whose numeric propKey
+// value relates to the anchor's propKey value according to rel,
+// e.g. "peer's load is within 10% of the anchor's load".
+//
+// Peers without a numeric propKey are skipped; if the anchor has no numeric propKey, nothing matches.
+// Conditions (counter, time window, properties) are applied to the related peers.
+func (e *EventExpression) PeerPropertyRelated(
+	eventType string,
+	propKey string,
+	rel PropertyRelation,
+	cond Conditions,
+) *EventExpression {
+	e.tokens = append(e.tokens, token{
+		kind: tkTerm,
+		term: term{
+			kind:      termPeerPropertyRelated,
+			eventType: eventType,
+			cond:      cond,
+			propKey:   propKey,
+			rel:       rel,
+		},
+	})
+	return e
+}
+
+// PeerPredicate decides whether a peer candidate counts for the anchor.
+type PeerPredicate func(anchor, peer Event) bool
+
+// HasPeersWhere matches peers (same semantics as HasPeers) for which pred(anchor, peer)
+// holds, for selections the built-in filters cannot express. Conditions (counter, time
+// window, properties) are applied to the accepted peers; a nil pred matches nothing.
+func (e *EventExpression) HasPeersWhere(eventType string, pred PeerPredicate, cond Conditions) *EventExpression {
+	e.tokens = append(e.tokens, token{
+		kind: tkTerm,
+		term: term{kind: termHasPeersWhere, eventType: eventType, cond: cond, peerPred: pred},
+	})
+	return e
+}
+
+// PeersScore is a recency-weighted HasPeers: each peer (after Conditions filtering)
+// contributes 0.5^(age/halfLife), where age is its distance in time from the anchor.
+// The term passes when the summed score exceeds threshold, so older peers count less
+// without a hard window cutoff. Conditions.Counter is ignored.
+func (e *EventExpression) PeersScore(
+	eventType string,
+	halfLife TimeWindow,
+	threshold float64,
+	cond Conditions,
+) *EventExpression {
+	e.tokens = append(e.tokens, token{
+		kind: tkTerm,
+		term: term{
+			kind:      termPeersScore,
+			eventType: eventType,
+			cond:      cond,
+			halfLife:  halfLife,
+			threshold: threshold,
+		},
+	})
+	return e
+}
+
+// HasChildrenAcrossDomains matches when the anchor's children (after Conditions filtering)
+// come from at least minDomains distinct EventDomains, e.g. "this derived event aggregates
+// signals from at least 2 domains". Without matching children it never passes.
+// Conditions.Counter is ignored.
+func (e *EventExpression) HasChildrenAcrossDomains(minDomains int, cond Conditions) *EventExpression {
+	e.tokens = append(e.tokens, token{
+		kind: tkTerm,
+		term: term{
+			kind:       termHasChildrenAcrossDomains,
+			cond:       cond,
+			minDomains: minDomains,
+		},
+	})
+	return e
+}
+
+// HasCompositionAncestor matches when the anchor (directly or through intermediate derived events)
+// fed a composition-derived event produced by the spec with the given CompositionID.
+//
+// Ancestors are walked upward via Parents(). cond.MaxDepth limits the number of hops;
+// 0 (default) means up to MaxTraversalDepth, since composition events usually sit several levels above leaves.
+// Counter/TimeWindow/PropertyValues are applied to the matching composition events.
+func (e *EventExpression) HasCompositionAncestor(compositionID string, cond Conditions) *EventExpression {
+	e.tokens = append(e.tokens, token{
+		kind: tkTerm,
+		term: term{
+			kind:          termHasCompositionAncestor,
+			compositionID: compositionID,
+			cond:          cond,
+		},
+	})
+	return e
+}
+
 /*
 ========================
 Evaluation
@@ -185,49 +403,169 @@ Evaluation
 */
 
 func (e *EventExpression) Eval() (bool, []Event, error) {
+	results := []Event{}
+	ok, err := e.evaluate(func(_ int, matched []Event) {
+		results = append(results, matched...)
+	})
+	if err != nil {
+		return false, nil, err
+	}
+	return ok, results, nil
+}
+
+// EvalBool evaluates the expression like Eval, but only reports whether it matched.
+// And/Or short-circuit: a term whose value cannot change the result is not evaluated,
+// so an error it would have returned is not reported either.
+func (e *EventExpression) EvalBool() (bool, error) {
 	if len(e.tokens) == 0 {
-		return false, nil, errors.New("empty expression")
+		return false, errors.New("empty expression")
 	}
 
 	rpn, err := toRPN(e.tokens)
+	if err != nil {
+		return false, err
+	}
+
+	var stack []*boolNode
+	for _, tk := range rpn {
+		switch tk.kind {
+		case tkTerm:
+			stack = append(stack, &boolNode{term: &tk.term})
+
+		case tkOp:
+			if tk.op == opNot {
+				if len(stack) < 1 {
+					return false, errors.New("invalid expression")
+				}
+				stack[len(stack)-1] = &boolNode{op: opNot, operands: []*boolNode{stack[len(stack)-1]}}
+				continue
+			}
+			if len(stack) < 2 {
+				return false, errors.New("invalid expression")
+			}
+			node := &boolNode{op: tk.op, operands: []*boolNode{stack[len(stack)-2], stack[len(stack)-1]}}
+			stack = append(stack[:len(stack)-2], node)
+		}
+	}
+
+	if len(stack) != 1 {
+		return false, errors.New("expression did not collapse")
+	}
+	return e.evalBoolNode(stack[0])
+}
+
+// boolNode is the expression tree EvalBool builds from the RPN: either a term,
+// or an operator applied to one (opNot) or two operands.
+type boolNode struct {
+	term     *term
+	op       opKind
+	operands []*boolNode
+}
+
+func (e *EventExpression) evalBoolNode(n *boolNode) (bool, error) {
+	if n.term != nil {
+		ok, _, err := e.evalTerm(*n.term)
+		return ok, err
+	}
+
+	a, err := e.evalBoolNode(n.operands[0])
+	if err != nil {
+		return false, err
+	}
+	switch {
+	case n.op == opNot:
+		return !a, nil
+	case n.op == opAnd && !a:
+		return false, nil
+	case n.op != opAnd && n.op != opXor && a:
+		return true, nil
+	}
+
+	b, err := e.evalBoolNode(n.operands[1])
+	if err != nil {
+		return false, err
+	}
+	if n.op == opXor {
+		return a != b, nil
+	}
+	return b, nil
+}
+
+// EvalGrouped evaluates the expression like Eval, but returns the matched events
+// grouped by term. The map key is the term's index in the order the terms were
+// added to the expression (operators and brackets are not counted).
+//
+// This lets a caller tell which term contributed which events,
+// e.g. "cpu peers" vs "memory peers" in HasPeers(cpu).Or().HasPeers(memory).
+func (e *EventExpression) EvalGrouped() (bool, map[int][]Event, error) {
+	grouped := make(map[int][]Event)
+	ok, err := e.evaluate(func(index int, matched []Event) {
+		grouped[index] = matched
+	})
 	if err != nil {
 		return false, nil, err
 	}
+	return ok, grouped, nil
+}
 
-	var stack []bool
+// evaluate runs the expression and reports each term's matched events to collect.
+//
+// toRPN keeps terms in their original relative order, so counting terms
+// while walking the RPN yields the term index as written by the caller.
+func (e *EventExpression) evaluate(collect func(index int, matched []Event)) (bool, error) {
+	if len(e.tokens) == 0 {
+		return false, errors.New("empty expression")
+	}
 
-	results := []Event{}
+	rpn, err := toRPN(e.tokens)
+	if err != nil {
+		return false, err
+	}
+
+	var stack []bool
+	termIndex := 0
 
 	for _, tk := range rpn {
 		switch tk.kind {
 		case tkTerm:
 			v, res, err := e.evalTerm(tk.term)
-			results = append(results, res...)
 			if err != nil {
-				return false, nil, err
+				return false, err
 			}
+			collect(termIndex, res)
+			termIndex++
 			stack = append(stack, v)
 
 		case tkOp:
+			if tk.op == opNot {
+				if len(stack) < 1 {
+					return false, errors.New("invalid expression")
+				}
+				stack[len(stack)-1] = !stack[len(stack)-1]
+				continue
+			}
 			if len(stack) < 2 {
-				return false, nil, errors.New("invalid expression")
+				return false, errors.New("invalid expression")
 			}
 			b := stack[len(stack)-1]
 			a := stack[len(stack)-2]
 			stack = stack[:len(stack)-2]
 
-			if tk.op == opAnd {
+			switch tk.op {
+			case opAnd:
 				stack = append(stack, a && b)
-			} else {
+			case opXor:
+				stack = append(stack, a != b)
+			default:
 				stack = append(stack, a || b)
 			}
 		}
 	}
 
 	if len(stack) != 1 {
-		return false, nil, errors.New("expression did not collapse")
+		return false, errors.New("expression did not collapse")
 	}
-	return stack[0], results, nil
+	return stack[0], nil
 }
 
 /*
@@ -242,22 +580,24 @@ func (e *EventExpression) evalTerm(t term) (bool, []Event, error) {
 	case termIsType:
 		return e.Event.EventType == EventType(t.eventType), nil, nil
 
+	case termIsAnyOfTypes:
+		for _, et := range t.eventTypes {
+			if e.Event.EventType == EventType(et) {
+				return true, nil, nil
+			}
+		}
+		return false, nil, nil
+
 	case termInDomain:
 		return e.Event.EventDomain == t.domain, nil, nil
 
 	case termHasChild:
-		return e.invertedRelationMatch(
-			t.eventType,
-			func(id EventID) ([]Event, error) {
-				return e.Graph.Parents(id)
-			},
-			t.cond,
-		)
+		return e.evalHasChild(t)
 
 	case termHasDescendants:
-		max := t.cond.MaxDepth
-		if max <= 0 {
-			max = 1
+		max, err := e.traversalDepth(t.cond.MaxDepth, 1)
+		if err != nil {
+			return false, nil, err
 		}
 
 		// Descendants = events derived FROM the anchor (walk parents upward)
@@ -268,16 +608,37 @@ func (e *EventExpression) evalTerm(t term) (bool, []Event, error) {
 
 		return e.applyConditions(derived, t.eventType, t.cond)
 
+	case termHasExactlyNDescendants:
+		return e.evalHasExactlyNDescendants(t)
+
 	case termHasSiblings:
 		return e.evalHasSiblings(t)
 
+	case termHasSiblingDerivedType:
+		return e.evalHasSiblingDerivedType(t)
+
 	case termHasPeers:
 		return e.evalHasPeers(t)
 
+	case termPeerPropertyRelated:
+		return e.evalPeerPropertyRelated(t)
+
+	case termHasPeersWhere:
+		return e.evalHasPeersWhere(t)
+
+	case termHasCompositionAncestor:
+		return e.evalHasCompositionAncestor(t)
+
+	case termPeersScore:
+		return e.evalPeersScore(t)
+
+	case termHasChildrenAcrossDomains:
+		return e.evalHasChildrenAcrossDomains(t)
+
 	case termHasCousin:
-		max := t.cond.MaxDepth
-		if max == 0 {
-			max = 1
+		max, err := e.traversalDepth(t.cond.MaxDepth, 1)
+		if err != nil {
+			return false, nil, err
 		}
 		cous, err := e.Graph.Cousins(e.Event.ID, max)
 		if err != nil {
@@ -296,6 +657,136 @@ Helpers
 ========================
 */
 
+// evalHasChild matches children of the requested type, then (if set) enforces Conditions.ChildOrder.
+func (e *EventExpression) evalHasChild(t term) (bool, []Event, error) {
+	ok, matched, err := e.invertedRelationMatch(
+		t.eventType,
+		func(id EventID) ([]Event, error) {
+			return e.Graph.Parents(id)
+		},
+		t.cond,
+	)
+	if err != nil || !ok || len(t.cond.ChildOrder) == 0 {
+		return ok, matched, err
+	}
+
+	children, err := e.Graph.Children(e.Event.ID)
+	if err != nil {
+		return false, nil, err
+	}
+	if !childrenInOrder(children, t.cond.ChildOrder) {
+		return false, matched, nil
+	}
+	return true, matched, nil
+}
+
+// evalHasChildrenAcrossDomains counts the distinct domains of the filtered children.
+func (e *EventExpression) evalHasChildrenAcrossDomains(t term) (bool, []Event, error) {
+	children, err := e.Graph.Children(e.Event.ID)
+	if err != nil {
+		return false, nil, err
+	}
+
+	cond := t.cond
+	cond.Counter = nil
+	_, filtered, err := e.applyConditions(children, "", cond)
+	if err != nil {
+		return false, nil, err
+	}
+
+	domains := make(map[EventDomain]struct{}, len(filtered))
+	for _, c := range filtered {
+		domains[c.EventDomain] = struct{}{}
+	}
+	return len(filtered) > 0 && len(domains) >= t.minDomains, filtered, nil
+}
+
+// evalHasExactlyNDescendants counts distinct typed descendants; derivedDescendantsByParents
+// visits every event once, so the count is exact.
+func (e *EventExpression) evalHasExactlyNDescendants(t term) (bool, []Event, error) {
+	max, err := e.traversalDepth(t.cond.MaxDepth, 1)
+	if err != nil {
+		return false, nil, err
+	}
+
+	derived, err := e.derivedDescendantsByParents(e.Event.ID, max)
+	if err != nil {
+		return false, nil, err
+	}
+
+	var matched []Event
+	for _, d := range derived {
+		if d.EventType == EventType(t.eventType) {
+			matched = append(matched, d)
+		}
+	}
+	return len(matched) == t.exactCount, matched, nil
+}
+
+// evalHasSiblingDerivedType keeps the anchor's parents of the requested type that have
+// another child besides the anchor, then applies Conditions to them.
+func (e *EventExpression) evalHasSiblingDerivedType(t term) (bool, []Event, error) {
+	parents, err := e.Graph.Parents(e.Event.ID)
+	if err != nil {
+		return false, nil, err
+	}
+
+	var shared []Event
+	for _, p := range parents {
+		if p.EventType != EventType(t.eventType) {
+			continue
+		}
+		children, err := e.Graph.Children(p.ID)
+		if err != nil {
+			return false, nil, err
+		}
+		for _, c := range children {
+			if c.ID != e.Event.ID {
+				shared = append(shared, p)
+				break
+			}
+		}
+	}
+
+	return e.applyConditions(shared, "", t.cond)
+}
+
+// childrenInOrder reports whether, for each consecutive pair in order, the latest child of the
+// first type is not after the earliest child of the second. Missing types fail the check.
+func childrenInOrder(children []Event, order []EventType) bool {
+	type span struct {
+		earliest, latest time.Time
+		seen             bool
+	}
+	spans := make(map[EventType]*span, len(order))
+	for _, t := range order {
+		spans[t] = &span{}
+	}
+	for _, c := range children {
+		sp, ok := spans[c.EventType]
+		if !ok {
+			continue
+		}
+		if !sp.seen || c.Timestamp.Before(sp.earliest) {
+			sp.earliest = c.Timestamp
+		}
+		if !sp.seen || c.Timestamp.After(sp.latest) {
+			sp.latest = c.Timestamp
+		}
+		sp.seen = true
+	}
+
+	for i, t := range order {
+		if !spans[t].seen {
+			return false
+		}
+		if i > 0 && spans[order[i-1]].latest.After(spans[t].earliest) {
+			return false
+		}
+	}
+	return true
+}
+
 // evalHasSiblings: Evaluation logic for HasSiblings (called from evalTerm):
 //
 // 1. Ask EventNetwork for siblings of the anchor event
@@ -326,10 +817,119 @@ func (e *EventExpression) evalHasSiblings(t term) (bool, []Event, error) {
 // evalHasPeers Evaluation logic for HasPeers:
 //
 //  1. If requested type matches anchor type, use Peers() method (efficient, filters parentless)
-//  2. If requested type differs from anchor type, get all events of requested type and filter parentless
+//  2. If requested type differs from anchor type, get parentless events of requested type
+//     (one ParentlessIndex call when the network supports it, otherwise GetByType + Parents per candidate)
 //  3. Apply Conditions (counter, time, properties)
 func (e *EventExpression) evalHasPeers(t term) (bool, []Event, error) {
-	requestedType := EventType(t.eventType)
+	peers, err := e.peersOfType(EventType(t.eventType))
+	if err != nil {
+		return false, nil, err
+	}
+
+	return e.applyConditions(
+		peers,
+		t.eventType,
+		t.cond,
+	)
+}
+
+// evalPeerPropertyRelated keeps only peers whose numeric property relates to the anchor's,
+// then applies Conditions like HasPeers.
+func (e *EventExpression) evalPeerPropertyRelated(t term) (bool, []Event, error) {
+	peers, err := e.peersOfType(EventType(t.eventType))
+	if err != nil {
+		return false, nil, err
+	}
+
+	related := make([]Event, 0, len(peers))
+	anchorVal, ok := toFloat64(e.Event.Properties[t.propKey])
+	if ok && t.rel != nil {
+		for _, p := range peers {
+			peerVal, ok := toFloat64(p.Properties[t.propKey])
+			if ok && t.rel(anchorVal, peerVal) {
+				related = append(related, p)
+			}
+		}
+	}
+
+	return e.applyConditions(related, t.eventType, t.cond)
+}
+
+// evalHasPeersWhere keeps only peers accepted by the term predicate,
+// then applies Conditions like HasPeers.
+func (e *EventExpression) evalHasPeersWhere(t term) (bool, []Event, error) {
+	peers, err := e.peersOfType(EventType(t.eventType))
+	if err != nil {
+		return false, nil, err
+	}
+
+	accepted := make([]Event, 0, len(peers))
+	if t.peerPred != nil {
+		for _, p := range peers {
+			if t.peerPred(*e.Event, p) {
+				accepted = append(accepted, p)
+			}
+		}
+	}
+
+	return e.applyConditions(accepted, t.eventType, t.cond)
+}
+
+// evalPeersScore sums decayed peer evidence and compares it with the term threshold.
+func (e *EventExpression) evalPeersScore(t term) (bool, []Event, error) {
+	peers, err := e.peersOfType(EventType(t.eventType))
+	if err != nil {
+		return false, nil, err
+	}
+
+	cond := t.cond
+	cond.Counter = nil
+	_, filtered, err := e.applyConditions(peers, t.eventType, cond)
+	if err != nil {
+		return false, nil, err
+	}
+
+	halfLife := t.halfLife.Duration()
+	if halfLife <= 0 {
+		return false, nil, errors.New("PeersScore: halfLife must be positive")
+	}
+
+	var score float64
+	for _, p := range filtered {
+		age := e.Event.Timestamp.Sub(p.Timestamp)
+		if age < 0 {
+			age = -age
+		}
+		score += math.Exp2(-float64(age) / float64(halfLife))
+	}
+
+	return score > t.threshold, filtered, nil
+}
+
+// evalHasCompositionAncestor collects ancestors that are composition events with the requested ID.
+func (e *EventExpression) evalHasCompositionAncestor(t term) (bool, []Event, error) {
+	max, err := e.traversalDepth(t.cond.MaxDepth, math.MaxInt)
+	if err != nil {
+		return false, nil, err
+	}
+
+	ancestors, err := e.derivedDescendantsByParents(e.Event.ID, max)
+	if err != nil {
+		return false, nil, err
+	}
+
+	compositions := make([]Event, 0)
+	for _, a := range ancestors {
+		if id, ok := a.Properties[CompositionIDProperty]; ok && id == t.compositionID {
+			compositions = append(compositions, a)
+		}
+	}
+
+	return e.applyConditions(compositions, "", t.cond)
+}
+
+// peersOfType returns the parentless events of requestedType, excluding the anchor.
+func (e *EventExpression) peersOfType(requestedType EventType) ([]Event, error) {
 	anchorType := e.Event.EventType
 
 	var peers []Event
@@ -339,39 +939,53 @@ func (e *EventExpression) evalHasPeers(t term) (bool, []Event, error) {
 		// Same type: use Peers() which efficiently returns parentless events of anchor type
 		peers, err = e.Graph.Peers(e.Event.ID)
 		if err != nil {
-			return false, nil, err
+			return nil, err
 		}
 	} else {
-		// Different type: get all events of requested type, then filter to parentless ones
-		allCandidates, err := e.Graph.GetByType(requestedType)
+		// Different type: parentless events of requested type, excluding the anchor itself
+		var candidates []Event
+		if idx, ok := e.Graph.(ParentlessIndex); ok {
+			// Single indexed call instead of Parents() per candidate
+			candidates, err = idx.ParentlessByType(requestedType)
+		} else {
+			candidates, err = parentlessByTypeSlow(e.Graph, requestedType)
+		}
 		if err != nil {
-			return false, nil, err
+			return nil, err
 		}
 
-		// Filter to only parentless events (events with no parents)
-		peers = make([]Event, 0)
-		for _, candidate := range allCandidates {
-			// Exclude anchor event itself
+		peers = make([]Event, 0, len(candidates))
+		for _, candidate := range candidates {
 			if candidate.ID == e.Event.ID {
 				continue
 			}
-
-			// Check if candidate is parentless (has no outgoing edges to derived events)
-			parents, err := e.Graph.Parents(candidate.ID)
-			if err != nil {
-				return false, nil, err
-			}
-			if len(parents) == 0 {
-				peers = append(peers, candidate)
-			}
+			peers = append(peers, candidate)
 		}
 	}
 
-	return e.applyConditions(
-		peers,
-		t.eventType,
-		t.cond,
-	)
+	return peers, nil
+}
+
+// parentlessByTypeSlow is the generic fallback for networks without ParentlessIndex:
+// get all events of the type, then keep those with no parents (one Parents() call per candidate).
+func parentlessByTypeSlow(net EventNetwork, eventType EventType) ([]Event, error) {
+	all, err := net.GetByType(eventType)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]Event, 0)
+	for _, candidate := range all {
+		// Check if candidate is parentless (has no outgoing edges to derived events)
+		parents, err := net.Parents(candidate.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(parents) == 0 {
+			out = append(out, candidate)
+		}
+	}
+	return out, nil
 }
 
 // applyConditionsForTypedSet Shared helper for typed sets (siblings / peers)
@@ -401,25 +1015,13 @@ func (e *EventExpression) applyConditionsForTypedSet(
 		}
 
 		// Time window constraint
-		if cond.TimeWindow != nil {
-			d := cond.TimeWindow.TimeUnit.ToDuration(cond.TimeWindow.Within)
-			if ev.Timestamp.Before(anchorTS.Add(-d)) || ev.Timestamp.After(anchorTS) {
-				continue
-			}
+		if !cond.admitsTime(anchorTS, ev.Timestamp) {
+			continue
 		}
 
 		// Property constraints
-		if cond.PropertyValues != nil {
-			ok := true
-			for k, v := range cond.PropertyValues {
-				if ev.Properties[k] != v {
-					ok = false
-					break
-				}
-			}
-			if !ok {
-				continue
-			}
+		if !matchConditionProperties(ev.Properties, cond) {
+			continue
 		}
 
 		matches = append(matches, ev)
@@ -427,10 +1029,11 @@ func (e *EventExpression) applyConditionsForTypedSet(
 
 	// Counter logic
 	if cond.Counter != nil {
-		if cond.Counter.HowManyOrMore {
-			return len(matches) >= cond.Counter.HowMany, matches, nil
+		ok, err := cond.Counter.satisfiedBy(len(matches))
+		if err != nil {
+			return false, nil, err
 		}
-		return len(matches) == cond.Counter.HowMany, matches, nil
+		return ok, matches, nil
 	}
 
 	return len(matches) > 0, matches, nil
@@ -539,36 +1142,23 @@ func (e *EventExpression) applyConditions(
 			}
 		}
 
-		if cond.TimeWindow != nil {
-			d := cond.TimeWindow.TimeUnit.ToDuration(cond.TimeWindow.Within)
-			// Time window: events must be within [anchorTS - d, anchorTS + d]
-			// This allows events both before and after the anchor within the window
-			if ev.Timestamp.Before(anchorTS.Add(-d)) || ev.Timestamp.After(anchorTS.Add(d)) {
-				continue
-			}
+		if !cond.admitsTime(anchorTS, ev.Timestamp) {
+			continue
 		}
 
-		if cond.PropertyValues != nil {
-			ok := true
-			for k, v := range cond.PropertyValues {
-				if ev.Properties[k] != v {
-					ok = false
-					break
-				}
-			}
-			if !ok {
-				continue
-			}
+		if !matchConditionProperties(ev.Properties, cond) {
+			continue
 		}
 		result = append(result, ev)
 		matches++
 	}
 
 	if cond.Counter != nil {
-		if cond.Counter.HowManyOrMore {
-			return matches >= cond.Counter.HowMany, result, nil
+		ok, err := cond.Counter.satisfiedBy(matches)
+		if err != nil {
+			return false, nil, err
 		}
-		return matches == cond.Counter.HowMany, result, nil
+		return ok, result, nil
 	}
 
 	return matches > 0, result, nil
@@ -585,19 +1175,37 @@ func toRPN(tokens []token) ([]token, error) {
 	var stack []token
 
 	prec := func(op opKind) int {
-		if op == opAnd {
+		switch op {
+		case opNot:
+			return 4
+		case opAnd:
+			return 3
+		case opXor:
 			return 2
 		}
 		return 1
 	}
 
-	for _, tk := range tokens {
+	for i, tk := range tokens {
 		switch tk.kind {
 
 		case tkTerm:
 			out = append(out, tk)
 
 		case tkOp:
+			if tk.op == opNot {
+				// Prefix unary: must be followed by a term, a group or another Not,
+				// and must not pop anything (so Not Not cancels out right-to-left).
+				if i+1 == len(tokens) {
+					return nil, errors.New("invalid expression")
+				}
+				next := tokens[i+1]
+				if next.kind == tkRParen || (next.kind == tkOp && next.op != opNot) {
+					return nil, errors.New("invalid expression")
+				}
+				stack = append(stack, tk)
+				continue
+			}
 			for len(stack) > 0 {
 				top := stack[len(stack)-1]
 				if top.kind == tkOp && prec(top.op) >= prec(tk.op) {
@@ -672,6 +1280,16 @@ func (e *EventExpression) derivedDescendantsByParents(of EventID, maxDepth int)
 	return out, nil
 }
 
-func (e *EventExpression) IsAnyOfTypes(eventTypes []string, condition Conditions) *EventExpression {
-	panic("Implement me!")
+// IsAnyOfTypes matches when the anchor's EventType is one of eventTypes.
+// It is the multi-type form of IsTypeOf; an empty slice never matches.
+func (e *EventExpression) IsAnyOfTypes(eventTypes []string, cond Conditions) *EventExpression {
+	e.tokens = append(e.tokens, token{
+		kind: tkTerm,
+		term: term{
+			kind:       termIsAnyOfTypes,
+			eventTypes: append([]string(nil), eventTypes...),
+			cond:       cond,
+		},
+	})
+	return e
 }
@@ -0,0 +1,111 @@
+package event_network
+
+import (
+	"errors"
+	"sort"
+)
+
+// CandidateResult explains why one candidate did or did not count for a peer term.
+// Each check is true when it passed (or does not apply to the term).
+type CandidateResult struct {
+	Event Event
+
+	// Type: the candidate has the term's event type.
+	Type bool
+	// Domain: same domain as the anchor; only required for same-type peers.
+	Domain bool
+	// Parentless: no derived event was created from the candidate yet.
+	Parentless bool
+	// Window: inside Conditions.TimeWindow around the anchor, and not a zero
+	// timestamp when Conditions.RequireTimestamp is set.
+	Window bool
+	// Property: satisfies Conditions.PropertyValues and, for PeerPropertyRelated,
+	// the property relation with the anchor (for HasPeersWhere, the predicate).
+	Property bool
+
+	// Matched: all checks passed, i.e. the candidate is counted by the term.
+	Matched bool
+}
+
+// EvalCandidates diagnoses a single peer term (HasPeers, PeerPropertyRelated or PeersScore).
+// build adds the term to a fresh expression with the same graph and anchor, e.g.
+//
+//	expr.EvalCandidates(func(x *EventExpression) *EventExpression {
+//		return x.HasPeers(CpuStatusChanged, cond)
+//	})
+//
+// Candidates are the events of the term's type plus the events of the anchor's domain
+// (anchor excluded), oldest first, each with the sub-checks it passed or failed.
+// Conditions.Counter is not applied: count the Matched results instead.
+func (e *EventExpression) EvalCandidates(build func(x *EventExpression) *EventExpression) ([]CandidateResult, error) {
+	if build == nil {
+		return nil, errors.New("EvalCandidates: nil build func")
+	}
+
+	x := build(NewExpression(e.Graph, e.Event))
+	var t *term
+	for i := range x.tokens {
+		if x.tokens[i].kind == tkTerm {
+			t = &x.tokens[i].term
+			break
+		}
+	}
+	if t == nil {
+		return nil, errors.New("EvalCandidates: build added no term")
+	}
+	switch t.kind {
+	case termHasPeers, termPeerPropertyRelated, termHasPeersWhere, termPeersScore:
+	default:
+		return nil, errors.New("EvalCandidates: only HasPeers, PeerPropertyRelated, HasPeersWhere and PeersScore terms are supported")
+	}
+
+	requested := EventType(t.eventType)
+	byType, err := e.Graph.GetByType(requested)
+	if err != nil {
+		return nil, err
+	}
+	inDomain, err := e.Graph.GetByDomain(e.Event.EventDomain)
+	if err != nil {
+		return nil, err
+	}
+
+	anchorVal, anchorOK := toFloat64(e.Event.Properties[t.propKey])
+
+	seen := map[EventID]bool{e.Event.ID: true}
+	results := make([]CandidateResult, 0, len(byType))
+	for _, ev := range append(byType, inDomain...) {
+		if seen[ev.ID] {
+			continue
+		}
+		seen[ev.ID] = true
+
+		parents, err := e.Graph.Parents(ev.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		r := CandidateResult{
+			Event:      ev,
+			Type:       ev.EventType == requested,
+			Domain:     requested != e.Event.EventType || ev.EventDomain == e.Event.EventDomain,
+			Parentless: len(parents) == 0,
+			Window:     t.cond.admitsTime(e.Event.Timestamp, ev.Timestamp),
+			Property:   matchConditionProperties(ev.Properties, t.cond),
+		}
+		if t.kind == termPeerPropertyRelated {
+			peerVal, ok := toFloat64(ev.Properties[t.propKey])
+			r.Property = r.Property && anchorOK && ok && t.rel != nil && t.rel(anchorVal, peerVal)
+		}
+		if t.kind == termHasPeersWhere {
+			r.Property = r.Property && t.peerPred != nil && t.peerPred(*e.Event, ev)
+		}
+		r.Matched = r.Type && r.Domain && r.Parentless && r.Window && r.Property
+
+		results = append(results, r)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Event.Timestamp.Before(results[j].Event.Timestamp)
+	})
+	return results, nil
+}
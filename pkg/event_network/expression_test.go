@@ -1,8 +1,13 @@
 package event_network
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 )
 
 func TestExpression_IsTypeOf(t *testing.T) {
@@ -1413,3 +1418,1552 @@ func TestExpression_EvalTerm_AllCases(t *testing.T) {
 		// For now, we test the happy path
 	})
 }
+
+func TestExpression_PropertyFilter_JSONDecodedNumbers(t *testing.T) {
+	net := NewInMemoryEventNetwork()
+	// JSON decoding turns "occurs": 3 into float64(3)
+	for i := 0; i < 2; i++ {
+		_, err := net.AddEvent(Event{
+			EventType:   CpuStatusChanged,
+			EventDomain: InfraDomain,
+			Properties:  toProps(`{"occurs": 3, "level": "critical"}`),
+		})
+		require.NoError(t, err)
+	}
+	anchorID, err := net.AddEvent(Event{
+		EventType:   CpuStatusChanged,
+		EventDomain: InfraDomain,
+		Properties:  toProps(`{"occurs": 3, "level": "critical"}`),
+	})
+	require.NoError(t, err)
+	anchor, err := net.GetByID(anchorID)
+	require.NoError(t, err)
+	require.IsType(t, float64(0), anchor.Properties["occurs"])
+
+	for _, literal := range []any{3, int64(3), float64(3.0), uint8(3)} {
+		ok, matched, err := NewExpression(net, &anchor).
+			HasPeers(CpuStatusChanged, Conditions{
+				PropertyValues: map[string]any{"occurs": literal},
+			}).
+			Eval()
+		require.NoError(t, err)
+		require.True(t, ok, "literal %T should match float64", literal)
+		require.Len(t, matched, 2)
+	}
+
+	ok, _, err := NewExpression(net, &anchor).
+		HasPeers(CpuStatusChanged, Conditions{
+			PropertyValues: map[string]any{"occurs": 4},
+		}).
+		Eval()
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// Standalone (cached) filtering normalizes numbers the same way
+	filtered, err := applyFilterAndConditionsStandalone(anchorID, net, []Event{anchor}, Conditions{
+		PropertyValues: map[string]any{"occurs": 3},
+	}, "")
+	require.NoError(t, err)
+	require.Len(t, filtered, 1)
+}
+
+func TestPropertyValueEquals(t *testing.T) {
+	require.True(t, propertyValueEquals(3, float64(3)))
+	require.True(t, propertyValueEquals(int64(3), 3))
+	require.True(t, propertyValueEquals(float32(2.5), 2.5))
+	require.False(t, propertyValueEquals(3, 3.5))
+	require.False(t, propertyValueEquals(3, "3"))
+	require.True(t, propertyValueEquals("critical", "critical"))
+	require.True(t, propertyValueEquals(nil, nil))
+	require.True(t, propertyValueEquals(map[string]any{"a": 1}, map[string]any{"a": 1}))
+}
+
+func TestExpression_EvalGrouped(t *testing.T) {
+	net := NewInMemoryEventNetwork()
+	for _, p := range []float64{91, 92} {
+		_, err := addCpuStatusChangedEvent(net, p, "critical")
+		require.NoError(t, err)
+	}
+	for _, p := range []float64{85, 86, 87} {
+		_, err := addMemoryStatusChangedEvent(net, p, "critical")
+		require.NoError(t, err)
+	}
+	anchorID, err := addCpuStatusChangedEvent(net, 93, "critical")
+	require.NoError(t, err)
+	anchor, _ := net.GetByID(anchorID)
+
+	t.Run("two-term Or groups matches per term", func(t *testing.T) {
+		ok, grouped, err := NewExpression(net, &anchor).
+			HasPeers(CpuStatusChanged, Conditions{}).
+			Or().
+			HasPeers(MemoryStatusChanged, Conditions{}).
+			EvalGrouped()
+
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Len(t, grouped, 2)
+		require.Len(t, grouped[0], 2)
+		for _, ev := range grouped[0] {
+			require.Equal(t, EventType(CpuStatusChanged), ev.EventType)
+		}
+		require.Len(t, grouped[1], 3)
+		for _, ev := range grouped[1] {
+			require.Equal(t, EventType(MemoryStatusChanged), ev.EventType)
+		}
+	})
+
+	t.Run("term index follows written order with grouping", func(t *testing.T) {
+		ok, grouped, err := NewExpression(net, &anchor).
+			IsTypeOf(CpuStatusChanged, Conditions{}).
+			And().
+			Group().
+			HasPeers(MemoryStatusChanged, Conditions{}).
+			Or().
+			HasPeers(CpuStatusChanged, Conditions{}).
+			Ungroup().
+			EvalGrouped()
+
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Len(t, grouped, 3)
+		require.Empty(t, grouped[0])
+		require.Len(t, grouped[1], 3)
+		require.Len(t, grouped[2], 2)
+	})
+
+	t.Run("flat Eval returns the union of grouped matches", func(t *testing.T) {
+		_, flat, err := NewExpression(net, &anchor).
+			HasPeers(CpuStatusChanged, Conditions{}).
+			Or().
+			HasPeers(MemoryStatusChanged, Conditions{}).
+			Eval()
+		require.NoError(t, err)
+		require.Len(t, flat, 5)
+	})
+
+	t.Run("empty expression", func(t *testing.T) {
+		_, grouped, err := NewExpression(net, &anchor).EvalGrouped()
+		require.Error(t, err)
+		require.Nil(t, grouped)
+	})
+}
+
+func TestExpression_PropertyMatchers(t *testing.T) {
+	net := NewInMemoryEventNetwork()
+	for _, props := range []EventProps{
+		{"level": "critical", "percentage": 97.5},
+		{"level": "fatal", "percentage": 99},
+		{"level": "warning", "percentage": 85.0},
+	} {
+		_, err := net.AddEvent(Event{EventType: CpuStatusChanged, EventDomain: InfraDomain, Properties: props})
+		require.NoError(t, err)
+	}
+	anchorID, err := net.AddEvent(Event{EventType: CpuStatusChanged, EventDomain: InfraDomain})
+	require.NoError(t, err)
+	anchor, _ := net.GetByID(anchorID)
+
+	peers := func(cond Conditions) []Event {
+		cond.Counter = &Counter{HowMany: 1, HowManyOrMore: true}
+		_, matched, err := NewExpression(net, &anchor).HasPeers(CpuStatusChanged, cond).Eval()
+		require.NoError(t, err)
+		return matched
+	}
+	levels := func(events []Event) []any {
+		var out []any
+		for _, ev := range events {
+			out = append(out, ev.Properties["level"])
+		}
+		return out
+	}
+
+	t.Run("numeric threshold", func(t *testing.T) {
+		matched := peers(Conditions{PropertyMatchers: map[string]PropertyMatcher{"percentage": GreaterThan(90)}})
+		require.ElementsMatch(t, []any{"critical", "fatal"}, levels(matched))
+
+		matched = peers(Conditions{PropertyMatchers: map[string]PropertyMatcher{"percentage": LessThan(90)}})
+		require.ElementsMatch(t, []any{"warning"}, levels(matched))
+	})
+
+	t.Run("set membership", func(t *testing.T) {
+		matched := peers(Conditions{PropertyMatchers: map[string]PropertyMatcher{"level": OneOf("critical", "fatal")}})
+		require.ElementsMatch(t, []any{"critical", "fatal"}, levels(matched))
+	})
+
+	t.Run("combined with PropertyValues", func(t *testing.T) {
+		matched := peers(Conditions{
+			PropertyValues:   map[string]any{"level": "fatal"},
+			PropertyMatchers: map[string]PropertyMatcher{"percentage": GreaterThan(90)},
+		})
+		require.ElementsMatch(t, []any{"fatal"}, levels(matched))
+	})
+
+	t.Run("missing or non-numeric values never match", func(t *testing.T) {
+		require.Empty(t, peers(Conditions{PropertyMatchers: map[string]PropertyMatcher{"load": GreaterThan(0)}}))
+		require.Empty(t, peers(Conditions{PropertyMatchers: map[string]PropertyMatcher{"level": GreaterThan(0)}}))
+	})
+
+	t.Run("memoized network does not mix up matchers", func(t *testing.T) {
+		mem := NewInMemoryStructuralMemory()
+		memo := NewMemoizedNetwork(net, mem)
+		eval := func(m PropertyMatcher) []Event {
+			_, matched, err := NewExpression(memo, &anchor).HasPeers(CpuStatusChanged, Conditions{
+				Counter:          &Counter{HowMany: 1, HowManyOrMore: true},
+				PropertyMatchers: map[string]PropertyMatcher{"percentage": m},
+			}).Eval()
+			require.NoError(t, err)
+			return matched
+		}
+		require.Len(t, eval(GreaterThan(98)), 1)
+		require.Len(t, eval(GreaterThan(90)), 2)
+	})
+}
+
+func TestExpression_NestedPropertyPaths(t *testing.T) {
+	net := NewInMemoryEventNetwork()
+	_, err := net.AddEvent(Event{
+		EventType:   CpuStatusChanged,
+		EventDomain: InfraDomain,
+		Properties: EventProps{
+			"prop1":  10,
+			"props4": map[string]any{"objProp": "hej", "load": map[string]any{"avg": 93.5}},
+		},
+	})
+	require.NoError(t, err)
+	anchorID, err := net.AddEvent(Event{EventType: CpuStatusChanged, EventDomain: InfraDomain})
+	require.NoError(t, err)
+	anchor, _ := net.GetByID(anchorID)
+
+	matches := func(cond Conditions) bool {
+		ok, _, err := NewExpression(net, &anchor).HasPeers(CpuStatusChanged, cond).Eval()
+		require.NoError(t, err)
+		return ok
+	}
+
+	require.True(t, matches(Conditions{PropertyValues: map[string]any{"props4.objProp": "hej"}}))
+	require.False(t, matches(Conditions{PropertyValues: map[string]any{"props4.objProp": "hello"}}))
+	require.True(t, matches(Conditions{PropertyMatchers: map[string]PropertyMatcher{"props4.load.avg": GreaterThan(90)}}))
+
+	t.Run("missing intermediate key", func(t *testing.T) {
+		require.False(t, matches(Conditions{PropertyValues: map[string]any{"props5.objProp": "hej"}}))
+		require.False(t, matches(Conditions{PropertyValues: map[string]any{"props4.cpu.avg": 93.5}}))
+		require.False(t, matches(Conditions{PropertyMatchers: map[string]PropertyMatcher{"props4.cpu.avg": GreaterThan(0)}}))
+		// a scalar cannot be descended into
+		require.False(t, matches(Conditions{PropertyValues: map[string]any{"prop1.value": 10}}))
+	})
+
+	t.Run("exact counts a path as its top-level key", func(t *testing.T) {
+		require.True(t, matches(Conditions{
+			PropertyValues:    map[string]any{"prop1": 10, "props4.objProp": "hej"},
+			PropertyMatchMode: Exact,
+		}))
+		require.False(t, matches(Conditions{
+			PropertyValues:    map[string]any{"props4.objProp": "hej"},
+			PropertyMatchMode: Exact,
+		}))
+	})
+}
+
+func TestExpression_PropertyMatchMode(t *testing.T) {
+	net := NewInMemoryEventNetwork()
+	// Peer with extra properties beyond the condition map
+	_, err := net.AddEvent(Event{
+		EventType:   CpuStatusChanged,
+		EventDomain: InfraDomain,
+		Properties:  EventProps{"level": "critical", "percentage": 97.5},
+	})
+	require.NoError(t, err)
+	anchorID, err := net.AddEvent(Event{EventType: CpuStatusChanged, EventDomain: InfraDomain})
+	require.NoError(t, err)
+	anchor, _ := net.GetByID(anchorID)
+
+	want := map[string]any{"level": "critical"}
+
+	t.Run("subset is the default and allows extra properties", func(t *testing.T) {
+		ok, matched, err := NewExpression(net, &anchor).
+			HasPeers(CpuStatusChanged, Conditions{PropertyValues: want}).
+			Eval()
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Len(t, matched, 1)
+	})
+
+	t.Run("exact rejects extra properties", func(t *testing.T) {
+		ok, matched, err := NewExpression(net, &anchor).
+			HasPeers(CpuStatusChanged, Conditions{PropertyValues: want, PropertyMatchMode: Exact}).
+			Eval()
+		require.NoError(t, err)
+		require.False(t, ok)
+		require.Empty(t, matched)
+	})
+
+	t.Run("exact accepts the identical property set", func(t *testing.T) {
+		ok, _, err := NewExpression(net, &anchor).
+			HasPeers(CpuStatusChanged, Conditions{
+				PropertyValues:    map[string]any{"level": "critical", "percentage": 97.5},
+				PropertyMatchMode: Exact,
+			}).
+			Eval()
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("exact compares key sets, not just their size", func(t *testing.T) {
+		net := NewInMemoryEventNetwork()
+		_, err := net.AddEvent(Event{
+			EventType:   CpuStatusChanged,
+			EventDomain: InfraDomain,
+			Properties:  EventProps{"b": 1, "c": 2},
+		})
+		require.NoError(t, err)
+		anchorID, err := net.AddEvent(Event{EventType: CpuStatusChanged, EventDomain: InfraDomain})
+		require.NoError(t, err)
+		anchor, _ := net.GetByID(anchorID)
+
+		// A nil wanted value accepts a missing key in subset mode only
+		cond := Conditions{PropertyValues: map[string]any{"a": nil, "b": 1}}
+		ok, _, err := NewExpression(net, &anchor).HasPeers(CpuStatusChanged, cond).Eval()
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		cond.PropertyMatchMode = Exact
+		ok, matched, err := NewExpression(net, &anchor).HasPeers(CpuStatusChanged, cond).Eval()
+		require.NoError(t, err)
+		require.False(t, ok)
+		require.Empty(t, matched)
+	})
+}
+
+func TestHashConditions_IncludesPropertyMatchMode(t *testing.T) {
+	subset := Conditions{PropertyValues: map[string]any{"level": "critical"}}
+	exact := Conditions{PropertyValues: map[string]any{"level": "critical"}, PropertyMatchMode: Exact}
+	require.NotEqual(t, HashConditions(subset), HashConditions(exact))
+}
+
+func TestHashConditions(t *testing.T) {
+	base := func() Conditions {
+		return Conditions{
+			Counter:    &Counter{HowMany: 2, HowManyOrMore: true},
+			TimeWindow: &TimeWindow{Within: 10, TimeUnit: Minute},
+			PropertyValues: map[string]any{
+				"level": "critical",
+				"host":  map[string]any{"zone": "eu", "rack": []any{1, "b"}},
+			},
+		}
+	}
+
+	t.Run("reordered property maps hash equally", func(t *testing.T) {
+		reordered := base()
+		reordered.PropertyValues = map[string]any{
+			"host":  map[string]any{"rack": []any{1.0, "b"}, "zone": "eu"},
+			"level": "critical",
+		}
+		for i := 0; i < 20; i++ {
+			require.Equal(t, HashConditions(base()), HashConditions(reordered))
+		}
+	})
+
+	t.Run("distinct conditions hash differently", func(t *testing.T) {
+		window := base()
+		window.TimeWindow = &TimeWindow{Within: 10, TimeUnit: Hour}
+		counter := base()
+		counter.Counter = &Counter{HowMany: 2}
+		nested := base()
+		nested.PropertyValues["host"] = map[string]any{"zone": "us", "rack": []any{1, "b"}}
+		typed := base()
+		typed.PropertyValues["level"] = 1
+		stringly := base()
+		stringly.PropertyValues["level"] = "1"
+		ofType := base()
+		ofType.OfEventType = CpuCritical
+
+		hashes := map[uint64]string{HashConditions(base()): "base"}
+		for name, c := range map[string]Conditions{
+			"window": window, "counter": counter, "nested": nested,
+			"typed": typed, "stringly": stringly, "ofType": ofType,
+		} {
+			h := HashConditions(c)
+			require.NotContains(t, hashes, h, "%s collides with %s", name, hashes[h])
+			hashes[h] = name
+		}
+	})
+}
+
+// unindexedNetwork hides optional extensions (e.g. ParentlessIndex) of the wrapped network,
+// forcing the generic per-candidate code paths.
+type unindexedNetwork struct {
+	EventNetwork
+}
+
+func buildCrossTypePeersNetwork(tb testing.TB, candidates int) (*InMemoryEventNetwork, Event) {
+	tb.Helper()
+	net := NewInMemoryEventNetwork()
+	derivedID, err := net.AddEvent(Event{EventType: MemoryCritical, EventDomain: InfraDomain})
+	require.NoError(tb, err)
+	for i := 0; i < candidates; i++ {
+		id, err := addMemoryStatusChangedEvent(net, float64(i%100), "critical")
+		require.NoError(tb, err)
+		// every 3rd candidate gets a parent, so it's no longer a peer
+		if i%3 == 0 {
+			require.NoError(tb, net.AddEdge(id, derivedID, "trigger"))
+		}
+	}
+	anchorID, err := addCpuStatusChangedEvent(net, 99, "critical")
+	require.NoError(tb, err)
+	anchor, err := net.GetByID(anchorID)
+	require.NoError(tb, err)
+	return net, anchor
+}
+
+func TestExpression_HasPeers_CrossTypeIndexedMatchesGeneric(t *testing.T) {
+	net, anchor := buildCrossTypePeersNetwork(t, 300)
+
+	for _, cond := range []Conditions{
+		{},
+		{Counter: &Counter{HowMany: 200}},
+		{Counter: &Counter{HowMany: 201}},
+		{PropertyValues: map[string]any{"percentage": 5}},
+	} {
+		okIndexed, indexed, err := NewExpression(net, &anchor).HasPeers(MemoryStatusChanged, cond).Eval()
+		require.NoError(t, err)
+		okGeneric, generic, err := NewExpression(unindexedNetwork{net}, &anchor).HasPeers(MemoryStatusChanged, cond).Eval()
+		require.NoError(t, err)
+
+		require.Equal(t, okGeneric, okIndexed)
+		require.ElementsMatch(t, collectIDs(generic), collectIDs(indexed))
+	}
+
+	// MemoizedNetwork delegates to the indexed base network
+	memoized := NewMemoizedNetwork(net, NewInMemoryStructuralMemory())
+	ok, peers, err := NewExpression(memoized, &anchor).HasPeers(MemoryStatusChanged, Conditions{}).Eval()
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Len(t, peers, 200)
+}
+
+func BenchmarkEvalHasPeersCrossType(b *testing.B) {
+	net, anchor := buildCrossTypePeersNetwork(b, 50_000)
+
+	b.Run("indexed", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _, _ = NewExpression(net, &anchor).HasPeers(MemoryStatusChanged, Conditions{}).Eval()
+		}
+	})
+
+	b.Run("per-candidate", func(b *testing.B) {
+		generic := unindexedNetwork{net}
+		for i := 0; i < b.N; i++ {
+			_, _, _ = NewExpression(generic, &anchor).HasPeers(MemoryStatusChanged, Conditions{}).Eval()
+		}
+	})
+}
+
+func TestExpression_PeerPropertyRelated(t *testing.T) {
+	withinTenPercent := func(anchorVal, peerVal float64) bool {
+		diff := anchorVal - peerVal
+		if diff < 0 {
+			diff = -diff
+		}
+		return diff <= anchorVal*0.1
+	}
+
+	net := NewInMemoryEventNetwork()
+	for _, p := range []float64{92, 98, 104, 50, 150} {
+		_, err := addCpuStatusChangedEvent(net, p, "critical")
+		require.NoError(t, err)
+	}
+	// peer without the numeric property is skipped
+	_, err := net.AddEvent(Event{EventType: CpuStatusChanged, EventDomain: InfraDomain})
+	require.NoError(t, err)
+
+	anchorID, err := addCpuStatusChangedEvent(net, 100, "critical")
+	require.NoError(t, err)
+	anchor, _ := net.GetByID(anchorID)
+
+	t.Run("matches peers within tolerance and excludes outliers", func(t *testing.T) {
+		ok, matched, err := NewExpression(net, &anchor).
+			PeerPropertyRelated(CpuStatusChanged, "percentage", withinTenPercent, Conditions{}).
+			Eval()
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Len(t, matched, 3)
+		for _, ev := range matched {
+			require.InDelta(t, 100, ev.Properties["percentage"].(float64), 10)
+		}
+	})
+
+	t.Run("counter applies to related peers", func(t *testing.T) {
+		ok, _, err := NewExpression(net, &anchor).
+			PeerPropertyRelated(CpuStatusChanged, "percentage", withinTenPercent, Conditions{
+				Counter: &Counter{HowMany: 4, HowManyOrMore: true},
+			}).
+			Eval()
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("anchor without numeric property matches nothing", func(t *testing.T) {
+		ok, matched, err := NewExpression(net, &anchor).
+			PeerPropertyRelated(CpuStatusChanged, "level", withinTenPercent, Conditions{}).
+			Eval()
+		require.NoError(t, err)
+		require.False(t, ok)
+		require.Empty(t, matched)
+	})
+
+	t.Run("compiled from Condition", func(t *testing.T) {
+		spec := NewCondition().
+			PeerPropertyRelated(CpuStatusChanged, "percentage", withinTenPercent, Conditions{})
+		expr, err := NewConditionCompiler(net).Compile(spec, &anchor)
+		require.NoError(t, err)
+		ok, matched, err := expr.Eval()
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Len(t, matched, 3)
+	})
+}
+
+func TestExpression_IsAnyOfTypes(t *testing.T) {
+	net, _, childs := buildInfraSubGraph(t)
+	ev, _ := net.GetByID(childs.CpuEventsIDs[0])
+
+	t.Run("matching type", func(t *testing.T) {
+		ok, _, err := NewExpression(net, &ev).
+			IsAnyOfTypes([]string{MemoryStatusChanged, CpuStatusChanged}, Conditions{}).
+			Eval()
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("non-matching type", func(t *testing.T) {
+		ok, _, err := NewExpression(net, &ev).
+			IsAnyOfTypes([]string{MemoryStatusChanged, CpuCritical}, Conditions{}).
+			Eval()
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("empty slice evaluates false", func(t *testing.T) {
+		ok, _, err := NewExpression(net, &ev).
+			IsAnyOfTypes(nil, Conditions{}).
+			Eval()
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("compiled from Condition", func(t *testing.T) {
+		expr, err := NewConditionCompiler(net).Compile(
+			NewCondition().IsAnyOfTypes([]EventType{CpuStatusChanged}, Conditions{}).And().InDomain(InfraDomain),
+			&ev,
+		)
+		require.NoError(t, err)
+		ok, _, err := expr.Eval()
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+}
+
+func TestExpression_HasCompositionAncestor(t *testing.T) {
+	net := NewInMemoryEventNetwork()
+	leafID, err := addCpuStatusChangedEvent(net, 98.3, "critical")
+	require.NoError(t, err)
+	patternID, err := net.AddEvent(Event{EventType: CpuCritical, EventDomain: InfraDomain})
+	require.NoError(t, err)
+	compositionID, err := net.AddEvent(Event{
+		EventType:   "safety_review_required",
+		EventDomain: "governance",
+		Properties:  EventProps{CompositionIDProperty: "safety-review"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, net.AddEdge(leafID, patternID, "trigger"))
+	require.NoError(t, net.AddEdge(patternID, compositionID, "pattern_composition"))
+
+	pattern, _ := net.GetByID(patternID)
+	leaf, _ := net.GetByID(leafID)
+
+	t.Run("direct contributor to the composition matches", func(t *testing.T) {
+		ok, matched, err := NewExpression(net, &pattern).
+			HasCompositionAncestor("safety-review", Conditions{}).
+			Eval()
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, []EventID{compositionID}, collectIDs(matched))
+	})
+
+	t.Run("transitive contributor matches with unlimited depth", func(t *testing.T) {
+		ok, _, err := NewExpression(net, &leaf).
+			HasCompositionAncestor("safety-review", Conditions{}).
+			Eval()
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("MaxDepth limits the walk", func(t *testing.T) {
+		ok, _, err := NewExpression(net, &leaf).
+			HasCompositionAncestor("safety-review", Conditions{MaxDepth: 1}).
+			Eval()
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("other composition ID does not match", func(t *testing.T) {
+		ok, _, err := NewExpression(net, &pattern).
+			HasCompositionAncestor("other", Conditions{}).
+			Eval()
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("compiled from Condition", func(t *testing.T) {
+		expr, err := NewConditionCompiler(net).Compile(
+			NewCondition().HasCompositionAncestor("safety-review", Conditions{}),
+			&leaf,
+		)
+		require.NoError(t, err)
+		ok, _, err := expr.Eval()
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+}
+
+func TestExpression_Not(t *testing.T) {
+	net, parents, _ := buildInfraSubGraph(t)
+	ev, _ := net.GetByID(parents.CpuCriticalID)
+
+	t.Run("negates the following term", func(t *testing.T) {
+		ok, _, err := NewExpression(net, &ev).
+			Not().
+			HasChild(CpuStatusChanged, Conditions{}).
+			Eval()
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		ok, _, err = NewExpression(net, &ev).
+			Not().
+			HasChild(MemoryStatusChanged, Conditions{}).
+			Eval()
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("binds tighter than And", func(t *testing.T) {
+		// (NOT HasChild(Memory)) AND HasChild(Cpu)
+		ok, _, err := NewExpression(net, &ev).
+			Not().
+			HasChild(MemoryStatusChanged, Conditions{}).
+			And().
+			HasChild(CpuStatusChanged, Conditions{}).
+			Eval()
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		// HasChild(Cpu) AND (NOT HasChild(Cpu))
+		ok, _, err = NewExpression(net, &ev).
+			HasChild(CpuStatusChanged, Conditions{}).
+			And().
+			Not().
+			HasChild(CpuStatusChanged, Conditions{}).
+			Eval()
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("negates a group", func(t *testing.T) {
+		ok, _, err := NewExpression(net, &ev).
+			Not().
+			Group().
+			HasChild(MemoryStatusChanged, Conditions{}).
+			Or().
+			HasChild(CpuStatusChanged, Conditions{}).
+			Ungroup().
+			Eval()
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("double negation cancels", func(t *testing.T) {
+		ok, _, err := NewExpression(net, &ev).
+			Not().
+			Not().
+			HasChild(CpuStatusChanged, Conditions{}).
+			Eval()
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("Not at end is invalid", func(t *testing.T) {
+		_, _, err := NewExpression(net, &ev).
+			HasChild(CpuStatusChanged, Conditions{}).
+			Not().
+			Eval()
+		require.EqualError(t, err, "invalid expression")
+
+		_, _, err = NewExpression(net, &ev).
+			Not().
+			And().
+			HasChild(CpuStatusChanged, Conditions{}).
+			Eval()
+		require.EqualError(t, err, "invalid expression")
+	})
+}
+
+func TestExpression_XorPrecedence(t *testing.T) {
+	net, parents, _ := buildInfraSubGraph(t)
+	ev, _ := net.GetByID(parents.CpuCriticalID)
+
+	// For the CpuCritical anchor HasChild(CpuStatusChanged) is true
+	// and HasChild(MemoryStatusChanged) is false.
+
+	t.Run("true when exactly one side is true", func(t *testing.T) {
+		ok, _, err := NewExpression(net, &ev).
+			HasChild(CpuStatusChanged, Conditions{}).
+			Xor().
+			HasChild(MemoryStatusChanged, Conditions{}).
+			Eval()
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		ok, _, err = NewExpression(net, &ev).
+			HasChild(CpuStatusChanged, Conditions{}).
+			Xor().
+			HasChild(CpuStatusChanged, Conditions{}).
+			Eval()
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("truth table", func(t *testing.T) {
+		term := map[bool]EventType{true: CpuStatusChanged, false: MemoryStatusChanged}
+		for _, tc := range []struct{ a, b, want bool }{
+			{false, false, false},
+			{false, true, true},
+			{true, false, true},
+			{true, true, false},
+		} {
+			ok, _, err := NewExpression(net, &ev).
+				HasChild(term[tc.a], Conditions{}).
+				Xor().
+				HasChild(term[tc.b], Conditions{}).
+				Eval()
+			require.NoError(t, err)
+			require.Equal(t, tc.want, ok, "%v XOR %v", tc.a, tc.b)
+		}
+	})
+
+	t.Run("between And and Or", func(t *testing.T) {
+		// (false AND true) XOR true OR false = (false XOR true) OR false = true
+		ok, _, err := NewExpression(net, &ev).
+			HasChild(MemoryStatusChanged, Conditions{}).
+			And().
+			HasChild(CpuStatusChanged, Conditions{}).
+			Xor().
+			HasChild(CpuStatusChanged, Conditions{}).
+			Or().
+			HasChild(MemoryStatusChanged, Conditions{}).
+			Eval()
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("binds tighter than Or", func(t *testing.T) {
+		// true OR (true XOR true) = true; left-to-right would give false.
+		ok, _, err := NewExpression(net, &ev).
+			HasChild(CpuStatusChanged, Conditions{}).
+			Or().
+			HasChild(CpuStatusChanged, Conditions{}).
+			Xor().
+			HasChild(CpuStatusChanged, Conditions{}).
+			Eval()
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("binds looser than And", func(t *testing.T) {
+		// true XOR (true AND false) = true; left-to-right would give false.
+		ok, _, err := NewExpression(net, &ev).
+			HasChild(CpuStatusChanged, Conditions{}).
+			Xor().
+			HasChild(CpuStatusChanged, Conditions{}).
+			And().
+			HasChild(MemoryStatusChanged, Conditions{}).
+			Eval()
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("grouped", func(t *testing.T) {
+		// (true OR true) XOR true = false
+		ok, _, err := NewExpression(net, &ev).
+			Group().
+			HasChild(CpuStatusChanged, Conditions{}).
+			Or().
+			HasChild(CpuStatusChanged, Conditions{}).
+			Ungroup().
+			Xor().
+			HasChild(CpuStatusChanged, Conditions{}).
+			Eval()
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+}
+
+func TestExpression_ForAnchor(t *testing.T) {
+	net, parents, _ := buildInfraSubGraph(t)
+	cpuCritical, _ := net.GetByID(parents.CpuCriticalID)
+	memoryCritical, _ := net.GetByID(parents.MemoryCriticalID)
+	nodeStatus, _ := net.GetByID(parents.ServerNodeChangeStatusID)
+
+	expr := NewExpression(net, nil).
+		HasChild(CpuStatusChanged, Conditions{}).
+		Or().
+		HasChild(CpuCritical, Conditions{})
+
+	cases := []struct {
+		name     string
+		anchor   Event
+		expected bool
+		matched  int
+	}{
+		{name: "cpu critical", anchor: cpuCritical, expected: true, matched: 3},
+		{name: "memory critical", anchor: memoryCritical, expected: false, matched: 0},
+		{name: "node status", anchor: nodeStatus, expected: true, matched: 1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ok, matched, err := expr.ForAnchor(&tc.anchor).Eval()
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, ok)
+			require.Len(t, matched, tc.matched)
+		})
+	}
+
+	t.Run("extending a copy does not affect the original", func(t *testing.T) {
+		base := NewExpression(net, nil).HasChild(CpuStatusChanged, Conditions{})
+		bound := base.ForAnchor(&cpuCritical)
+		bound.And().HasChild(MemoryStatusChanged, Conditions{})
+
+		ok, _, err := bound.Eval()
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		ok, _, err = base.ForAnchor(&cpuCritical).Eval()
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+}
+
+func TestExpression_HasPeers_CounterRange(t *testing.T) {
+	cases := []struct {
+		name     string
+		peers    int
+		expected bool
+	}{
+		{name: "below min", peers: 2, expected: false},
+		{name: "at min", peers: 3, expected: true},
+		{name: "in range", peers: 5, expected: true},
+		{name: "at max", peers: 7, expected: true},
+		{name: "above max", peers: 8, expected: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			net := NewInMemoryEventNetwork()
+			for i := 0; i < tc.peers; i++ {
+				_, err := addCpuStatusChangedEvent(net, 95, "critical")
+				require.NoError(t, err)
+			}
+			anchorID, err := addCpuStatusChangedEvent(net, 97.1, "critical")
+			require.NoError(t, err)
+			ev, _ := net.GetByID(anchorID)
+
+			ok, matched, err := NewExpression(net, &ev).
+				HasPeers(CpuStatusChanged, Conditions{
+					// HowMany is ignored once UseRange is set.
+					Counter: &Counter{HowMany: 1, UseRange: true, Min: 3, Max: 7},
+				}).
+				Eval()
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, ok)
+			require.Len(t, matched, tc.peers)
+		})
+	}
+}
+
+func TestExpression_HasPeers_CounterOrFewer(t *testing.T) {
+	cases := []struct {
+		name     string
+		peers    int
+		expected bool
+	}{
+		{name: "zero matches", peers: 0, expected: true},
+		{name: "exactly N", peers: 2, expected: true},
+		{name: "N+1", peers: 3, expected: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			net := NewInMemoryEventNetwork()
+			for i := 0; i < tc.peers; i++ {
+				_, err := addCpuStatusChangedEvent(net, 95, "critical")
+				require.NoError(t, err)
+			}
+			anchorID, err := addCpuStatusChangedEvent(net, 97.1, "critical")
+			require.NoError(t, err)
+			ev, _ := net.GetByID(anchorID)
+
+			ok, _, err := NewExpression(net, &ev).
+				HasPeers(CpuStatusChanged, Conditions{
+					Counter: &Counter{HowMany: 2, HowManyOrFewer: true},
+				}).
+				Eval()
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, ok)
+		})
+	}
+
+	t.Run("conflicting with HowManyOrMore", func(t *testing.T) {
+		net, parents, _ := buildInfraSubGraph(t)
+		ev, _ := net.GetByID(parents.CpuCriticalID)
+
+		_, _, err := NewExpression(net, &ev).
+			HasChild(CpuStatusChanged, Conditions{
+				Counter: &Counter{HowMany: 2, HowManyOrMore: true, HowManyOrFewer: true},
+			}).
+			Eval()
+		require.ErrorContains(t, err, "mutually exclusive")
+	})
+}
+
+func TestExpression_HasChild_ChildOrder(t *testing.T) {
+	const (
+		runStarted   = "run_started"
+		sliceAdded   = "dataset_slice_added"
+		trainingRun  = "training_run"
+		researchArea = "research"
+	)
+	baseTime := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// build creates a training_run derived from children added at the given offsets.
+	build := func(t *testing.T, children map[EventType][]time.Duration) (EventNetwork, Event) {
+		t.Helper()
+		net := NewInMemoryEventNetwork()
+		derivedID, err := net.AddEvent(Event{EventType: trainingRun, EventDomain: researchArea, Timestamp: baseTime.Add(time.Hour)})
+		require.NoError(t, err)
+		for eventType, offsets := range children {
+			for _, offset := range offsets {
+				id, err := net.AddEvent(Event{EventType: eventType, EventDomain: researchArea, Timestamp: baseTime.Add(offset)})
+				require.NoError(t, err)
+				require.NoError(t, net.AddEdge(id, derivedID, "trigger"))
+			}
+		}
+		derived, err := net.GetByID(derivedID)
+		require.NoError(t, err)
+		return net, derived
+	}
+
+	order := Conditions{ChildOrder: []EventType{runStarted, sliceAdded}}
+
+	t.Run("in order passes", func(t *testing.T) {
+		net, anchor := build(t, map[EventType][]time.Duration{
+			runStarted: {0},
+			sliceAdded: {time.Minute, 2 * time.Minute},
+		})
+		ok, matched, err := NewExpression(net, &anchor).HasChild(sliceAdded, order).Eval()
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Len(t, matched, 2)
+	})
+
+	t.Run("out of order fails", func(t *testing.T) {
+		net, anchor := build(t, map[EventType][]time.Duration{
+			runStarted: {2 * time.Minute},
+			sliceAdded: {time.Minute, 3 * time.Minute},
+		})
+		ok, _, err := NewExpression(net, &anchor).HasChild(sliceAdded, order).Eval()
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("missing ordered type fails", func(t *testing.T) {
+		net, anchor := build(t, map[EventType][]time.Duration{
+			sliceAdded: {time.Minute},
+		})
+		ok, _, err := NewExpression(net, &anchor).HasChild(sliceAdded, order).Eval()
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("without ChildOrder order is ignored", func(t *testing.T) {
+		net, anchor := build(t, map[EventType][]time.Duration{
+			runStarted: {2 * time.Minute},
+			sliceAdded: {time.Minute},
+		})
+		ok, _, err := NewExpression(net, &anchor).HasChild(sliceAdded, Conditions{}).Eval()
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+}
+
+func TestExpression_TimeWindowConverter(t *testing.T) {
+	anchorTime := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	net := NewInMemoryEventNetwork()
+	add := func(at time.Time) Event {
+		id, err := net.AddEvent(Event{EventType: CpuStatusChanged, EventDomain: InfraDomain, Timestamp: at})
+		require.NoError(t, err)
+		ev, err := net.GetByID(id)
+		require.NoError(t, err)
+		return ev
+	}
+	add(anchorTime.Add(-29 * 24 * time.Hour))
+	anchor := add(anchorTime)
+
+	eval := func(window *TimeWindow) bool {
+		ok, _, err := NewExpression(net, &anchor).HasPeers(CpuStatusChanged, Conditions{TimeWindow: window}).Eval()
+		require.NoError(t, err)
+		return ok
+	}
+
+	// A peer 29 days back is within one (30-day) month, but not within a 28-day billing cycle
+	require.True(t, eval(&TimeWindow{Within: 1, TimeUnit: Month}))
+	billing := TimeUnitConverter{}.WithDaysPerMonth(28)
+	require.False(t, eval(&TimeWindow{Within: 1, TimeUnit: Month, Converter: &billing}))
+	require.Equal(t, 28*24*time.Hour, TimeWindow{Within: 1, TimeUnit: Month, Converter: &billing}.Duration())
+	require.NotEqual(t,
+		HashConditions(Conditions{TimeWindow: &TimeWindow{Within: 1, TimeUnit: Month}}),
+		HashConditions(Conditions{TimeWindow: &TimeWindow{Within: 1, TimeUnit: Month, Converter: &billing}}),
+		"cached relations must not mix calendars")
+}
+
+func TestExpression_TimeWindowDirection(t *testing.T) {
+	anchorTime := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// Anchor with one peer/sibling an hour before and one an hour after.
+	newNet := func(t *testing.T) (EventNetwork, Event) {
+		t.Helper()
+		net := NewInMemoryEventNetwork()
+		add := func(at time.Time) EventID {
+			id, err := net.AddEvent(Event{EventType: CpuStatusChanged, EventDomain: InfraDomain, Timestamp: at})
+			require.NoError(t, err)
+			return id
+		}
+		add(anchorTime.Add(-time.Hour))
+		anchorID := add(anchorTime)
+		add(anchorTime.Add(time.Hour))
+		anchor, _ := net.GetByID(anchorID)
+		return net, anchor
+	}
+
+	cases := []struct {
+		direction TimeDirection
+		expected  []time.Time
+	}{
+		{direction: Past, expected: []time.Time{anchorTime.Add(-time.Hour)}},
+		{direction: Future, expected: []time.Time{anchorTime.Add(time.Hour)}},
+		{direction: Both, expected: []time.Time{anchorTime.Add(-time.Hour), anchorTime.Add(time.Hour)}},
+	}
+
+	timestamps := func(events []Event) []time.Time {
+		out := make([]time.Time, 0, len(events))
+		for _, ev := range events {
+			out = append(out, ev.Timestamp)
+		}
+		return out
+	}
+
+	for _, tc := range cases {
+		cond := Conditions{TimeWindow: &TimeWindow{Within: 2, TimeUnit: Hour, Direction: tc.direction}}
+
+		t.Run(fmt.Sprintf("peers direction %d", tc.direction), func(t *testing.T) {
+			net, anchor := newNet(t)
+			ok, matched, err := NewExpression(net, &anchor).HasPeers(CpuStatusChanged, cond).Eval()
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.ElementsMatch(t, tc.expected, timestamps(matched))
+		})
+
+		t.Run(fmt.Sprintf("siblings direction %d", tc.direction), func(t *testing.T) {
+			net, anchor := newNet(t)
+			derivedID, err := net.AddEvent(Event{EventType: CpuCritical, EventDomain: InfraDomain, Timestamp: anchorTime})
+			require.NoError(t, err)
+			leaves, err := net.GetByType(CpuStatusChanged)
+			require.NoError(t, err)
+			for _, leaf := range leaves {
+				require.NoError(t, net.AddEdge(leaf.ID, derivedID, "trigger"))
+			}
+
+			cond := cond
+			cond.OfEventType = CpuStatusChanged
+			ok, matched, err := NewExpression(net, &anchor).HasSiblings(CpuStatusChanged, cond).Eval()
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.ElementsMatch(t, tc.expected, timestamps(matched))
+		})
+	}
+}
+
+func TestExpression_PeersScore(t *testing.T) {
+	anchorTime := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	halfLife := TimeWindow{Within: 1, TimeUnit: Hour}
+
+	// newNet adds one peer per age (relative to the anchor) and returns the anchor.
+	newNet := func(t *testing.T, ages ...time.Duration) (EventNetwork, Event) {
+		t.Helper()
+		net := NewInMemoryEventNetwork()
+		for _, age := range ages {
+			_, err := net.AddEvent(Event{EventType: CpuStatusChanged, EventDomain: InfraDomain, Timestamp: anchorTime.Add(-age)})
+			require.NoError(t, err)
+		}
+		anchorID, err := net.AddEvent(Event{EventType: CpuStatusChanged, EventDomain: InfraDomain, Timestamp: anchorTime})
+		require.NoError(t, err)
+		anchor, _ := net.GetByID(anchorID)
+		return net, anchor
+	}
+
+	t.Run("recent cluster passes", func(t *testing.T) {
+		net, anchor := newNet(t, time.Minute, 2*time.Minute, 5*time.Minute, 10*time.Minute)
+		ok, matched, err := NewExpression(net, &anchor).PeersScore(CpuStatusChanged, halfLife, 3, Conditions{}).Eval()
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Len(t, matched, 4)
+	})
+
+	t.Run("same count spread far back fails", func(t *testing.T) {
+		net, anchor := newNet(t, 2*time.Hour, 5*time.Hour, 10*time.Hour, 24*time.Hour)
+		ok, matched, err := NewExpression(net, &anchor).PeersScore(CpuStatusChanged, halfLife, 3, Conditions{}).Eval()
+		require.NoError(t, err)
+		require.False(t, ok)
+		require.Len(t, matched, 4)
+	})
+
+	t.Run("one half-life old peers count half", func(t *testing.T) {
+		net, anchor := newNet(t, time.Hour, time.Hour)
+		// 0.5 + 0.5 == 1: not strictly above threshold
+		ok, _, err := NewExpression(net, &anchor).PeersScore(CpuStatusChanged, halfLife, 1, Conditions{}).Eval()
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		ok, _, err = NewExpression(net, &anchor).PeersScore(CpuStatusChanged, halfLife, 0.99, Conditions{}).Eval()
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("invalid half-life", func(t *testing.T) {
+		net, anchor := newNet(t, time.Minute)
+		_, _, err := NewExpression(net, &anchor).PeersScore(CpuStatusChanged, TimeWindow{}, 0, Conditions{}).Eval()
+		require.Error(t, err)
+	})
+
+	t.Run("compiled from Condition", func(t *testing.T) {
+		net, anchor := newNet(t, time.Minute, 2*time.Minute, 5*time.Minute, 10*time.Minute)
+		expr, err := NewConditionCompiler(net).Compile(
+			NewCondition().PeersScore(CpuStatusChanged, halfLife, 3, Conditions{}), &anchor)
+		require.NoError(t, err)
+		ok, _, err := expr.Eval()
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+}
+
+func TestExpression_EvalCandidates(t *testing.T) {
+	net := NewInMemoryEventNetwork()
+	base := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	add := func(eventType EventType, domain EventDomain, offset time.Duration, status string) Event {
+		ev, err := net.AddEventFull(Event{
+			EventType:   eventType,
+			EventDomain: domain,
+			Timestamp:   base.Add(offset),
+			Properties:  EventProps{"status": status},
+		})
+		require.NoError(t, err)
+		return ev
+	}
+
+	matching := add(CpuStatusChanged, InfraDomain, -40*time.Minute, "critical")
+	wrongStatus := add(CpuStatusChanged, InfraDomain, -30*time.Minute, "ok")
+	tooOld := add(CpuStatusChanged, InfraDomain, -3*time.Hour, "critical")
+	derivedFrom := add(CpuStatusChanged, InfraDomain, -20*time.Minute, "critical")
+	otherType := add(MemoryStatusChanged, InfraDomain, -10*time.Minute, "critical")
+	anchor := add(CpuStatusChanged, InfraDomain, 0, "critical")
+
+	parent := add(CpuCritical, "elsewhere", 0, "critical")
+	require.NoError(t, net.AddEdge(derivedFrom.ID, parent.ID, "trigger"))
+
+	cond := Conditions{
+		TimeWindow:     &TimeWindow{Within: 1, TimeUnit: Hour},
+		PropertyValues: map[string]any{"status": "critical"},
+		Counter:        &Counter{HowMany: 3, HowManyOrMore: true},
+	}
+	expr := NewExpression(net, &anchor)
+
+	ok, _, err := NewExpression(net, &anchor).HasPeers(CpuStatusChanged, cond).Eval()
+	require.NoError(t, err)
+	require.False(t, ok, "only one peer passes every check")
+
+	results, err := expr.EvalCandidates(func(x *EventExpression) *EventExpression {
+		return x.HasPeers(CpuStatusChanged, cond)
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 5)
+
+	byID := make(map[EventID]CandidateResult, len(results))
+	for _, r := range results {
+		byID[r.Event.ID] = r
+	}
+	require.NotContains(t, byID, anchor.ID)
+
+	require.Equal(t, CandidateResult{Event: matching, Type: true, Domain: true, Parentless: true, Window: true, Property: true, Matched: true}, byID[matching.ID])
+	require.Equal(t, CandidateResult{Event: wrongStatus, Type: true, Domain: true, Parentless: true, Window: true, Property: false}, byID[wrongStatus.ID])
+	require.Equal(t, CandidateResult{Event: tooOld, Type: true, Domain: true, Parentless: true, Window: false, Property: true}, byID[tooOld.ID])
+	require.Equal(t, CandidateResult{Event: derivedFrom, Type: true, Domain: true, Parentless: false, Window: true, Property: true}, byID[derivedFrom.ID])
+	require.Equal(t, CandidateResult{Event: otherType, Type: false, Domain: true, Parentless: true, Window: true, Property: true}, byID[otherType.ID])
+
+	// Oldest first
+	require.Equal(t, tooOld.ID, results[0].Event.ID)
+
+	t.Run("rejects non-peer terms", func(t *testing.T) {
+		_, err := expr.EvalCandidates(func(x *EventExpression) *EventExpression {
+			return x.HasChild(CpuStatusChanged, Conditions{})
+		})
+		require.Error(t, err)
+		_, err = expr.EvalCandidates(func(x *EventExpression) *EventExpression { return x })
+		require.Error(t, err)
+	})
+}
+
+func TestExpression_HasChildrenAcrossDomains(t *testing.T) {
+	net := NewInMemoryEventNetwork()
+	now := time.Now()
+
+	cpu, _ := net.AddEvent(Event{EventType: CpuStatusChanged, EventDomain: InfraDomain, Timestamp: now.Add(-2 * time.Minute)})
+	cpu2, _ := net.AddEvent(Event{EventType: CpuStatusChanged, EventDomain: InfraDomain, Timestamp: now.Add(-time.Minute)})
+	tremor, _ := net.AddEvent(Event{EventType: MinorTremors, EventDomain: Geology, Timestamp: now.Add(-3 * time.Hour)})
+	derived, _ := net.AddEventFull(Event{EventType: "cross_domain_signal", EventDomain: "governance", Timestamp: now})
+	for _, id := range []EventID{cpu, cpu2, tremor} {
+		require.NoError(t, net.AddEdge(id, derived.ID, "trigger"))
+	}
+
+	ok, matched, err := NewExpression(net, &derived).HasChildrenAcrossDomains(2, Conditions{}).Eval()
+	require.NoError(t, err)
+	require.True(t, ok, "children come from infra and geology")
+	require.Len(t, matched, 3)
+
+	ok, _, err = NewExpression(net, &derived).HasChildrenAcrossDomains(3, Conditions{}).Eval()
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// The tremor falls outside the window, leaving a single domain
+	ok, matched, err = NewExpression(net, &derived).
+		HasChildrenAcrossDomains(2, Conditions{TimeWindow: &TimeWindow{Within: 1, TimeUnit: Hour}}).
+		Eval()
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Len(t, matched, 2)
+
+	leaf, err := net.GetByID(cpu)
+	require.NoError(t, err)
+	ok, _, err = NewExpression(net, &leaf).HasChildrenAcrossDomains(0, Conditions{}).Eval()
+	require.NoError(t, err)
+	require.False(t, ok, "events without children never match")
+}
+
+func TestExpression_HasExactlyNDescendants(t *testing.T) {
+	// leaf feeds two mid-level events which both feed one top event (a diamond)
+	net := NewInMemoryEventNetwork()
+	now := time.Now()
+
+	leaf, _ := net.AddEventFull(Event{EventType: CpuStatusChanged, EventDomain: InfraDomain, Timestamp: now})
+	midA, _ := net.AddEvent(Event{EventType: CpuCritical, EventDomain: InfraDomain, Timestamp: now.Add(time.Minute)})
+	midB, _ := net.AddEvent(Event{EventType: CpuCritical, EventDomain: InfraDomain, Timestamp: now.Add(time.Minute)})
+	top, _ := net.AddEvent(Event{EventType: ServerNodeChangeStatus, EventDomain: InfraDomain, Timestamp: now.Add(2 * time.Minute)})
+	require.NoError(t, net.AddEdge(leaf.ID, midA, "trigger"))
+	require.NoError(t, net.AddEdge(leaf.ID, midB, "trigger"))
+	require.NoError(t, net.AddEdge(midA, top, "trigger"))
+	require.NoError(t, net.AddEdge(midB, top, "trigger"))
+
+	cases := []struct {
+		name      string
+		eventType string
+		n         int
+		maxDepth  int
+		want      bool
+	}{
+		{"two mid-level", CpuCritical, 2, 1, true},
+		{"one short", CpuCritical, 1, 1, false},
+		{"one over", CpuCritical, 3, 1, false},
+		{"top reached by two paths counts once", ServerNodeChangeStatus, 1, 2, true},
+		{"top is not counted twice", ServerNodeChangeStatus, 2, 2, false},
+		{"top is beyond depth", ServerNodeChangeStatus, 0, 1, true},
+		{"default depth is one", ServerNodeChangeStatus, 1, 0, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ok, matched, err := NewExpression(net, &leaf).
+				HasExactlyNDescendants(tc.eventType, tc.n, tc.maxDepth).
+				Eval()
+			require.NoError(t, err)
+			require.Equal(t, tc.want, ok)
+			if ok {
+				require.Len(t, matched, tc.n)
+			}
+		})
+	}
+
+	spec := NewCondition().HasExactlyNDescendants(ServerNodeChangeStatus, 1, 2)
+	expr, err := NewConditionCompiler(net).Compile(spec, &leaf)
+	require.NoError(t, err)
+	ok, _, err := expr.Eval()
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestExpression_EvalBool(t *testing.T) {
+	net, parents, childs := buildInfraSubGraph(t)
+	cpuCritical, _ := net.GetByID(parents.CpuCriticalID)
+	cpu, _ := net.GetByID(childs.CpuEventsIDs[0])
+
+	cases := map[string]func() *EventExpression{
+		"child": func() *EventExpression {
+			return NewExpression(net, &cpuCritical).HasChild(CpuStatusChanged, Conditions{})
+		},
+		"missing descendant": func() *EventExpression {
+			return NewExpression(net, &cpuCritical).HasDescendants(CpuStatusChanged, Conditions{})
+		},
+		"descendant or not type": func() *EventExpression {
+			return NewExpression(net, &cpu).HasDescendants(CpuCritical, Conditions{}).Or().Not().IsTypeOf(CpuStatusChanged, Conditions{})
+		},
+		"peers and domain": func() *EventExpression {
+			return NewExpression(net, &cpu).HasPeers(CpuStatusChanged, Conditions{}).And().InDomain(InfraDomain)
+		},
+	}
+	for name, build := range cases {
+		t.Run(name, func(t *testing.T) {
+			want, _, wantErr := build().Eval()
+			got, err := build().EvalBool()
+			require.Equal(t, wantErr, err)
+			require.Equal(t, want, got)
+		})
+	}
+
+	_, err := NewExpression(net, &cpu).EvalBool()
+	require.Error(t, err, "empty expression")
+
+	// The right-hand terms can't change the result, so they are not evaluated
+	tooDeep := Conditions{MaxDepth: DefaultMaxTraversalDepth + 1}
+	_, _, err = NewExpression(net, &cpu).InDomain("other").And().HasDescendants(CpuCritical, tooDeep).Eval()
+	require.ErrorIs(t, err, ErrMaxDepthExceeded)
+
+	ok, err := NewExpression(net, &cpu).InDomain("other").And().HasDescendants(CpuCritical, tooDeep).EvalBool()
+	require.NoError(t, err)
+	require.False(t, ok)
+	ok, err = NewExpression(net, &cpu).InDomain(InfraDomain).Or().HasDescendants(CpuCritical, tooDeep).EvalBool()
+	require.NoError(t, err)
+	require.True(t, ok)
+	_, err = NewExpression(net, &cpu).InDomain(InfraDomain).And().HasDescendants(CpuCritical, tooDeep).EvalBool()
+	require.ErrorIs(t, err, ErrMaxDepthExceeded)
+}
+
+func BenchmarkEvalBoolVsEval(b *testing.B) {
+	net, anchor := buildCrossTypePeersNetwork(b, 50_000)
+
+	b.Run("Eval", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _, _ = NewExpression(net, &anchor).HasPeers(MemoryStatusChanged, Conditions{}).Eval()
+		}
+	})
+
+	b.Run("EvalBool", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = NewExpression(net, &anchor).HasPeers(MemoryStatusChanged, Conditions{}).EvalBool()
+		}
+	})
+}
+
+func TestExpression_HasSiblingDerivedType(t *testing.T) {
+	net := NewInMemoryEventNetwork()
+	now := time.Now()
+
+	input, _ := net.AddEventFull(Event{EventType: CpuStatusChanged, EventDomain: InfraDomain, Timestamp: now.Add(-3 * time.Minute)})
+	otherCpu, _ := net.AddEvent(Event{EventType: CpuStatusChanged, EventDomain: InfraDomain, Timestamp: now.Add(-2 * time.Minute)})
+	memory, _ := net.AddEvent(Event{EventType: MemoryStatusChanged, EventDomain: InfraDomain, Timestamp: now.Add(-time.Minute)})
+	cpuCritical, _ := net.AddEvent(Event{EventType: CpuCritical, EventDomain: InfraDomain, Timestamp: now})
+	memoryCritical, _ := net.AddEvent(Event{EventType: MemoryCritical, EventDomain: InfraDomain, Timestamp: now})
+	solo, _ := net.AddEvent(Event{EventType: ServerNodeChangeStatus, EventDomain: InfraDomain, Timestamp: now})
+	for _, edge := range [][2]EventID{
+		{input.ID, cpuCritical}, {otherCpu, cpuCritical},
+		{input.ID, memoryCritical}, {memory, memoryCritical},
+		{input.ID, solo},
+	} {
+		require.NoError(t, net.AddEdge(edge[0], edge[1], "trigger"))
+	}
+
+	for derivedType, want := range map[EventType]EventID{CpuCritical: cpuCritical, MemoryCritical: memoryCritical} {
+		ok, matched, err := NewExpression(net, &input).HasSiblingDerivedType(derivedType, Conditions{}).Eval()
+		require.NoError(t, err)
+		require.True(t, ok, derivedType)
+		require.Equal(t, []EventID{want}, collectIDs(matched))
+	}
+
+	ok, _, err := NewExpression(net, &input).HasSiblingDerivedType(ServerNodeChangeStatus, Conditions{}).Eval()
+	require.NoError(t, err)
+	require.False(t, ok, "the input alone fed the server status")
+
+	ok, _, err = NewExpression(net, &input).HasSiblingDerivedType(CpuCritical, Conditions{
+		PropertyValues: map[string]any{"level": "critical"},
+	}).Eval()
+	require.NoError(t, err)
+	require.False(t, ok, "conditions apply to the derived parent")
+
+	spec := NewCondition().HasSiblingDerivedType(MemoryCritical, Conditions{})
+	expr, err := NewConditionCompiler(net).Compile(spec, &input)
+	require.NoError(t, err)
+	ok, _, err = expr.Eval()
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestExpression_MaxTraversalDepth(t *testing.T) {
+	// level_0 -> ... -> level_69, the top being a composition-derived event
+	net, ids := buildLinearChain(t, 70)
+	top, err := net.GetByID(ids[69])
+	require.NoError(t, err)
+	top.Properties = EventProps{CompositionIDProperty: "deep"}
+	require.NoError(t, net.UpdateEvent(top))
+	leaf, err := net.GetByID(ids[0])
+	require.NoError(t, err)
+
+	t.Run("unlimited default is clamped", func(t *testing.T) {
+		expr := NewExpression(net, &leaf).HasCompositionAncestor("deep", Conditions{})
+		require.Equal(t, DefaultMaxTraversalDepth, expr.MaxTraversalDepth)
+		ok, _, err := expr.Eval()
+		require.NoError(t, err)
+		require.False(t, ok, "69 hops are beyond the default limit")
+
+		expr = NewExpression(net, &leaf).HasCompositionAncestor("deep", Conditions{})
+		expr.MaxTraversalDepth = 100
+		ok, _, err = expr.Eval()
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("explicit depth beyond the limit fails", func(t *testing.T) {
+		_, _, err := NewExpression(net, &leaf).
+			HasDescendants("level_3", Conditions{MaxDepth: DefaultMaxTraversalDepth + 1}).
+			Eval()
+		require.ErrorIs(t, err, ErrMaxDepthExceeded)
+
+		expr := NewExpression(net, &leaf).HasCousin("level_1", Conditions{MaxDepth: 5})
+		expr.MaxTraversalDepth = 4
+		_, _, err = expr.Eval()
+		require.ErrorIs(t, err, ErrMaxDepthExceeded)
+
+		_, _, err = NewExpression(net, &leaf).HasExactlyNDescendants("level_3", 1, 1000).Eval()
+		require.ErrorIs(t, err, ErrMaxDepthExceeded)
+	})
+
+	t.Run("depth within the limit", func(t *testing.T) {
+		ok, _, err := NewExpression(net, &leaf).
+			HasDescendants("level_64", Conditions{MaxDepth: DefaultMaxTraversalDepth}).
+			Eval()
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+}
+
+func TestExpression_RequireTimestamp(t *testing.T) {
+	// Zero timestamps can only enter through a loaded network; AddEvent defaults them.
+	now := time.Date(2026, 4, 25, 5, 0, 0, 0, time.UTC)
+	untimed := Event{ID: uuid.New(), EventType: CpuStatusChanged, EventDomain: InfraDomain}
+	untimedPeer := Event{ID: uuid.New(), EventType: CpuStatusChanged, EventDomain: InfraDomain}
+	timed := Event{ID: uuid.New(), EventType: CpuStatusChanged, EventDomain: InfraDomain, Timestamp: now}
+	recent := Event{ID: uuid.New(), EventType: CpuStatusChanged, EventDomain: InfraDomain, Timestamp: now.Add(-10 * time.Minute)}
+
+	payload, err := json.Marshal(networkJSON{Events: []Event{untimed, untimedPeer, timed, recent}})
+	require.NoError(t, err)
+	net, err := LoadNetworkJSON(bytes.NewReader(payload))
+	require.NoError(t, err)
+
+	hasPeers := func(anchor Event, cond Conditions) bool {
+		ok, _, err := NewExpression(net, &anchor).HasPeers(CpuStatusChanged, cond).Eval()
+		require.NoError(t, err)
+		return ok
+	}
+	hour := &TimeWindow{Within: 1, TimeUnit: Hour}
+
+	t.Run("zero-timestamp peer is excluded from a windowed match", func(t *testing.T) {
+		require.True(t, hasPeers(untimed, Conditions{TimeWindow: hour}), "zero anchor and zero peer share the same instant")
+		require.False(t, hasPeers(untimed, Conditions{TimeWindow: hour, RequireTimestamp: true}))
+	})
+
+	t.Run("zero-timestamp peer is excluded from a windowed count", func(t *testing.T) {
+		wide := &TimeWindow{Within: 1, TimeUnit: Hour, Direction: Both}
+		counter := &Counter{HowMany: 1}
+		require.True(t, hasPeers(timed, Conditions{TimeWindow: wide, Counter: counter, RequireTimestamp: true}))
+		require.False(t, hasPeers(timed, Conditions{Counter: counter}), "untimed peers count without a window")
+		require.True(t, hasPeers(timed, Conditions{Counter: counter, RequireTimestamp: true}))
+	})
+}
+
+func TestExpression_HasPeersWhere(t *testing.T) {
+	net := NewInMemoryEventNetwork()
+	for _, peer := range []struct {
+		percentage float64
+		level      string
+	}{{90, "critical"}, {95, "warning"}, {80, "critical"}, {99, "critical"}} {
+		_, err := addCpuStatusChangedEvent(net, peer.percentage, peer.level)
+		require.NoError(t, err)
+	}
+	anchorID, err := addCpuStatusChangedEvent(net, 100, "critical")
+	require.NoError(t, err)
+	anchor, _ := net.GetByID(anchorID)
+
+	// same level, and clearly below the anchor's load
+	calmerSameLevel := func(anchor, peer Event) bool {
+		return peer.Properties["level"] == anchor.Properties["level"] &&
+			peer.Properties["percentage"].(float64) < anchor.Properties["percentage"].(float64)-5
+	}
+
+	t.Run("counts only peers accepted by the predicate", func(t *testing.T) {
+		ok, matched, err := NewExpression(net, &anchor).
+			HasPeersWhere(CpuStatusChanged, calmerSameLevel, Conditions{Counter: &Counter{HowMany: 2}}).
+			Eval()
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Len(t, matched, 2)
+		for _, ev := range matched {
+			require.Equal(t, "critical", ev.Properties["level"])
+		}
+
+		ok, _, err = NewExpression(net, &anchor).
+			HasPeersWhere(CpuStatusChanged, calmerSameLevel, Conditions{Counter: &Counter{HowMany: 3, HowManyOrMore: true}}).
+			Eval()
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("built-in filters apply alongside the predicate", func(t *testing.T) {
+		ok, matched, err := NewExpression(net, &anchor).
+			HasPeersWhere(CpuStatusChanged, calmerSameLevel, Conditions{PropertyValues: map[string]any{"percentage": 80.0}}).
+			Eval()
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Len(t, matched, 1)
+	})
+
+	t.Run("nil predicate matches nothing", func(t *testing.T) {
+		ok, _, err := NewExpression(net, &anchor).HasPeersWhere(CpuStatusChanged, nil, Conditions{}).Eval()
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("compiled from a Condition", func(t *testing.T) {
+		expr, err := NewConditionCompiler(net).Compile(
+			NewCondition().HasPeersWhere(CpuStatusChanged, calmerSameLevel, Conditions{Counter: &Counter{HowMany: 2}}),
+			&anchor,
+		)
+		require.NoError(t, err)
+		ok, _, err := expr.Eval()
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+}
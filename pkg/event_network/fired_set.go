@@ -0,0 +1,78 @@
+package event_network
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// FiredSet remembers which combinations of contributing matches already derived a
+// composition, so PatternCompositionWatcher derives each combination at most once.
+// Persisting it (see InMemoryFiredSet.MarshalJSON and LoadFiredSetJSON) keeps that
+// guarantee across restarts. Implementations must be safe for concurrent use.
+type FiredSet interface {
+	Contains(key string) bool
+	Add(key string)
+}
+
+// InMemoryFiredSet is a map-backed FiredSet.
+type InMemoryFiredSet struct {
+	mu   sync.RWMutex
+	keys map[string]struct{}
+}
+
+func NewInMemoryFiredSet() *InMemoryFiredSet {
+	return &InMemoryFiredSet{keys: make(map[string]struct{})}
+}
+
+func (s *InMemoryFiredSet) Contains(key string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.keys[key]
+	return ok
+}
+
+func (s *InMemoryFiredSet) Add(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[key] = struct{}{}
+}
+
+// MarshalJSON writes the keys as a sorted array.
+func (s *InMemoryFiredSet) MarshalJSON() ([]byte, error) {
+	s.mu.RLock()
+	keys := make([]string, 0, len(s.keys))
+	for k := range s.keys {
+		keys = append(keys, k)
+	}
+	s.mu.RUnlock()
+
+	sort.Strings(keys)
+	return json.Marshal(keys)
+}
+
+// LoadFiredSetJSON rebuilds a set written by InMemoryFiredSet.MarshalJSON.
+func LoadFiredSetJSON(r io.Reader) (*InMemoryFiredSet, error) {
+	var keys []string
+	if err := json.NewDecoder(r).Decode(&keys); err != nil {
+		return nil, err
+	}
+
+	s := NewInMemoryFiredSet()
+	for _, k := range keys {
+		s.keys[k] = struct{}{}
+	}
+	return s, nil
+}
+
+// firedKey identifies a composition by its ID and the sorted DerivedIDs of its matches.
+func firedKey(compositionID string, patterns []PatternMatch) string {
+	ids := make([]string, 0, len(patterns))
+	for _, p := range patterns {
+		ids = append(ids, p.DerivedID.String())
+	}
+	sort.Strings(ids)
+	return compositionID + ":" + strings.Join(ids, ",")
+}
@@ -1,14 +1,29 @@
 package event_network
 
 import (
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
+	"sort"
+	"sync"
 	"time"
 )
 
+// InMemoryEventNetwork is a map-backed EventNetwork.
+// It is safe for concurrent use: all reads and writes are guarded by mu.
+// Listing queries (GetByType, Children, Peers, Cousins, ...) return events ordered by
+// timestamp then ID, so the same graph always yields the same slices. Ancestors and
+// Descendants keep their breadth-first (nearest level first) order.
 type InMemoryEventNetwork struct {
-	events       map[EventID]Event
-	eventsByType map[EventType][]Event
+	mu sync.RWMutex
+
+	// StrictDAG rejects edges that would close a cycle (see ErrCycle).
+	// Enabled by NewInMemoryEventNetwork; each check walks the derivations above the edge target.
+	StrictDAG bool
+
+	events         map[EventID]Event
+	eventsByType   map[EventType][]Event
+	eventsByDomain map[EventDomain][]Event
 
 	// adjacency lists
 	out map[EventID][]Edge
@@ -17,14 +32,57 @@ type InMemoryEventNetwork struct {
 
 func NewInMemoryEventNetwork() *InMemoryEventNetwork {
 	return &InMemoryEventNetwork{
-		events:       make(map[EventID]Event),
-		eventsByType: make(map[EventType][]Event),
-		out:          make(map[EventID][]Edge),
-		in:           make(map[EventID][]Edge),
+		events:         make(map[EventID]Event),
+		eventsByType:   make(map[EventType][]Event),
+		eventsByDomain: make(map[EventDomain][]Event),
+		out:            make(map[EventID][]Edge),
+		in:             make(map[EventID][]Edge),
+		StrictDAG:      true,
 	}
 }
 
+// Snapshot returns an independent copy of the network's events and edges, e.g. to
+// DiffNetworks against later. Event property maps are shared, not copied.
+func (n *InMemoryEventNetwork) Snapshot() *InMemoryEventNetwork {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	snap := NewInMemoryEventNetwork()
+	snap.StrictDAG = n.StrictDAG
+	for id, ev := range n.events {
+		snap.events[id] = ev
+	}
+	for t, events := range n.eventsByType {
+		snap.eventsByType[t] = append([]Event(nil), events...)
+	}
+	for d, events := range n.eventsByDomain {
+		snap.eventsByDomain[d] = append([]Event(nil), events...)
+	}
+	for id, edges := range n.out {
+		snap.out[id] = append([]Edge(nil), edges...)
+	}
+	for id, edges := range n.in {
+		snap.in[id] = append([]Edge(nil), edges...)
+	}
+	return snap
+}
+
+// ErrCycle is returned by AddEdge when StrictDAG is set and the edge would create a cycle.
+var ErrCycle = errors.New("edge would create a cycle")
+
 func (n *InMemoryEventNetwork) AddEvent(event Event) (EventID, error) {
+	stored, err := n.AddEventFull(event)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	return stored.ID, nil
+}
+
+// AddEventFull stores the event and returns it exactly as stored,
+// i.e. with the generated ID and the (possibly defaulted) timestamp.
+func (n *InMemoryEventNetwork) AddEventFull(event Event) (Event, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
 
 	event.ID = uuid.New()
 
@@ -34,11 +92,37 @@ func (n *InMemoryEventNetwork) AddEvent(event Event) (EventID, error) {
 
 	n.events[event.ID] = event
 	n.eventsByType[event.EventType] = append(n.eventsByType[event.EventType], event)
+	n.eventsByDomain[event.EventDomain] = append(n.eventsByDomain[event.EventDomain], event)
+
+	return event, nil
+}
+
+// UpdateEvent implements EventUpdater.
+func (n *InMemoryEventNetwork) UpdateEvent(event Event) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	stored, ok := n.events[event.ID]
+	if !ok {
+		return fmt.Errorf("event not found: %s", event.ID)
+	}
+	if event.EventType != stored.EventType || event.EventDomain != stored.EventDomain {
+		return fmt.Errorf("event %s: type and domain cannot change", event.ID)
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = stored.Timestamp
+	}
 
-	return event.ID, nil
+	n.events[event.ID] = event
+	replaceEvent(n.eventsByType[event.EventType], event)
+	replaceEvent(n.eventsByDomain[event.EventDomain], event)
+	return nil
 }
 
 func (n *InMemoryEventNetwork) AddEdge(from EventID, to EventID, relation string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
 	if _, ok := n.events[from]; !ok {
 		return fmt.Errorf("from event not found: %s", from)
 	}
@@ -52,11 +136,99 @@ func (n *InMemoryEventNetwork) AddEdge(from EventID, to EventID, relation string
 		Relation: relation,
 	}
 
+	// Identical edges are stored once, so re-firing rules can't inflate
+	// Children/Parents counts or motif contributor lists.
+	for _, existing := range n.out[from] {
+		if existing == edge {
+			return nil
+		}
+	}
+
+	if n.StrictDAG && n.reachableLocked(to, from) {
+		return fmt.Errorf("%w: %s -> %s closes a derivation path back to %s", ErrCycle, from, to, from)
+	}
+
 	n.out[from] = append(n.out[from], edge)
 	n.in[to] = append(n.in[to], edge)
 	return nil
 }
 
+// reachableLocked reports whether target is start itself or derived (transitively) from it.
+func (n *InMemoryEventNetwork) reachableLocked(start, target EventID) bool {
+	seen := map[EventID]bool{start: true}
+	stack := []EventID{start}
+	for len(stack) > 0 {
+		cur := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if cur == target {
+			return true
+		}
+		for _, e := range n.out[cur] {
+			if !seen[e.To] {
+				seen[e.To] = true
+				stack = append(stack, e.To)
+			}
+		}
+	}
+	return false
+}
+
+func (n *InMemoryEventNetwork) RemoveEvent(id EventID) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	event, ok := n.events[id]
+	if !ok {
+		return fmt.Errorf("event not found: %s", id)
+	}
+
+	delete(n.events, id)
+	n.eventsByType[event.EventType] = withoutEvent(n.eventsByType[event.EventType], id)
+	n.eventsByDomain[event.EventDomain] = withoutEvent(n.eventsByDomain[event.EventDomain], id)
+
+	// contributor -> id
+	for _, edge := range n.in[id] {
+		n.out[edge.From] = withoutEdgesTouching(n.out[edge.From], id)
+	}
+	// id -> derived
+	for _, edge := range n.out[id] {
+		n.in[edge.To] = withoutEdgesTouching(n.in[edge.To], id)
+	}
+	delete(n.in, id)
+	delete(n.out, id)
+
+	return nil
+}
+
+func replaceEvent(events []Event, event Event) {
+	for i := range events {
+		if events[i].ID == event.ID {
+			events[i] = event
+			return
+		}
+	}
+}
+
+func withoutEvent(events []Event, id EventID) []Event {
+	out := make([]Event, 0, len(events))
+	for _, ev := range events {
+		if ev.ID != id {
+			out = append(out, ev)
+		}
+	}
+	return out
+}
+
+func withoutEdgesTouching(edges []Edge, id EventID) []Edge {
+	out := make([]Edge, 0, len(edges))
+	for _, e := range edges {
+		if e.From != id && e.To != id {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
 func (n *InMemoryEventNetwork) getEvent(id EventID) (Event, error) {
 	e, ok := n.events[id]
 	if !ok {
@@ -66,21 +238,29 @@ func (n *InMemoryEventNetwork) getEvent(id EventID) (Event, error) {
 }
 
 func (n *InMemoryEventNetwork) Children(of EventID) ([]Event, error) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
 	if _, ok := n.events[of]; !ok {
 		return nil, fmt.Errorf("event not found: %s", of)
 	}
 
+	// in[of] holds edges contributor -> of; the child is the contributor (From).
 	edges := n.in[of]
 
 	result := make([]Event, 0, len(edges))
 	for _, e := range edges {
-		ev, _ := n.events[e.To]
+		ev, _ := n.events[e.From]
 		result = append(result, ev)
 	}
+	sortByTimestamp(result)
 	return result, nil
 }
 
 func (n *InMemoryEventNetwork) Parents(of EventID) ([]Event, error) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
 	if _, ok := n.events[of]; !ok {
 		return nil, fmt.Errorf("event not found: %s", of)
 	}
@@ -92,9 +272,30 @@ func (n *InMemoryEventNetwork) Parents(of EventID) ([]Event, error) {
 		ev, _ := n.events[e.To]
 		result = append(result, ev)
 	}
+	sortByTimestamp(result)
 	return result, nil
 }
 
+func (n *InMemoryEventNetwork) OutEdges(of EventID) ([]Edge, error) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	if _, ok := n.events[of]; !ok {
+		return nil, fmt.Errorf("event not found: %s", of)
+	}
+	return append([]Edge(nil), n.out[of]...), nil
+}
+
+func (n *InMemoryEventNetwork) InEdges(of EventID) ([]Edge, error) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	if _, ok := n.events[of]; !ok {
+		return nil, fmt.Errorf("event not found: %s", of)
+	}
+	return append([]Edge(nil), n.in[of]...), nil
+}
+
 // Peers returns same-type, parentless events.
 //
 // Semantic meaning (bottom-up derivation):
@@ -108,6 +309,9 @@ func (n *InMemoryEventNetwork) Parents(of EventID) ([]Event, error) {
 //   - Peers do NOT require a shared parent — they exist precisely for the case where
 //     no parent exists (disconnected but same-level, same-type contextual grouping).
 func (n *InMemoryEventNetwork) Peers(of EventID) ([]Event, error) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
 	anchor, ok := n.events[of]
 	if !ok {
 		return nil, fmt.Errorf("event not found: %s", of)
@@ -142,6 +346,7 @@ func (n *InMemoryEventNetwork) Peers(of EventID) ([]Event, error) {
 		result = append(result, candidate)
 	}
 
+	sortByTimestamp(result)
 	return result, nil
 }
 
@@ -160,6 +365,9 @@ func (n *InMemoryEventNetwork) Peers(of EventID) ([]Event, error) {
 //   - No duplicates (visited set).
 //   - Safe even if the DAG assumption is violated (visited prevents infinite loops).
 func (n *InMemoryEventNetwork) Ancestors(of EventID, maxDepth int) ([]Event, error) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
 	if _, ok := n.events[of]; !ok {
 		return nil, fmt.Errorf("event not found: %s", of)
 	}
@@ -213,31 +421,52 @@ func (n *InMemoryEventNetwork) Ancestors(of EventID, maxDepth int) ([]Event, err
 }
 
 func (n *InMemoryEventNetwork) Descendants(of EventID, maxDepth int) ([]Event, error) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	if _, ok := n.events[of]; !ok {
+		return nil, fmt.Errorf("event not found: %s", of)
+	}
 	if maxDepth <= 0 {
 		return nil, nil
 	}
 
-	visited := make(map[EventID]bool)
+	type item struct {
+		id    EventID
+		depth int
+	}
+
+	visited := map[EventID]bool{of: true}
+	queue := []item{{id: of, depth: 0}}
+
 	var result []Event
 
-	var dfs func(EventID, int)
-	dfs = func(id EventID, depth int) {
-		if depth > maxDepth || visited[id] {
-			return
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+
+		if cur.depth >= maxDepth {
+			continue
 		}
-		visited[id] = true
-		for _, edge := range n.in[id] {
-			ev := n.events[edge.To]
-			result = append(result, ev)
-			dfs(edge.From, depth+1)
+
+		// Contributors are stored on inbound edges: contributorID -> cur.id.
+		for _, edge := range n.in[cur.id] {
+			if visited[edge.From] {
+				continue
+			}
+			visited[edge.From] = true
+			result = append(result, n.events[edge.From])
+			queue = append(queue, item{id: edge.From, depth: cur.depth + 1})
 		}
 	}
 
-	dfs(of, 1)
 	return result, nil
 }
 
 func (n *InMemoryEventNetwork) Cousins(of EventID, maxDepth int) ([]Event, error) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
 	if _, ok := n.events[of]; !ok {
 		return nil, fmt.Errorf("event not found: %s", of)
 	}
@@ -273,10 +502,14 @@ func (n *InMemoryEventNetwork) Cousins(of EventID, maxDepth int) ([]Event, error
 		}
 	}
 
+	sortByTimestamp(result)
 	return result, nil
 }
 
 func (n *InMemoryEventNetwork) Siblings(of EventID) ([]Event, error) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
 	// Siblings = events that share at least one common derived parent with `of`.
 	//
 	// Semantic meaning (bottom-up derivation):
@@ -306,18 +539,23 @@ func (n *InMemoryEventNetwork) Siblings(of EventID) ([]Event, error) {
 				}
 			}
 		}
-		return result, nil
 	}
 
+	sortByTimestamp(result)
 	return result, nil
-
 }
 
 func (n *InMemoryEventNetwork) GetByID(id EventID) (Event, error) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
 	return n.getEvent(id)
 }
 
 func (n *InMemoryEventNetwork) GetByIDs(ids []EventID) ([]Event, error) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
 	result := make([]Event, 0, len(ids))
 	for _, id := range ids {
 		ev, err := n.getEvent(id)
@@ -330,15 +568,191 @@ func (n *InMemoryEventNetwork) GetByIDs(ids []EventID) ([]Event, error) {
 }
 
 func (n *InMemoryEventNetwork) GetByType(eventType EventType) ([]Event, error) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
 	var result []Event
 	for _, ev := range n.events {
 		if ev.EventType == eventType {
 			result = append(result, ev)
 		}
 	}
+	sortByTimestamp(result)
+	return result, nil
+}
+
+// GetByDomain returns all events of a given domain.
+func (n *InMemoryEventNetwork) GetByDomain(domain EventDomain) ([]Event, error) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	in := n.eventsByDomain[domain]
+	result := make([]Event, 0, len(in))
+	result = append(result, in...)
+	sortByTimestamp(result)
+	return result, nil
+}
+
+// GetByTimeRange returns all events whose Timestamp falls in [from, to], oldest first.
+// It scans every event.
+func (n *InMemoryEventNetwork) GetByTimeRange(from, to time.Time) ([]Event, error) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	result := make([]Event, 0)
+	for _, ev := range n.events {
+		if !ev.Timestamp.Before(from) && !ev.Timestamp.After(to) {
+			result = append(result, ev)
+		}
+	}
+	sortByTimestamp(result)
+	return result, nil
+}
+
+// AllEvents returns every event, oldest first.
+func (n *InMemoryEventNetwork) AllEvents() ([]Event, error) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	result := make([]Event, 0, len(n.events))
+	for _, ev := range n.events {
+		result = append(result, ev)
+	}
+	sortByTimestamp(result)
+	return result, nil
+}
+
+// ParentlessByType returns events of eventType that have no derived parents.
+// It reads the adjacency lists directly, so it costs one pass over the type cohort.
+func (n *InMemoryEventNetwork) ParentlessByType(eventType EventType) ([]Event, error) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	result := make([]Event, 0)
+	for _, ev := range n.eventsByType[eventType] {
+		if len(n.out[ev.ID]) == 0 {
+			result = append(result, ev)
+		}
+	}
+	sortByTimestamp(result)
 	return result, nil
 }
 
+// RelationCounts returns how many edges exist per relation label
+// (e.g. "trigger", "pattern_composition").
+//
+// Useful for observability: it shows the balance between rule-driven
+// and composition-driven derivations in a live graph.
+func (n *InMemoryEventNetwork) RelationCounts() map[string]int {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for _, edges := range n.out {
+		for _, e := range edges {
+			counts[e.Relation]++
+		}
+	}
+	return counts
+}
+
+// Stats summarizes the network size (events and edges, by type, domain and relation),
+// e.g. to monitor memory growth over long runs.
+func (n *InMemoryEventNetwork) Stats() NetworkStats {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	stats := NetworkStats{
+		Events:          len(n.events),
+		EventsByType:    make(map[EventType]int),
+		EventsByDomain:  make(map[EventDomain]int),
+		EdgesByRelation: make(map[string]int),
+	}
+	for _, ev := range n.events {
+		stats.EventsByType[ev.EventType]++
+		stats.EventsByDomain[ev.EventDomain]++
+	}
+	for _, edges := range n.out {
+		for _, e := range edges {
+			stats.Edges++
+			stats.EdgesByRelation[e.Relation]++
+		}
+	}
+	return stats
+}
+
+// AllEdges returns every edge once, grouped by From event (oldest first) in insertion order.
+func (n *InMemoryEventNetwork) AllEdges() []Edge {
+	edges := make([]Edge, 0)
+	n.ForEachEdge(func(e Edge) bool {
+		edges = append(edges, e)
+		return true
+	})
+	return edges
+}
+
+// ForEachEdge streams every edge once, in AllEdges order, until fn returns false.
+// The network is read-locked meanwhile: fn must not modify it.
+func (n *InMemoryEventNetwork) ForEachEdge(fn func(Edge) bool) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	from := make([]Event, 0, len(n.out))
+	for id, edges := range n.out {
+		if len(edges) > 0 {
+			from = append(from, n.events[id])
+		}
+	}
+	sortByTimestamp(from)
+
+	for _, ev := range from {
+		for _, e := range n.out[ev.ID] {
+			if !fn(e) {
+				return
+			}
+		}
+	}
+}
+
+// Leaves returns events without contributors (raw observations), oldest first.
+// Together with Roots they are the usual starting points for traversal and export.
+func (n *InMemoryEventNetwork) Leaves() []Event {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	return n.eventsWithout(n.in)
+}
+
+// Roots returns events no other event was derived from (top of derivation), oldest first.
+func (n *InMemoryEventNetwork) Roots() []Event {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	return n.eventsWithout(n.out)
+}
+
+// eventsWithout returns events with no edges in adjacency, ordered by timestamp (then ID).
+func (n *InMemoryEventNetwork) eventsWithout(adjacency map[EventID][]Edge) []Event {
+	result := make([]Event, 0)
+	for id, ev := range n.events {
+		if len(adjacency[id]) == 0 {
+			result = append(result, ev)
+		}
+	}
+	sortByTimestamp(result)
+	return result
+}
+
+// sortByTimestamp orders events oldest first, ties by ID, so listings are stable across runs.
+func sortByTimestamp(events []Event) {
+	sort.Slice(events, func(i, j int) bool {
+		if !events[i].Timestamp.Equal(events[j].Timestamp) {
+			return events[i].Timestamp.Before(events[j].Timestamp)
+		}
+		return events[i].ID.String() < events[j].ID.String()
+	})
+}
+
 func (n *InMemoryEventNetwork) parents(of EventID) []EventID {
 	var result []EventID
 	for _, e := range n.in[of] {
@@ -0,0 +1,62 @@
+package event_network
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// networkJSON is the persisted form of an InMemoryEventNetwork.
+type networkJSON struct {
+	Events []Event `json:"events"`
+	Edges  []Edge  `json:"edges"`
+}
+
+// MarshalJSON writes every event and edge, events ordered by timestamp (then ID)
+// and edges by their From event in the same order, so output is stable across runs.
+func (n *InMemoryEventNetwork) MarshalJSON() ([]byte, error) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	doc := networkJSON{
+		Events: make([]Event, 0, len(n.events)),
+		Edges:  make([]Edge, 0),
+	}
+	for _, ev := range n.events {
+		doc.Events = append(doc.Events, ev)
+	}
+	sortByTimestamp(doc.Events)
+	for _, ev := range doc.Events {
+		doc.Edges = append(doc.Edges, n.out[ev.ID]...)
+	}
+
+	return json.Marshal(doc)
+}
+
+// LoadNetworkJSON rebuilds a network written by InMemoryEventNetwork.MarshalJSON.
+// Event IDs and timestamps are kept as stored; edges rebuild the in/out adjacency.
+// Numeric properties come back as float64, as with any JSON decoding.
+// Duplicate event IDs and edges referencing unknown events are errors.
+func LoadNetworkJSON(r io.Reader) (*InMemoryEventNetwork, error) {
+	var doc networkJSON
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	n := NewInMemoryEventNetwork()
+	for _, ev := range doc.Events {
+		if _, dup := n.events[ev.ID]; dup {
+			return nil, fmt.Errorf("duplicate event: %s", ev.ID)
+		}
+		n.events[ev.ID] = ev
+		n.eventsByType[ev.EventType] = append(n.eventsByType[ev.EventType], ev)
+		n.eventsByDomain[ev.EventDomain] = append(n.eventsByDomain[ev.EventDomain], ev)
+	}
+	for _, edge := range doc.Edges {
+		if err := n.AddEdge(edge.From, edge.To, edge.Relation); err != nil {
+			return nil, err
+		}
+	}
+
+	return n, nil
+}
@@ -1,6 +1,8 @@
 package event_network
 
 import (
+	"math"
+	"sort"
 	"sync"
 	"time"
 )
@@ -14,7 +16,7 @@ import (
 type MotifKey struct {
 	DerivedType    EventType
 	DerivedDomain  EventDomain
-	ContributorSig string // normalized types list for POC
+	ContributorSig string // sorted "type@domain" contributor tokens joined by "|"
 	RuleID         string
 }
 
@@ -29,18 +31,42 @@ type MotifStats struct {
 	Count     int
 	LastSeen  time.Time
 	Instances []MotifInstance
+
+	// DecayedScore is an exponentially decaying occurrence counter, valid as of LastSeen.
+	// Each occurrence adds 1; older weight halves every trend half-life.
+	DecayedScore float64
+}
+
+// MotifKeyScore is a motif ranked by its decayed (recency-weighted) score.
+type MotifKeyScore struct {
+	Key      MotifKey
+	Score    float64
+	Count    int
+	LastSeen time.Time
 }
 
+// MotifTrendTracker is an optional StructuralMemory extension for "what's trending" views.
+// Unlike raw motif counts, recent occurrences weigh more than old ones.
+type MotifTrendTracker interface {
+	// TrendingMotifs returns up to k motifs with the highest decayed score, best first.
+	TrendingMotifs(k int) []MotifKeyScore
+}
+
+const defaultTrendHalfLife = time.Hour
+
+// BuildMotifKey identifies a derivation by its derived event and contributors.
+// Contributors count by type and domain, so same-named types from different
+// domains do not merge into one motif.
 func BuildMotifKey(derived Event, contributors []Event, ruleID string) MotifKey {
-	types := make([]string, 0, len(contributors))
+	tokens := make([]string, 0, len(contributors))
 	for _, c := range contributors {
-		types = append(types, string(c.EventType))
+		tokens = append(tokens, string(c.EventType)+"@"+string(c.EventDomain))
 	}
-	types = stableSortStrings(types)
+	tokens = stableSortStrings(tokens)
 	return MotifKey{
 		DerivedType:    derived.EventType,
 		DerivedDomain:  derived.EventDomain,
-		ContributorSig: joinWithSep(types, "|"),
+		ContributorSig: joinWithSep(tokens, "|"),
 		RuleID:         ruleID,
 	}
 }
@@ -76,6 +102,25 @@ type StructuralMemory interface {
 	// Motif memory (optional)
 	GetMotifStats(key MotifKey) (MotifStats, bool)
 	ListMotifs() []MotifKey
+	// MotifsByDerivedType returns the motifs producing events of type t.
+	MotifsByDerivedType(t EventType) []MotifKey
+	// MotifsByRule returns the motifs materialized by the rule (or pattern) ruleID.
+	MotifsByRule(ruleID string) []MotifKey
+}
+
+// EventRemovalObserver is an optional StructuralMemory extension notified after an event
+// was removed from the network, with the neighbours it had (contributors -> event -> derived).
+// MemoizedNetwork uses it to invalidate exactly the affected revisions.
+type EventRemovalObserver interface {
+	OnEventRemoved(event Event, contributors []Event, derived []Event)
+}
+
+// MaterializationRetractor is an optional extension of StructuralMemory and PatternObserver
+// undoing OnMaterialized for a derived event that is about to be removed, so motif and
+// lineage counts only reflect derivations still in the network. SynapseRuntime calls it
+// when ReevaluateOnChange retracts a derivation to re-derive it.
+type MaterializationRetractor interface {
+	OnRetracted(derived Event, contributors []Event, ruleID string)
 }
 
 // InMemoryStructuralMemory is a POC implementation.
@@ -95,12 +140,19 @@ type InMemoryStructuralMemory struct {
 
 	// sigs[eventID] => signatures for k=0..maxDepth
 	sigs map[EventID][]uint64
+	// bySig is the reverse of sigs: events per (k, signature), in the order they got it
+	bySig map[sigIndexKey][]EventID
 
 	// lineageStats counts repeated multi-level patterns.
 	lineageStats map[LineageKey]*LineageStats
 
 	// (optional) keep a small sample list size to avoid memory blow-up
 	maxSamplesPerLineage int
+	// bounds LineageStats.Recent, and so how many occurrences a windowed count can see
+	maxRecentPerLineage int
+
+	clock         Clock
+	trendHalfLife time.Duration
 }
 
 func NewInMemoryStructuralMemory() *InMemoryStructuralMemory {
@@ -112,11 +164,30 @@ func NewInMemoryStructuralMemory() *InMemoryStructuralMemory {
 
 		maxDepth:             5,
 		sigs:                 make(map[EventID][]uint64),
+		bySig:                make(map[sigIndexKey][]EventID),
 		lineageStats:         make(map[LineageKey]*LineageStats),
 		maxSamplesPerLineage: 20,
+		maxRecentPerLineage:  1024,
+
+		clock:         SystemClock{},
+		trendHalfLife: defaultTrendHalfLife,
 	}
 }
 
+// SetClock replaces the clock used to timestamp motif occurrences and decay trend scores.
+func (m *InMemoryStructuralMemory) SetClock(clock Clock) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.clock = clock
+}
+
+// SetTrendHalfLife sets how fast TrendingMotifs forgets: a motif's weight halves every halfLife.
+func (m *InMemoryStructuralMemory) SetTrendHalfLife(halfLife time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.trendHalfLife = halfLife
+}
+
 // OnEventAdded updates revision counters AND stores Sig0 for the event.
 func (m *InMemoryStructuralMemory) OnEventAdded(event Event) {
 	m.mu.Lock()
@@ -169,7 +240,11 @@ func (m *InMemoryStructuralMemory) OnMaterialized(derived Event, contributors []
 		stats = &MotifStats{}
 		m.motifs[key] = stats
 	}
-	now := time.Now()
+	now := m.clock.Now()
+	if stats.Count > 0 {
+		stats.DecayedScore = m.decayLocked(stats.DecayedScore, now.Sub(stats.LastSeen))
+	}
+	stats.DecayedScore++
 	stats.Count++
 	stats.LastSeen = now
 	stats.Instances = append(stats.Instances, MotifInstance{
@@ -180,6 +255,71 @@ func (m *InMemoryStructuralMemory) OnMaterialized(derived Event, contributors []
 	})
 }
 
+// OnRetracted implements MaterializationRetractor: it removes derived's occurrence from
+// its motif and lineage stats (dropping stats that reach zero) and from their instances
+// and samples. Revisions are left to OnEventRemoved, which follows the removal.
+// DecayedScore loses the occurrence's weight as of LastSeen, which is kept.
+func (m *InMemoryStructuralMemory) OnRetracted(derived Event, contributors []Event, ruleID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := BuildMotifKey(derived, contributors, ruleID)
+	if stats, ok := m.motifs[key]; ok {
+		kept := stats.Instances[:0]
+		for _, inst := range stats.Instances {
+			if inst.DerivedID != derived.ID {
+				kept = append(kept, inst)
+				continue
+			}
+			stats.Count--
+			stats.DecayedScore = math.Max(0, stats.DecayedScore-m.decayLocked(1, stats.LastSeen.Sub(inst.At)))
+		}
+		stats.Instances = kept
+		if stats.Count <= 0 {
+			delete(m.motifs, key)
+		}
+	}
+
+	ds := m.sigs[derived.ID]
+	for k := 1; k < len(ds); k++ {
+		m.dropLineageOccurrenceLocked(LineageKey{
+			DerivedType:   derived.EventType,
+			DerivedDomain: derived.EventDomain,
+			Depth:         k,
+			Sig:           ds[k],
+		}, derived.ID, ruleID)
+	}
+}
+
+// dropLineageOccurrenceLocked undoes bumpLineageStatsLocked for one derived event.
+func (m *InMemoryStructuralMemory) dropLineageOccurrenceLocked(key LineageKey, derivedID EventID, ruleID string) {
+	st, ok := m.lineageStats[key]
+	if !ok {
+		return
+	}
+	st.Count--
+	if st.Count <= 0 {
+		delete(m.lineageStats, key)
+		return
+	}
+	if st.RuleCounts[ruleID]--; st.RuleCounts[ruleID] <= 0 {
+		delete(st.RuleCounts, ruleID)
+	}
+	st.Samples = withoutDerived(st.Samples, derivedID)
+	st.Recent = withoutDerived(st.Recent, derivedID)
+}
+
+// withoutDerived drops the samples of derivedID, in place.
+func withoutDerived(samples []LineageSample, derivedID EventID) []LineageSample {
+	kept := samples[:0]
+	for _, sample := range samples {
+		if sample.DerivedID != derivedID {
+			kept = append(kept, sample)
+		}
+	}
+	return kept
+}
+
 func (m *InMemoryStructuralMemory) OnEdgeAdded(from, to EventID) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -192,6 +332,31 @@ func (m *InMemoryStructuralMemory) OnEdgeAdded(from, to EventID) {
 	// with full Event objects (or extend this hook to include types).
 }
 
+// OnEventRemoved implements EventRemovalObserver.
+func (m *InMemoryStructuralMemory) OnEventRemoved(event Event, contributors []Event, derived []Event) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.global++
+	m.typeRev[event.EventType]++
+	m.inRev[event.ID]++
+	m.outRev[event.ID]++
+
+	for _, c := range contributors {
+		m.outRev[c.ID]++
+		// Contributors may have just become parentless: their peer cohort changed.
+		m.typeRev[c.EventType]++
+	}
+	for _, d := range derived {
+		m.inRev[d.ID]++
+	}
+
+	for k, sig := range m.sigs[event.ID] {
+		m.unindexSigLocked(event.ID, k, sig)
+	}
+	delete(m.sigs, event.ID)
+}
+
 func (m *InMemoryStructuralMemory) InRev(of EventID) uint64 {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -239,6 +404,87 @@ func (m *InMemoryStructuralMemory) ListMotifs() []MotifKey {
 	return out
 }
 
+func (m *InMemoryStructuralMemory) MotifsByDerivedType(t EventType) []MotifKey {
+	return m.motifsWhere(func(k MotifKey) bool { return k.DerivedType == t })
+}
+
+func (m *InMemoryStructuralMemory) MotifsByRule(ruleID string) []MotifKey {
+	return m.motifsWhere(func(k MotifKey) bool { return k.RuleID == ruleID })
+}
+
+// motifsWhere returns the motif keys accepted by keep, in a stable order
+// (derived type, domain, contributor signature, rule).
+func (m *InMemoryStructuralMemory) motifsWhere(keep func(MotifKey) bool) []MotifKey {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	out := make([]MotifKey, 0)
+	for k := range m.motifs {
+		if keep(k) {
+			out = append(out, k)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return motifKeyLess(out[i], out[j])
+	})
+	return out
+}
+
+// motifKeyLess orders motif keys by derived type, domain, contributor signature and rule.
+func motifKeyLess(a, b MotifKey) bool {
+	if a.DerivedType != b.DerivedType {
+		return a.DerivedType < b.DerivedType
+	}
+	if a.DerivedDomain != b.DerivedDomain {
+		return a.DerivedDomain < b.DerivedDomain
+	}
+	if a.ContributorSig != b.ContributorSig {
+		return a.ContributorSig < b.ContributorSig
+	}
+	return a.RuleID < b.RuleID
+}
+
+// TrendingMotifs implements MotifTrendTracker.
+// Scores are decayed from each motif's LastSeen up to the clock's current time.
+func (m *InMemoryStructuralMemory) TrendingMotifs(k int) []MotifKeyScore {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if k <= 0 {
+		return nil
+	}
+
+	now := m.clock.Now()
+	out := make([]MotifKeyScore, 0, len(m.motifs))
+	for key, stats := range m.motifs {
+		out = append(out, MotifKeyScore{
+			Key:      key,
+			Score:    m.decayLocked(stats.DecayedScore, now.Sub(stats.LastSeen)),
+			Count:    stats.Count,
+			LastSeen: stats.LastSeen,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Score != out[j].Score {
+			return out[i].Score > out[j].Score
+		}
+		return out[i].LastSeen.After(out[j].LastSeen)
+	})
+	if len(out) > k {
+		out = out[:k]
+	}
+	return out
+}
+
+// decayLocked ages score by elapsed using the configured half-life.
+// A non-positive half-life disables decay; negative elapsed (clock skew) is treated as zero.
+func (m *InMemoryStructuralMemory) decayLocked(score float64, elapsed time.Duration) float64 {
+	if m.trendHalfLife <= 0 || elapsed <= 0 {
+		return score
+	}
+	return score * math.Exp2(-float64(elapsed)/float64(m.trendHalfLife))
+}
+
 // MaxSignatureDepth implements PatternMemory.
 func (m *InMemoryStructuralMemory) MaxSignatureDepth() int {
 	m.mu.RLock()
@@ -261,6 +507,33 @@ func (m *InMemoryStructuralMemory) EventSignature(eventID EventID, k int) (uint6
 	return s[k], true
 }
 
+// EventsWithSignature implements PatternMemory.
+func (m *InMemoryStructuralMemory) EventsWithSignature(k int, sig uint64) []EventID {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return append([]EventID(nil), m.bySig[sigIndexKey{k: k, sig: sig}]...)
+}
+
+// SimilarEvents implements PatternMemory.
+func (m *InMemoryStructuralMemory) SimilarEvents(id EventID, k int) []EventID {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	s, ok := m.sigs[id]
+	if !ok || k < 0 || k >= len(s) {
+		return nil
+	}
+
+	var out []EventID
+	for _, other := range m.bySig[sigIndexKey{k: k, sig: s[k]}] {
+		if other != id {
+			out = append(out, other)
+		}
+	}
+	return out
+}
+
 // GetLineageStats implements PatternMemory.
 func (m *InMemoryStructuralMemory) GetLineageStats(key LineageKey) (LineageStats, bool) {
 	m.mu.RLock()
@@ -269,19 +542,78 @@ func (m *InMemoryStructuralMemory) GetLineageStats(key LineageKey) (LineageStats
 	if !ok {
 		return LineageStats{}, false
 	}
-	return *st, true
+	out := *st
+	out.RuleCounts = make(map[string]int, len(st.RuleCounts))
+	for ruleID, n := range st.RuleCounts {
+		out.RuleCounts[ruleID] = n
+	}
+	out.Samples = append([]LineageSample(nil), st.Samples...)
+	out.Recent = append([]LineageSample(nil), st.Recent...)
+	return out, true
+}
+
+// LineageCount returns the number of distinct lineage shapes seen.
+func (m *InMemoryStructuralMemory) LineageCount() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.lineageStats)
+}
+
+// LineagesForType implements PatternMemory.
+// Keys are ordered by depth, then domain and signature.
+func (m *InMemoryStructuralMemory) LineagesForType(t EventType) []LineageKey {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var out []LineageKey
+	for k := range m.lineageStats {
+		if k.DerivedType == t {
+			out = append(out, k)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return lineageKeyLess(out[i], out[j])
+	})
+	return out
+}
+
+// TopLineages implements PatternMemory.
+// Keys are ranked by Count; ties are ordered as in LineagesForType, with type first.
+func (m *InMemoryStructuralMemory) TopLineages(n int) []LineageKey {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if n <= 0 {
+		return nil
+	}
+	out := make([]LineageKey, 0, len(m.lineageStats))
+	for k := range m.lineageStats {
+		out = append(out, k)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		ci, cj := m.lineageStats[out[i]].Count, m.lineageStats[out[j]].Count
+		if ci != cj {
+			return ci > cj
+		}
+		return lineageKeyLess(out[i], out[j])
+	})
+	if len(out) > n {
+		out = out[:n]
+	}
+	return out
 }
 
-//// ListLineages implements PatternMemory.
-//func (m *InMemoryStructuralMemory) ListLineages() []LineageKey {
-//	m.mu.RLock()
-//	defer m.mu.RUnlock()
-//	out := make([]LineageKey, 0, len(m.lineageStats))
-//	for k := range m.lineageStats {
-//		out = append(out, k)
-//	}
-//	return out
-//}
+// lineageKeyLess orders keys by type, depth, domain and signature, so listings are stable.
+func lineageKeyLess(a, b LineageKey) bool {
+	if a.DerivedType != b.DerivedType {
+		return a.DerivedType < b.DerivedType
+	}
+	if a.Depth != b.Depth {
+		return a.Depth < b.Depth
+	}
+	if a.DerivedDomain != b.DerivedDomain {
+		return a.DerivedDomain < b.DerivedDomain
+	}
+	return a.Sig < b.Sig
+}
 
 // ensureEventSigsLocked creates signature slots for the event if missing.
 // It writes Sig0 and also pre-fills SigK for leaves (no contributors) deterministically.
@@ -307,6 +639,35 @@ func (m *InMemoryStructuralMemory) ensureEventSigsLocked(ev Event, ruleID string
 	}
 
 	m.sigs[ev.ID] = s
+	for k, sig := range s {
+		m.indexSigLocked(ev.ID, k, sig)
+	}
+}
+
+type sigIndexKey struct {
+	k   int
+	sig uint64
+}
+
+func (m *InMemoryStructuralMemory) indexSigLocked(id EventID, k int, sig uint64) {
+	key := sigIndexKey{k: k, sig: sig}
+	m.bySig[key] = append(m.bySig[key], id)
+}
+
+func (m *InMemoryStructuralMemory) unindexSigLocked(id EventID, k int, sig uint64) {
+	key := sigIndexKey{k: k, sig: sig}
+	ids := m.bySig[key]
+	for i, other := range ids {
+		if other == id {
+			ids = append(ids[:i], ids[i+1:]...)
+			break
+		}
+	}
+	if len(ids) == 0 {
+		delete(m.bySig, key)
+		return
+	}
+	m.bySig[key] = ids
 }
 
 // computeDerivedLineageSigsLocked recomputes Sig1..SigK for derived
@@ -335,6 +696,10 @@ func (m *InMemoryStructuralMemory) computeDerivedLineageSigsLocked(
 
 		// ✅ RULE-AGNOSTIC SHAPE SIGNATURE
 		shapeSig := HashLineage(k, s0, "", prev)
+		if ds[k] != shapeSig {
+			m.unindexSigLocked(derived.ID, k, ds[k])
+			m.indexSigLocked(derived.ID, k, shapeSig)
+		}
 		ds[k] = shapeSig
 
 		// ✅ Aggregate by shapeSig (NOT by rule)
@@ -343,7 +708,7 @@ func (m *InMemoryStructuralMemory) computeDerivedLineageSigsLocked(
 			DerivedDomain: derived.EventDomain,
 			Depth:         k,
 			Sig:           shapeSig,
-		}, derived.ID, ruleID)
+		}, derived, ruleID)
 	}
 
 	m.sigs[derived.ID] = ds
@@ -352,9 +717,10 @@ func (m *InMemoryStructuralMemory) computeDerivedLineageSigsLocked(
 // bumpLineageStatsLocked increments pattern counters and stores some sample derived IDs.
 func (m *InMemoryStructuralMemory) bumpLineageStatsLocked(
 	key LineageKey,
-	derivedID EventID,
+	derived Event,
 	ruleID string,
 ) {
+	derivedID := derived.ID
 	st, ok := m.lineageStats[key]
 	if !ok {
 		st = &LineageStats{
@@ -378,4 +744,13 @@ func (m *InMemoryStructuralMemory) bumpLineageStatsLocked(
 			DerivedID: derivedID,
 		})
 	}
+
+	if len(st.Recent) >= m.maxRecentPerLineage {
+		st.Recent = append(st.Recent[:0], st.Recent[len(st.Recent)-m.maxRecentPerLineage+1:]...)
+	}
+	st.Recent = append(st.Recent, LineageSample{
+		At:        derived.Timestamp,
+		RuleID:    ruleID,
+		DerivedID: derivedID,
+	})
 }
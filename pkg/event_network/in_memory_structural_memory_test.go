@@ -1,6 +1,7 @@
 package event_network
 
 import (
+	"fmt"
 	"sync"
 	"testing"
 	"time"
@@ -63,6 +64,11 @@ func newFakeNetwork() *fakeNetwork {
 }
 
 func (n *fakeNetwork) AddEvent(event Event) (EventID, error) {
+	stored, err := n.AddEventFull(event)
+	return stored.ID, err
+}
+
+func (n *fakeNetwork) AddEventFull(event Event) (Event, error) {
 	n.mu.Lock()
 	defer n.mu.Unlock()
 
@@ -74,7 +80,7 @@ func (n *fakeNetwork) AddEvent(event Event) (EventID, error) {
 
 	n.events[id] = event
 	n.eventsByType[event.EventType] = append(n.eventsByType[event.EventType], event)
-	return id, nil
+	return event, nil
 }
 
 func (n *fakeNetwork) AddEdge(from EventID, to EventID, relation string) error {
@@ -86,6 +92,27 @@ func (n *fakeNetwork) AddEdge(from EventID, to EventID, relation string) error {
 	return nil
 }
 
+func (n *fakeNetwork) RemoveEvent(id EventID) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	ev, ok := n.events[id]
+	if !ok {
+		return fmt.Errorf("event not found: %s", id)
+	}
+	delete(n.events, id)
+	n.eventsByType[ev.EventType] = withoutEvent(n.eventsByType[ev.EventType], id)
+	for _, e := range n.in[id] {
+		n.out[e.From] = withoutEdgesTouching(n.out[e.From], id)
+	}
+	for _, e := range n.out[id] {
+		n.in[e.To] = withoutEdgesTouching(n.in[e.To], id)
+	}
+	delete(n.in, id)
+	delete(n.out, id)
+	return nil
+}
+
 func (n *fakeNetwork) Children(of EventID) ([]Event, error) {
 	n.mu.RLock()
 	defer n.mu.RUnlock()
@@ -110,6 +137,18 @@ func (n *fakeNetwork) Parents(of EventID) ([]Event, error) {
 	return out, nil
 }
 
+func (n *fakeNetwork) OutEdges(of EventID) ([]Edge, error) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return append([]Edge(nil), n.out[of]...), nil
+}
+
+func (n *fakeNetwork) InEdges(of EventID) ([]Edge, error) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return append([]Edge(nil), n.in[of]...), nil
+}
+
 func (n *fakeNetwork) Descendants(of EventID, maxDepth int) ([]Event, error) {
 	if maxDepth <= 0 {
 		maxDepth = 1
@@ -294,6 +333,41 @@ func (n *fakeNetwork) GetByType(eventType EventType) ([]Event, error) {
 	return out, nil
 }
 
+func (n *fakeNetwork) GetByDomain(domain EventDomain) ([]Event, error) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	var out []Event
+	for _, ev := range n.events {
+		if ev.EventDomain == domain {
+			out = append(out, ev)
+		}
+	}
+	return out, nil
+}
+
+func (n *fakeNetwork) GetByTimeRange(from, to time.Time) ([]Event, error) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	var out []Event
+	for _, ev := range n.events {
+		if !ev.Timestamp.Before(from) && !ev.Timestamp.After(to) {
+			out = append(out, ev)
+		}
+	}
+	return out, nil
+}
+
+func (n *fakeNetwork) AllEvents() ([]Event, error) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	out := make([]Event, 0, len(n.events))
+	for _, ev := range n.events {
+		out = append(out, ev)
+	}
+	sortByTimestamp(out)
+	return out, nil
+}
+
 // -----------------------------
 // Instrumented wrapper for cache assertions
 // -----------------------------
@@ -327,11 +401,21 @@ func (c *countingNetwork) AddEvent(event Event) (EventID, error) {
 	return c.base.AddEvent(event)
 }
 
+func (c *countingNetwork) AddEventFull(event Event) (Event, error) {
+	c.inc("AddEventFull")
+	return c.base.AddEventFull(event)
+}
+
 func (c *countingNetwork) AddEdge(from EventID, to EventID, relation string) error {
 	c.inc("AddEdge")
 	return c.base.AddEdge(from, to, relation)
 }
 
+func (c *countingNetwork) RemoveEvent(id EventID) error {
+	c.inc("RemoveEvent")
+	return c.base.RemoveEvent(id)
+}
+
 func (c *countingNetwork) Children(of EventID) ([]Event, error) {
 	c.inc("Children")
 	return c.base.Children(of)
@@ -342,6 +426,16 @@ func (c *countingNetwork) Parents(of EventID) ([]Event, error) {
 	return c.base.Parents(of)
 }
 
+func (c *countingNetwork) OutEdges(of EventID) ([]Edge, error) {
+	c.inc("OutEdges")
+	return c.base.OutEdges(of)
+}
+
+func (c *countingNetwork) InEdges(of EventID) ([]Edge, error) {
+	c.inc("InEdges")
+	return c.base.InEdges(of)
+}
+
 func (c *countingNetwork) Descendants(of EventID, maxDepth int) ([]Event, error) {
 	c.inc("Descendants")
 	return c.base.Descendants(of, maxDepth)
@@ -382,6 +476,21 @@ func (c *countingNetwork) GetByType(eventType EventType) ([]Event, error) {
 	return c.base.GetByType(eventType)
 }
 
+func (c *countingNetwork) GetByDomain(domain EventDomain) ([]Event, error) {
+	c.inc("GetByDomain")
+	return c.base.GetByDomain(domain)
+}
+
+func (c *countingNetwork) GetByTimeRange(from, to time.Time) ([]Event, error) {
+	c.inc("GetByTimeRange")
+	return c.base.GetByTimeRange(from, to)
+}
+
+func (c *countingNetwork) AllEvents() ([]Event, error) {
+	c.inc("AllEvents")
+	return c.base.AllEvents()
+}
+
 // -----------------------------
 // Tests
 // -----------------------------
@@ -443,6 +552,194 @@ func TestStructuralMemory_RevisionsAndMotifs(t *testing.T) {
 	require.Equal(t, uint64(2), mem.InRev(derived.ID))
 }
 
+func TestStructuralMemory_TrendingMotifs_RecentOutranksStale(t *testing.T) {
+	mem := NewInMemoryStructuralMemory()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	mem.SetClock(ClockFunc(func() time.Time { return now }))
+	mem.SetTrendHalfLife(time.Hour)
+
+	domain := EventDomain("infra")
+	materialize := func(derivedType EventType, ruleID string) {
+		leaf := Event{ID: nid(), EventType: "cpu", EventDomain: domain}
+		mem.OnMaterialized(Event{ID: nid(), EventType: derivedType, EventDomain: domain}, []Event{leaf}, ruleID)
+	}
+
+	// Historically frequent motif: 10 occurrences, then silence for 5 half-lives.
+	for i := 0; i < 10; i++ {
+		materialize("stale", "rule-stale")
+	}
+	now = now.Add(5 * time.Hour)
+
+	// Recently active motif: only 2 occurrences, right now.
+	materialize("fresh", "rule-fresh")
+	materialize("fresh", "rule-fresh")
+
+	syn := &SynapseRuntime{Memory: mem}
+	require.Len(t, syn.HotMotifs(5), 1, "raw counts still favour the stale motif")
+
+	trending := syn.TrendingMotifs(2)
+	require.Len(t, trending, 2)
+	require.Equal(t, EventType("fresh"), trending[0].Key.DerivedType)
+	require.InDelta(t, 2.0, trending[0].Score, 1e-9)
+	require.Equal(t, 2, trending[0].Count)
+	require.Equal(t, EventType("stale"), trending[1].Key.DerivedType)
+	require.InDelta(t, 10.0/32, trending[1].Score, 1e-9)
+	require.Equal(t, 10, trending[1].Count)
+
+	require.Len(t, syn.TrendingMotifs(1), 1)
+	require.Empty(t, syn.TrendingMotifs(0))
+
+	// Scores keep decaying while nothing happens.
+	now = now.Add(time.Hour)
+	require.InDelta(t, 1.0, syn.TrendingMotifs(1)[0].Score, 1e-9)
+}
+
+func TestStructuralMemory_MotifsByDerivedTypeAndRule(t *testing.T) {
+	mem := NewInMemoryStructuralMemory()
+	domain := EventDomain("infra")
+	materialize := func(derivedType EventType, ruleID string, contributorTypes ...EventType) {
+		contributors := make([]Event, 0, len(contributorTypes))
+		for _, ct := range contributorTypes {
+			contributors = append(contributors, Event{ID: nid(), EventType: ct, EventDomain: domain})
+		}
+		mem.OnMaterialized(Event{ID: nid(), EventType: derivedType, EventDomain: domain}, contributors, ruleID)
+	}
+
+	materialize(CpuCritical, "cpu-rule", CpuStatusChanged, CpuStatusChanged)
+	materialize(CpuCritical, "cpu-rule", CpuStatusChanged, CpuStatusChanged) // same motif again
+	materialize(CpuCritical, "cpu-burst-rule", CpuStatusChanged)
+	materialize(MemoryCritical, "memory-rule", MemoryStatusChanged)
+	materialize(ServerNodeChangeStatus, "node-rule", CpuCritical, MemoryCritical)
+	require.Len(t, mem.ListMotifs(), 4)
+
+	cpu := mem.MotifsByDerivedType(CpuCritical)
+	require.Len(t, cpu, 2)
+	require.Equal(t, "cpu-burst-rule", cpu[0].RuleID)
+	require.Equal(t, "cpu-rule", cpu[1].RuleID)
+	for _, k := range cpu {
+		require.Equal(t, EventType(CpuCritical), k.DerivedType)
+	}
+
+	byRule := mem.MotifsByRule("node-rule")
+	require.Equal(t, []MotifKey{{
+		DerivedType:    ServerNodeChangeStatus,
+		DerivedDomain:  domain,
+		ContributorSig: "cpu_critical@infra|memory_critical@infra",
+		RuleID:         "node-rule",
+	}}, byRule)
+
+	require.Empty(t, mem.MotifsByDerivedType("unknown"))
+	require.Empty(t, mem.MotifsByRule("unknown"))
+}
+
+func TestBuildMotifKey_SeparatesContributorDomains(t *testing.T) {
+	mem := NewInMemoryStructuralMemory()
+	materialize := func(contributorDomain EventDomain) MotifKey {
+		contributor := Event{ID: nid(), EventType: "status_changed", EventDomain: contributorDomain}
+		derived := Event{ID: nid(), EventType: "alert", EventDomain: "ops"}
+		mem.OnMaterialized(derived, []Event{contributor}, "rule")
+		return BuildMotifKey(derived, []Event{contributor}, "rule")
+	}
+
+	infra := materialize("infra")
+	materialize("infra")
+	animals := materialize("animal_observation")
+	require.NotEqual(t, infra, animals)
+	require.Equal(t, "status_changed@infra", infra.ContributorSig)
+
+	stats, ok := mem.GetMotifStats(infra)
+	require.True(t, ok)
+	require.Equal(t, 2, stats.Count)
+	stats, ok = mem.GetMotifStats(animals)
+	require.True(t, ok)
+	require.Equal(t, 1, stats.Count)
+	require.Len(t, mem.ListMotifs(), 2)
+}
+
+func TestInMemoryStructuralMemory_SimilarEvents(t *testing.T) {
+	mem := NewInMemoryStructuralMemory()
+	D := EventDomain("infra")
+
+	leaf := func(eventType EventType) Event {
+		ev := Event{ID: nid(), EventType: eventType, EventDomain: D, Timestamp: time.Now()}
+		mem.OnEventAdded(ev)
+		return ev
+	}
+	derive := func(eventType EventType, contributors ...Event) Event {
+		ev := Event{ID: nid(), EventType: eventType, EventDomain: D, Timestamp: time.Now()}
+		mem.OnMaterialized(ev, contributors, "rule")
+		return ev
+	}
+
+	// Two structurally identical derivations (A, A -> B) and one of a different shape (A, C -> B)
+	first := derive("B", leaf("A"), leaf("A"))
+	second := derive("B", leaf("A"), leaf("A"))
+	other := derive("B", leaf("A"), leaf("C"))
+
+	require.Equal(t, []EventID{second.ID}, mem.SimilarEvents(first.ID, 1))
+	require.Equal(t, []EventID{first.ID}, mem.SimilarEvents(second.ID, 1))
+	require.Empty(t, mem.SimilarEvents(other.ID, 1))
+
+	sig, ok := mem.EventSignature(first.ID, 1)
+	require.True(t, ok)
+	require.Equal(t, []EventID{first.ID, second.ID}, mem.EventsWithSignature(1, sig))
+
+	// Depth 0 only looks at the event itself: all three are B
+	require.ElementsMatch(t, []EventID{second.ID, other.ID}, mem.SimilarEvents(first.ID, 0))
+
+	require.Nil(t, mem.SimilarEvents(first.ID, mem.MaxSignatureDepth()+1))
+	require.Nil(t, mem.SimilarEvents(nid(), 1))
+
+	t.Run("removed events leave the index", func(t *testing.T) {
+		mem.OnEventRemoved(second, nil, nil)
+		require.Empty(t, mem.SimilarEvents(first.ID, 1))
+		require.Equal(t, []EventID{first.ID}, mem.EventsWithSignature(1, sig))
+	})
+}
+
+func TestInMemoryStructuralMemory_TopLineages(t *testing.T) {
+	mem := NewInMemoryStructuralMemory()
+	D := EventDomain("infra")
+
+	derive := func(derivedType EventType, contributorTypes ...EventType) Event {
+		contributors := make([]Event, 0, len(contributorTypes))
+		for _, ct := range contributorTypes {
+			c := Event{ID: nid(), EventType: ct, EventDomain: D}
+			mem.OnEventAdded(c)
+			contributors = append(contributors, c)
+		}
+		ev := Event{ID: nid(), EventType: derivedType, EventDomain: D}
+		mem.OnMaterialized(ev, contributors, "rule")
+		return ev
+	}
+
+	frequent := derive("B", "A", "A")
+	derive("B", "A", "A")
+	rare := derive("B", "A", "C")
+	derive("X", "A")
+
+	sig, ok := mem.EventSignature(frequent.ID, 1)
+	require.True(t, ok)
+	top := LineageKey{DerivedType: "B", DerivedDomain: D, Depth: 1, Sig: sig}
+	require.Equal(t, []LineageKey{top}, mem.TopLineages(1))
+	st, ok := mem.GetLineageStats(top)
+	require.True(t, ok)
+	require.Equal(t, 2, st.Count)
+	require.Nil(t, mem.TopLineages(0))
+	require.Len(t, mem.TopLineages(100), mem.LineageCount())
+
+	// Every depth of both B shapes, shallowest first
+	keys := mem.LineagesForType("B")
+	require.Len(t, keys, 2*mem.MaxSignatureDepth())
+	rareSig, _ := mem.EventSignature(rare.ID, 1)
+	require.ElementsMatch(t, []uint64{sig, rareSig}, []uint64{keys[0].Sig, keys[1].Sig})
+	for i, key := range keys {
+		require.Equal(t, EventType("B"), key.DerivedType)
+		require.Equal(t, i/2+1, key.Depth)
+	}
+	require.Empty(t, mem.LineagesForType("A"))
+}
+
 func TestCachedRelationProvider_CacheHitAndInvalidation(t *testing.T) {
 	net := newCountingNetwork()
 	mem := NewInMemoryStructuralMemory()
@@ -557,6 +854,139 @@ func TestCachedRelationProvider_PeersCache_UsesTypeRev(t *testing.T) {
 	require.Equal(t, 2, net.get("Peers"), "TypeRev bump should force recompute")
 }
 
+func TestCachedRelationProvider_CachesEmptyResults(t *testing.T) {
+	net := newCountingNetwork()
+	mem := NewInMemoryStructuralMemory()
+	p := NewCachedRelationProvider(net, mem)
+
+	// A parentless event with no peers and no children
+	lone := Event{EventType: "lone", EventDomain: "infra", Timestamp: time.Now()}
+	loneID, _ := net.AddEvent(lone)
+	lone.ID = loneID
+	mem.OnEventAdded(lone)
+
+	for i := 0; i < 3; i++ {
+		peers, err := p.PeersCached(loneID, Conditions{}, "lone")
+		require.NoError(t, err)
+		require.Empty(t, peers)
+		children, err := p.ChildrenCached(loneID, Conditions{}, "")
+		require.NoError(t, err)
+		require.Empty(t, children)
+	}
+	require.Equal(t, 1, net.get("Peers"), "empty peers are served from the cache")
+	require.Equal(t, 1, net.get("Children"), "empty children are served from the cache")
+	require.Zero(t, net.get("GetByIDs"), "nothing to resolve for an empty result")
+
+	// A new peer bumps TypeRev: the negative entry no longer applies
+	peer := Event{EventType: "lone", EventDomain: "infra", Timestamp: time.Now()}
+	peer.ID, _ = net.AddEvent(peer)
+	mem.OnEventAdded(peer)
+
+	peers, err := p.PeersCached(loneID, Conditions{}, "lone")
+	require.NoError(t, err)
+	require.Len(t, peers, 1)
+	require.Equal(t, 2, net.get("Peers"))
+}
+
+func TestCachedRelationProvider_MaxEntries(t *testing.T) {
+	net := newCountingNetwork()
+	mem := NewInMemoryStructuralMemory()
+	p := NewCachedRelationProvider(net, mem)
+	p.Cache.MaxEntries = 4
+
+	A := EventType("A")
+	B := EventType("B")
+	D := EventDomain("infra")
+
+	// 10 derived events, each with its own contributor
+	var derived []EventID
+	for i := 0; i < 10; i++ {
+		aID, _ := net.AddEvent(Event{EventType: A, EventDomain: D, Timestamp: time.Now()})
+		bID, _ := net.AddEvent(Event{EventType: B, EventDomain: D, Timestamp: time.Now()})
+		require.NoError(t, net.AddEdge(aID, bID, "trigger"))
+		derived = append(derived, bID)
+	}
+
+	for _, id := range derived {
+		children, err := p.ChildrenCached(id, Conditions{}, "")
+		require.NoError(t, err)
+		require.Len(t, children, 1)
+		require.LessOrEqual(t, p.Cache.Len(), 4)
+	}
+	require.Equal(t, 4, p.Cache.Len())
+	require.Equal(t, 10, net.get("Children"))
+
+	// Evicted entries are misses: every anchor still gets the right answer
+	for _, id := range derived {
+		children, err := p.ChildrenCached(id, Conditions{}, "")
+		require.NoError(t, err)
+		require.Len(t, children, 1)
+	}
+	require.Equal(t, 4, p.Cache.Len())
+	require.Greater(t, net.get("Children"), 10, "evicted entries are recomputed")
+
+	// The most recently used entry survives and is still invalidated by revisions
+	last := derived[len(derived)-1]
+	before := net.get("Children")
+	_, err := p.ChildrenCached(last, Conditions{}, "")
+	require.NoError(t, err)
+	require.Equal(t, before, net.get("Children"), "recently used entry is a hit")
+
+	a2ID, _ := net.AddEvent(Event{EventType: A, EventDomain: D, Timestamp: time.Now()})
+	require.NoError(t, net.AddEdge(a2ID, last, "trigger"))
+	mem.OnEdgeAdded(a2ID, last)
+	children, err := p.ChildrenCached(last, Conditions{}, "")
+	require.NoError(t, err)
+	require.Len(t, children, 2)
+	require.LessOrEqual(t, p.Cache.Len(), 4)
+}
+
+func TestMemoizedNetwork_RemoveEvent_BumpsRevisions(t *testing.T) {
+	base := newCountingNetwork()
+	mem := NewInMemoryStructuralMemory()
+	m := NewMemoizedNetwork(base, mem)
+
+	A := EventType("A")
+	B := EventType("B")
+	D := EventDomain("infra")
+
+	a1ID, err := m.AddEvent(Event{EventType: A, EventDomain: D})
+	require.NoError(t, err)
+	a2ID, err := m.AddEvent(Event{EventType: A, EventDomain: D})
+	require.NoError(t, err)
+	bID, err := m.AddEvent(Event{EventType: B, EventDomain: D})
+	require.NoError(t, err)
+	require.NoError(t, m.AddEdge(a1ID, bID, "trigger"))
+	require.NoError(t, m.AddEdge(a2ID, bID, "trigger"))
+
+	// Cache Children(b) and Parents(a2).
+	children, err := m.Children(bID)
+	require.NoError(t, err)
+	require.Len(t, children, 2)
+	_, err = m.Parents(a2ID)
+	require.NoError(t, err)
+
+	global := mem.GlobalRev()
+	inB := mem.InRev(bID)
+	outA1 := mem.OutRev(a1ID)
+	typeA := mem.TypeRev(A)
+
+	require.NoError(t, m.RemoveEvent(a1ID))
+
+	require.Greater(t, mem.GlobalRev(), global)
+	require.Greater(t, mem.InRev(bID), inB, "former parent lost a contributor")
+	require.Greater(t, mem.OutRev(a1ID), outA1)
+	require.Greater(t, mem.TypeRev(A), typeA)
+
+	children, err = m.Children(bID)
+	require.NoError(t, err)
+	require.Len(t, children, 1, "cached Children must be invalidated")
+	require.Equal(t, a2ID, children[0].ID)
+
+	require.Error(t, m.RemoveEvent(a1ID))
+	require.Equal(t, 2, base.get("RemoveEvent"))
+}
+
 func TestMemoizedNetwork_AllMethodsCovered(t *testing.T) {
 	base := newCountingNetwork()
 	mem := NewInMemoryStructuralMemory()
@@ -670,7 +1100,7 @@ func Test_applyFilterAndConditionsStandalone_HitsAllBranches(t *testing.T) {
 	require.Len(t, filtered, 1)
 	require.Equal(t, "v", filtered[0].Properties["k"])
 
-	// Exercise hashConditions determinism on map order:
+	// Exercise HashConditions determinism on map order:
 	cond2 := Conditions{
 		TimeWindow:     &TimeWindow{Within: 60, TimeUnit: Second},
 		PropertyValues: map[string]any{"b": 2, "a": 1},
@@ -679,5 +1109,5 @@ func Test_applyFilterAndConditionsStandalone_HitsAllBranches(t *testing.T) {
 		TimeWindow:     &TimeWindow{Within: 60, TimeUnit: Second},
 		PropertyValues: map[string]any{"a": 1, "b": 2},
 	}
-	require.Equal(t, hashConditions(cond2), hashConditions(cond3), "hashConditions should be order-independent for maps")
+	require.Equal(t, HashConditions(cond2), HashConditions(cond3), "HashConditions should be order-independent for maps")
 }
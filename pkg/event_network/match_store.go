@@ -0,0 +1,102 @@
+package event_network
+
+import (
+	"sync"
+	"time"
+)
+
+// MatchStore holds the pattern matches a PatternCompositionWatcher has seen.
+//
+// Keeping matches behind an interface lets several watcher instances share one store
+// (e.g. Redis-backed), so multiple workers contribute to the same composition.
+// Implementations must be safe for concurrent use.
+type MatchStore interface {
+	// Add records match under pid.
+	Add(pid PatternIdentifier, match PatternMatch)
+
+	// Recent returns the matches recorded under pid, in the order they were added.
+	// With window > 0 only matches at most window older than the newest one are returned;
+	// window == 0 returns every retained match.
+	Recent(pid PatternIdentifier, window time.Duration) []PatternMatch
+
+	// Cleanup drops all matches older than cutoff.
+	Cleanup(cutoff time.Time)
+
+	// Remove drops every match recorded under pid.
+	Remove(pid PatternIdentifier)
+}
+
+// InMemoryMatchStore is the default MatchStore: a map of per-pattern slices.
+type InMemoryMatchStore struct {
+	mu      sync.RWMutex
+	matches map[PatternIdentifier][]PatternMatch
+}
+
+func NewInMemoryMatchStore() *InMemoryMatchStore {
+	return &InMemoryMatchStore{
+		matches: make(map[PatternIdentifier][]PatternMatch),
+	}
+}
+
+func (s *InMemoryMatchStore) Add(pid PatternIdentifier, match PatternMatch) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.matches[pid] = append(s.matches[pid], match)
+}
+
+func (s *InMemoryMatchStore) Recent(pid PatternIdentifier, window time.Duration) []PatternMatch {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	matches := s.matches[pid]
+	if len(matches) == 0 {
+		return nil
+	}
+
+	if window <= 0 {
+		out := make([]PatternMatch, len(matches))
+		copy(out, matches)
+		return out
+	}
+
+	var newest time.Time
+	for _, m := range matches {
+		if m.At.After(newest) {
+			newest = m.At
+		}
+	}
+	cutoff := newest.Add(-window)
+
+	out := make([]PatternMatch, 0, len(matches))
+	for _, m := range matches {
+		if !m.At.Before(cutoff) {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+func (s *InMemoryMatchStore) Cleanup(cutoff time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for pid, matches := range s.matches {
+		valid := make([]PatternMatch, 0, len(matches))
+		for _, m := range matches {
+			if !m.At.Before(cutoff) {
+				valid = append(valid, m)
+			}
+		}
+		if len(valid) == 0 {
+			delete(s.matches, pid)
+			continue
+		}
+		s.matches[pid] = valid
+	}
+}
+
+func (s *InMemoryMatchStore) Remove(pid PatternIdentifier) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.matches, pid)
+}
@@ -0,0 +1,83 @@
+package event_network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemoryMatchStore(t *testing.T) {
+	pid := PatternIdentifier{EventType: HighFrequencyOfMinorTremors, EventDomain: Geology}
+	other := PatternIdentifier{EventType: MultipleAnimalUnexpectedBehavior, EventDomain: AnimalObservation}
+	baseTime := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	newStore := func() *InMemoryMatchStore {
+		store := NewInMemoryMatchStore()
+		store.Add(pid, newTestPatternMatch(HighFrequencyOfMinorTremors, Geology, baseTime.Add(-2*time.Hour)))
+		store.Add(pid, newTestPatternMatch(HighFrequencyOfMinorTremors, Geology, baseTime.Add(-30*time.Minute)))
+		store.Add(pid, newTestPatternMatch(HighFrequencyOfMinorTremors, Geology, baseTime))
+		store.Add(other, newTestPatternMatch(MultipleAnimalUnexpectedBehavior, AnimalObservation, baseTime.Add(-3*time.Hour)))
+		return store
+	}
+
+	t.Run("Recent returns matches in insertion order", func(t *testing.T) {
+		matches := newStore().Recent(pid, 0)
+		require.Len(t, matches, 3)
+		require.Equal(t, baseTime.Add(-2*time.Hour), matches[0].At)
+		require.Equal(t, baseTime, matches[2].At)
+	})
+
+	t.Run("Recent limits to window before the newest match", func(t *testing.T) {
+		matches := newStore().Recent(pid, time.Hour)
+		require.Len(t, matches, 2)
+		require.Equal(t, baseTime.Add(-30*time.Minute), matches[0].At)
+	})
+
+	t.Run("Recent of unknown pattern is empty", func(t *testing.T) {
+		require.Empty(t, newStore().Recent(PatternIdentifier{EventType: "unknown"}, 0))
+	})
+
+	t.Run("Recent returns a copy", func(t *testing.T) {
+		store := newStore()
+		matches := store.Recent(pid, 0)
+		matches[0].Occurrence = 99
+		require.NotEqual(t, 99, store.Recent(pid, 0)[0].Occurrence)
+	})
+
+	t.Run("Cleanup drops matches older than cutoff across patterns", func(t *testing.T) {
+		store := newStore()
+		store.Cleanup(baseTime.Add(-time.Hour))
+		require.Len(t, store.Recent(pid, 0), 2)
+		require.Empty(t, store.Recent(other, 0))
+	})
+
+	t.Run("Remove drops only the given pattern", func(t *testing.T) {
+		store := newStore()
+		store.Remove(pid)
+		require.Empty(t, store.Recent(pid, 0))
+		require.Len(t, store.Recent(other, 0), 1)
+	})
+}
+
+func TestPatternCompositionWatcher_SharedMatchStore(t *testing.T) {
+	synapse := newTestSynapse(t)
+	listener := &testCompositionListener{}
+	spec := newForbiddenPatternSpec()
+	spec.ForbiddenPatterns = nil
+
+	// Two workers of the same composition, each seeing only part of the stream
+	shared := NewInMemoryMatchStore()
+	first := NewPatternCompositionWatcher(spec, synapse, listener)
+	first.Store = shared
+	second := NewPatternCompositionWatcher(spec, synapse, listener)
+	second.Store = shared
+
+	baseTime := time.Now()
+	first.OnPatternRepeated(newTestPatternMatch(MultipleAnimalUnexpectedBehavior, AnimalObservation, baseTime))
+	require.Equal(t, 0, listener.Count())
+
+	second.OnPatternRepeated(newTestPatternMatch(HighFrequencyOfMinorTremors, Geology, baseTime.Add(time.Minute)))
+	require.Equal(t, 1, listener.Count())
+	require.Len(t, listener.All()[0].Patterns, 2)
+}
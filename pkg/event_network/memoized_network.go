@@ -6,6 +6,7 @@ import (
 	"github.com/google/uuid"
 	"hash"
 	"hash/fnv"
+	"math"
 	"time"
 )
 
@@ -35,6 +36,11 @@ func NewMemoizedNetwork(base EventNetwork, mem StructuralMemory) *MemoizedNetwor
 	}
 }
 
+// Cache returns the relation cache, e.g. to bound it with MaxEntries or monitor its Len.
+func (m *MemoizedNetwork) Cache() *PatternCache {
+	return m.cache
+}
+
 func (m *MemoizedNetwork) AddEvent(event Event) (EventID, error) {
 	id, err := m.base.AddEvent(event)
 	if err == nil && m.mem != nil {
@@ -45,6 +51,14 @@ func (m *MemoizedNetwork) AddEvent(event Event) (EventID, error) {
 	return id, err
 }
 
+func (m *MemoizedNetwork) AddEventFull(event Event) (Event, error) {
+	stored, err := m.base.AddEventFull(event)
+	if err == nil && m.mem != nil {
+		m.mem.OnEventAdded(stored)
+	}
+	return stored, err
+}
+
 func (m *MemoizedNetwork) AddEdge(from EventID, to EventID, relation string) error {
 	err := m.base.AddEdge(from, to, relation)
 	if err == nil && m.mem != nil {
@@ -53,6 +67,51 @@ func (m *MemoizedNetwork) AddEdge(from EventID, to EventID, relation string) err
 	return err
 }
 
+// RemoveEvent removes the event from the base network and invalidates cached relations
+// of the event and its former neighbours.
+func (m *MemoizedNetwork) RemoveEvent(id EventID) error {
+	if m.mem == nil {
+		return m.base.RemoveEvent(id)
+	}
+
+	// Neighbours must be read before removal: afterwards the edges are gone.
+	event, err := m.base.GetByID(id)
+	if err != nil {
+		return err
+	}
+	contributors, err := m.base.Children(id)
+	if err != nil {
+		return err
+	}
+	derived, err := m.base.Parents(id)
+	if err != nil {
+		return err
+	}
+
+	if err := m.base.RemoveEvent(id); err != nil {
+		return err
+	}
+
+	notifyEventRemoved(m.mem, event, contributors, derived)
+	return nil
+}
+
+// notifyEventRemoved updates mem's revisions after event was removed together with its
+// edges to contributors and derived.
+func notifyEventRemoved(mem StructuralMemory, event Event, contributors []Event, derived []Event) {
+	if obs, ok := mem.(EventRemovalObserver); ok {
+		obs.OnEventRemoved(event, contributors, derived)
+		return
+	}
+	// Conservative fallback: every dropped edge bumps the same revisions as adding it did.
+	for _, c := range contributors {
+		mem.OnEdgeAdded(c.ID, event.ID)
+	}
+	for _, p := range derived {
+		mem.OnEdgeAdded(event.ID, p.ID)
+	}
+}
+
 func (m *MemoizedNetwork) Children(of EventID) ([]Event, error) {
 	p := &CachedRelationProvider{Net: m.base, Mem: m.mem, Cache: m.cache}
 	return p.ChildrenCached(of, Conditions{}, "")
@@ -63,7 +122,19 @@ func (m *MemoizedNetwork) Parents(of EventID) ([]Event, error) {
 	return p.ParentsCached(of, Conditions{}, "")
 }
 
+func (m *MemoizedNetwork) OutEdges(of EventID) ([]Edge, error) {
+	return m.base.OutEdges(of)
+}
+
+func (m *MemoizedNetwork) InEdges(of EventID) ([]Edge, error) {
+	return m.base.InEdges(of)
+}
+
 func (m *MemoizedNetwork) Descendants(of EventID, maxDepth int) ([]Event, error) {
+	if maxDepth <= 0 {
+		// Cached relations default depth to 1; keep the base "no levels" semantics instead.
+		return m.base.Descendants(of, maxDepth)
+	}
 	p := &CachedRelationProvider{Net: m.base, Mem: m.mem, Cache: m.cache}
 	return p.DescendantsCached(of, Conditions{MaxDepth: maxDepth}, "")
 }
@@ -84,6 +155,9 @@ func (m *MemoizedNetwork) Peers(of EventID) ([]Event, error) {
 }
 
 func (m *MemoizedNetwork) Cousins(of EventID, maxDepth int) ([]Event, error) {
+	if maxDepth <= 0 {
+		return m.base.Cousins(of, maxDepth)
+	}
 	p := &CachedRelationProvider{Net: m.base, Mem: m.mem, Cache: m.cache}
 	return p.CousinsCached(of, Conditions{MaxDepth: maxDepth}, "")
 }
@@ -104,6 +178,27 @@ func (m *MemoizedNetwork) GetByType(eventType EventType) ([]Event, error) {
 	return m.base.GetByType(eventType)
 }
 
+func (m *MemoizedNetwork) GetByDomain(domain EventDomain) ([]Event, error) {
+	return m.base.GetByDomain(domain)
+}
+
+func (m *MemoizedNetwork) GetByTimeRange(from, to time.Time) ([]Event, error) {
+	return m.base.GetByTimeRange(from, to)
+}
+
+func (m *MemoizedNetwork) AllEvents() ([]Event, error) {
+	return m.base.AllEvents()
+}
+
+// ParentlessByType delegates to the base network when it supports ParentlessIndex,
+// otherwise it filters GetByType results by Parents().
+func (m *MemoizedNetwork) ParentlessByType(eventType EventType) ([]Event, error) {
+	if idx, ok := m.base.(ParentlessIndex); ok {
+		return idx.ParentlessByType(eventType)
+	}
+	return parentlessByTypeSlow(m.base, eventType)
+}
+
 // ==========================
 // 4) Condition application
 // ==========================
@@ -137,24 +232,12 @@ func applyFilterAndConditionsStandalone(
 			continue
 		}
 
-		if cond.TimeWindow != nil {
-			d := cond.TimeWindow.TimeUnit.ToDuration(cond.TimeWindow.Within)
-			if ev.Timestamp.Before(anchorTS.Add(-d)) || ev.Timestamp.After(anchorTS) {
-				continue
-			}
+		if !cond.admitsTime(anchorTS, ev.Timestamp) {
+			continue
 		}
 
-		if cond.PropertyValues != nil {
-			ok := true
-			for k, v := range cond.PropertyValues {
-				if ev.Properties == nil || ev.Properties[k] != v {
-					ok = false
-					break
-				}
-			}
-			if !ok {
-				continue
-			}
+		if !matchConditionProperties(ev.Properties, cond) {
+			continue
 		}
 
 		out = append(out, ev)
@@ -175,7 +258,12 @@ func effectiveMaxDepth(cond Conditions) int {
 // 5) Hashing (cache key)
 // ==========================
 
-func hashConditions(c Conditions) uint64 {
+// HashConditions returns a stable 64-bit hash of c, for caches keyed by conditions
+// (PatternCache uses it). Equal conditions hash equally regardless of map iteration order,
+// also in nested PropertyValues; numbers are normalized as in property matching, so
+// int(3) and 3.0 hash alike. Distinct conditions may collide, as with any 64-bit hash.
+// PropertyMatchers are functions and are not hashed: do not cache conditions that use them.
+func HashConditions(c Conditions) uint64 {
 	h := fnv.New64a()
 
 	writeInt(h, effectiveMaxDepth(c))
@@ -187,6 +275,18 @@ func hashConditions(c Conditions) uint64 {
 		} else {
 			writeInt(h, 0)
 		}
+		if c.Counter.HowManyOrFewer {
+			writeInt(h, 1)
+		} else {
+			writeInt(h, 0)
+		}
+		if c.Counter.UseRange {
+			writeInt(h, 1)
+			writeInt(h, c.Counter.Min)
+			writeInt(h, c.Counter.Max)
+		} else {
+			writeInt(h, 0)
+		}
 	} else {
 		writeInt(h, 0)
 		writeInt(h, 0)
@@ -195,6 +295,9 @@ func hashConditions(c Conditions) uint64 {
 	if c.TimeWindow != nil {
 		writeInt(h, c.TimeWindow.Within)
 		writeString(h, string(c.TimeWindow.TimeUnit))
+		writeInt(h, int(c.TimeWindow.Direction))
+		// months and years depend on the window's Converter
+		writeInt(h, int(c.TimeWindow.Duration()))
 	} else {
 		writeInt(h, 0)
 		writeString(h, "")
@@ -208,22 +311,74 @@ func hashConditions(c Conditions) uint64 {
 		keys = stableSortStrings(keys)
 		for _, k := range keys {
 			writeString(h, k)
-			writeString(h, fmt.Sprintf("%v", c.PropertyValues[k]))
+			writeValue(h, c.PropertyValues[k])
 		}
 	} else {
 		writeString(h, "")
 	}
 
+	writeInt(h, int(c.PropertyMatchMode))
+	if c.RequireTimestamp {
+		writeInt(h, 1)
+	} else {
+		writeInt(h, 0)
+	}
+
+	writeString(h, c.OfEventType)
+	writeInt(h, len(c.ChildOrder))
+	for _, t := range c.ChildOrder {
+		writeString(h, t)
+	}
+
 	return h.Sum64()
 }
 
-func writeInt(h hash.Hash64, v int) {
+// writeValue hashes a property value: numbers normalized to float64, maps by sorted key,
+// slices element by element. Anything else is hashed by type and %v (fmt sorts map keys).
+func writeValue(h hash.Hash, v any) {
+	if f, ok := toFloat64(v); ok {
+		writeString(h, "n")
+		var buf [8]byte
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(f))
+		_, _ = h.Write(buf[:])
+		return
+	}
+
+	switch x := v.(type) {
+	case nil:
+		writeString(h, "nil")
+	case string:
+		writeString(h, "s")
+		writeString(h, x)
+	case map[string]any:
+		writeString(h, "m")
+		writeInt(h, len(x))
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		for _, k := range stableSortStrings(keys) {
+			writeString(h, k)
+			writeValue(h, x[k])
+		}
+	case []any:
+		writeString(h, "l")
+		writeInt(h, len(x))
+		for _, e := range x {
+			writeValue(h, e)
+		}
+	default:
+		writeString(h, fmt.Sprintf("%T:%v", v, v))
+	}
+}
+
+func writeInt(h hash.Hash, v int) {
 	var buf [8]byte
 	binary.LittleEndian.PutUint64(buf[:], uint64(v))
 	_, _ = h.Write(buf[:])
 }
 
-func writeString(h hash.Hash64, s string) {
+func writeString(h hash.Hash, s string) {
 	_, _ = h.Write([]byte(s))
 	_, _ = h.Write([]byte{0})
 }
@@ -1,5 +1,7 @@
 package event_network
 
+import "time"
+
 // EventNetwork is a directed acyclic graph (DAG) whose nodes represent immutable events and whose edges represent
 // derivation relationships between events.
 //   - The EventNetwork models semantic derivation, not causal explanation.
@@ -15,13 +17,22 @@ type EventNetwork interface {
 	// The returned EventID uniquely identifies the event.
 	AddEvent(event Event) (EventID, error)
 
+	// AddEventFull registers a new event in the network and returns the stored event,
+	// including its generated ID and timestamp. It saves a GetByID round-trip after AddEvent.
+	AddEventFull(event Event) (Event, error)
+
 	// AddEdge creates a directed semantic relationship between two events.
 	// from -> to
+	// Adding an identical (from, to, relation) edge again is a no-op.
 	AddEdge(from EventID, to EventID, relation string) error
 
+	// RemoveEvent deletes the event together with every edge referencing it.
+	// Returns an error if the event does not exist.
+	RemoveEvent(id EventID) error
+
 	// Children of an event are the events that directly contributed to its derivation.
 	//  - Children are semantic inputs.
-	//  - Structurally, they may appear as inbound edges.
+	//  - Structurally, they are the From side of inbound edges (contributor -> of).
 	// Querying an event for its children returns the events that were used to derive it.
 	Children(of EventID) ([]Event, error)
 	// Parents (Derived Events) Parents of an event are derived events that were created using this event as one of their inputs.
@@ -29,6 +40,11 @@ type EventNetwork interface {
 	//  - They exist at a higher derivation level.
 	Parents(of EventID) ([]Event, error)
 
+	// OutEdges returns the raw edges leaving an event (of -> derived), relation labels included.
+	OutEdges(of EventID) ([]Edge, error)
+	// InEdges returns the raw edges entering an event (contributor -> of), relation labels included.
+	InEdges(of EventID) ([]Edge, error)
+
 	// Descendants are all derivation-source events reachable by recursively traversing children, limited by maxDepth.
 	//  - This traversal explores the subgraph of contributing events.
 	//  - Depth is measured in derivation levels.
@@ -68,4 +84,22 @@ type EventNetwork interface {
 
 	// GetByType returns all events of a given type.
 	GetByType(eventType EventType) ([]Event, error)
+
+	// GetByDomain returns all events of a given domain.
+	GetByDomain(domain EventDomain) ([]Event, error)
+
+	// GetByTimeRange returns all events whose Timestamp falls in [from, to].
+	GetByTimeRange(from, to time.Time) ([]Event, error)
+
+	// AllEvents returns every event in the network, oldest first.
+	AllEvents() ([]Event, error)
+}
+
+// ParentlessIndex is an optional EventNetwork extension for cohort queries.
+//
+// Expression evaluation type-asserts to it to find parentless events of a type
+// in a single call instead of one Parents() call per candidate.
+type ParentlessIndex interface {
+	// ParentlessByType returns all events of the given type that have no derived parents.
+	ParentlessByType(eventType EventType) ([]Event, error)
 }
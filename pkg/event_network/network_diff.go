@@ -0,0 +1,77 @@
+package event_network
+
+// NetworkDiff lists what changed between two network snapshots.
+// Event sets hold IDs; edges are listed oldest From event first.
+type NetworkDiff struct {
+	AddedEvents   map[EventID]struct{}
+	RemovedEvents map[EventID]struct{}
+	AddedEdges    []Edge
+	RemovedEdges  []Edge
+}
+
+// Empty reports whether the snapshots are identical in events and edges.
+func (d NetworkDiff) Empty() bool {
+	return len(d.AddedEvents) == 0 && len(d.RemovedEvents) == 0 &&
+		len(d.AddedEdges) == 0 && len(d.RemovedEdges) == 0
+}
+
+// DiffNetworks compares two networks by event ID and by edge (from, to, relation),
+// e.g. a Snapshot taken before an ingest batch with the live network after it.
+// Changed properties of an event present in both are not reported.
+// Events or edges that can't be read are treated as absent.
+func DiffNetworks(before, after EventNetwork) NetworkDiff {
+	beforeEvents, beforeEdges := eventsAndEdges(before)
+	afterEvents, afterEdges := eventsAndEdges(after)
+
+	diff := NetworkDiff{
+		AddedEvents:   make(map[EventID]struct{}),
+		RemovedEvents: make(map[EventID]struct{}),
+	}
+	diff.AddedEdges = edgesMissingFrom(afterEdges, beforeEdges)
+	diff.RemovedEdges = edgesMissingFrom(beforeEdges, afterEdges)
+	for id := range afterEvents {
+		if _, ok := beforeEvents[id]; !ok {
+			diff.AddedEvents[id] = struct{}{}
+		}
+	}
+	for id := range beforeEvents {
+		if _, ok := afterEvents[id]; !ok {
+			diff.RemovedEvents[id] = struct{}{}
+		}
+	}
+	return diff
+}
+
+func eventsAndEdges(n EventNetwork) (map[EventID]struct{}, []Edge) {
+	ids := make(map[EventID]struct{})
+	var edges []Edge
+
+	events, err := n.AllEvents()
+	if err != nil {
+		return ids, nil
+	}
+	for _, ev := range events {
+		ids[ev.ID] = struct{}{}
+		out, err := n.OutEdges(ev.ID)
+		if err != nil {
+			continue
+		}
+		edges = append(edges, out...)
+	}
+	return ids, edges
+}
+
+// edgesMissingFrom returns the edges of from that other lacks, in from's order.
+func edgesMissingFrom(from, other []Edge) []Edge {
+	present := make(map[Edge]struct{}, len(other))
+	for _, e := range other {
+		present[e] = struct{}{}
+	}
+	var missing []Edge
+	for _, e := range from {
+		if _, ok := present[e]; !ok {
+			missing = append(missing, e)
+		}
+	}
+	return missing
+}
@@ -1,10 +1,14 @@
 package event_network
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -189,6 +193,266 @@ func TestInMemoryEventNetwork_GetByType(t *testing.T) {
 	require.Equal(t, len(events), 3)
 }
 
+func TestInMemoryEventNetwork_GetByDomain(t *testing.T) {
+	network := NewInMemoryEventNetwork()
+	for i := 0; i < 3; i++ {
+		_, err := network.AddEvent(Event{EventType: CpuStatusChanged, EventDomain: "infra"})
+		require.NoError(t, err)
+	}
+	for i := 0; i < 2; i++ {
+		_, err := network.AddEvent(Event{EventType: "policy_changed", EventDomain: "governance"})
+		require.NoError(t, err)
+	}
+
+	infra, err := network.GetByDomain("infra")
+	require.NoError(t, err)
+	require.Len(t, infra, 3)
+
+	governance, err := network.GetByDomain("governance")
+	require.NoError(t, err)
+	require.Len(t, governance, 2)
+	for _, ev := range governance {
+		require.Equal(t, EventDomain("governance"), ev.EventDomain)
+	}
+
+	require.NoError(t, network.RemoveEvent(governance[0].ID))
+	governance, err = network.GetByDomain("governance")
+	require.NoError(t, err)
+	require.Len(t, governance, 1)
+
+	unknown, err := network.GetByDomain("unknown")
+	require.NoError(t, err)
+	require.Empty(t, unknown)
+}
+
+func TestInMemoryEventNetwork_LeavesAndRoots(t *testing.T) {
+	net, parents, children := buildInfraSubGraph(t)
+	network := net.(*InMemoryEventNetwork)
+
+	leafIDs := make(map[EventID]bool)
+	for _, ev := range network.Leaves() {
+		leafIDs[ev.ID] = true
+	}
+	require.Len(t, leafIDs, 6)
+	for _, id := range append(children.CpuEventsIDs, children.MemoryEventsIDs...) {
+		require.True(t, leafIDs[id], "cpu/memory status events are leaves")
+	}
+
+	roots := network.Roots()
+	require.Len(t, roots, 1)
+	require.Equal(t, parents.ServerNodeChangeStatusID, roots[0].ID)
+	require.Equal(t, ServerNodeChangeStatus, roots[0].EventType)
+}
+
+func TestInMemoryEventNetwork_Stats(t *testing.T) {
+	net, _, _ := buildInfraSubGraph(t)
+	network := net.(*InMemoryEventNetwork)
+
+	stats := network.Stats()
+	// 3 cpu + 3 memory status changes, cpu/memory critical and the node status change
+	require.Equal(t, 9, stats.Events)
+	// 3+3 into the criticals, 2 into the node status change
+	require.Equal(t, 8, stats.Edges)
+	require.Equal(t, map[EventType]int{
+		CpuStatusChanged:       3,
+		MemoryStatusChanged:    3,
+		CpuCritical:            1,
+		MemoryCritical:         1,
+		ServerNodeChangeStatus: 1,
+	}, stats.EventsByType)
+	require.Equal(t, map[EventDomain]int{InfraDomain: 9}, stats.EventsByDomain)
+	require.Equal(t, map[string]int{"trigger": 8}, stats.EdgesByRelation)
+}
+
+func TestInMemoryEventNetwork_GetByTimeRange(t *testing.T) {
+	network := NewInMemoryEventNetwork()
+	base := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	ids := make([]EventID, 0, 5)
+	for _, offset := range []time.Duration{0, 15 * time.Minute, 30 * time.Minute, 45 * time.Minute, 60 * time.Minute} {
+		id, err := network.AddEvent(Event{
+			EventType:   CpuStatusChanged,
+			EventDomain: InfraDomain,
+			Timestamp:   base.Add(offset),
+		})
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+
+	events, err := network.GetByTimeRange(base.Add(15*time.Minute), base.Add(45*time.Minute))
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	require.Equal(t, []EventID{ids[1], ids[2], ids[3]}, collectIDs(events))
+
+	all, err := network.GetByTimeRange(base, base.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, all, 5)
+
+	none, err := network.GetByTimeRange(base.Add(2*time.Hour), base.Add(3*time.Hour))
+	require.NoError(t, err)
+	require.Empty(t, none)
+}
+
+func TestInMemoryEventNetwork_JSONRoundTrip(t *testing.T) {
+	network, parentNodes, _ := buildInfraSubGraph(t)
+	net := network.(*InMemoryEventNetwork)
+
+	compositionID, err := net.AddEvent(Event{EventType: "composition", EventDomain: InfraDomain})
+	require.NoError(t, err)
+	require.NoError(t, net.AddEdge(parentNodes.ServerNodeChangeStatusID, compositionID, "pattern_composition"))
+
+	payload, err := json.Marshal(net)
+	require.NoError(t, err)
+
+	loaded, err := LoadNetworkJSON(bytes.NewReader(payload))
+	require.NoError(t, err)
+
+	require.Len(t, loaded.events, 10)
+	require.Equal(t, net.RelationCounts(), loaded.RelationCounts())
+
+	in, err := loaded.InEdges(compositionID)
+	require.NoError(t, err)
+	require.Equal(t, []Edge{{From: parentNodes.ServerNodeChangeStatusID, To: compositionID, Relation: "pattern_composition"}}, in)
+
+	original, err := net.GetByID(parentNodes.CpuCriticalID)
+	require.NoError(t, err)
+	restored, err := loaded.GetByID(parentNodes.CpuCriticalID)
+	require.NoError(t, err)
+	require.True(t, original.Timestamp.Equal(restored.Timestamp))
+	require.Equal(t, original.EventType, restored.EventType)
+
+	children, err := loaded.Children(parentNodes.CpuCriticalID)
+	require.NoError(t, err)
+	require.Len(t, children, 3)
+	byType, err := loaded.GetByType(CpuStatusChanged)
+	require.NoError(t, err)
+	require.Len(t, byType, 3)
+
+	t.Run("rejects edges to unknown events", func(t *testing.T) {
+		_, err := LoadNetworkJSON(strings.NewReader(`{"events":[],"edges":[{"From":"` + compositionID.String() + `","To":"` + compositionID.String() + `","Relation":"x"}]}`))
+		require.Error(t, err)
+	})
+}
+
+func TestExportDOT(t *testing.T) {
+	network, parentNodes, childNodes := buildInfraSubGraph(t)
+
+	var out bytes.Buffer
+	require.NoError(t, ExportDOT(network, &out))
+	dot := out.String()
+
+	require.True(t, strings.HasPrefix(dot, "digraph events {\n"))
+	require.True(t, strings.HasSuffix(dot, "}\n"))
+
+	leaf := childNodes.CpuEventsIDs[0]
+	require.Contains(t, dot, fmt.Sprintf(`  "%s" [label="cpu_status_changed\ninfra_domain", style=filled, fillcolor=white];`, leaf))
+	require.Contains(t, dot, fmt.Sprintf(`  "%s" [label="cpu_critical\ninfra_domain", style=filled, fillcolor=lightblue];`, parentNodes.CpuCriticalID))
+	require.Contains(t, dot, fmt.Sprintf(`  "%s" -> "%s" [label="trigger"];`, leaf, parentNodes.CpuCriticalID))
+	require.Contains(t, dot, fmt.Sprintf(`  "%s" -> "%s" [label="trigger"];`, parentNodes.CpuCriticalID, parentNodes.ServerNodeChangeStatusID))
+
+	require.Equal(t, 9, strings.Count(dot, "[label=")-strings.Count(dot, "->"), "one line per node")
+	require.Equal(t, 8, strings.Count(dot, "->"), "one line per edge")
+}
+
+func TestInMemoryEventNetwork_AddEdge_StrictDAG(t *testing.T) {
+	net := NewInMemoryEventNetwork()
+	require.True(t, net.StrictDAG)
+
+	a, _ := net.AddEvent(Event{EventType: CpuStatusChanged, EventDomain: InfraDomain})
+	b, _ := net.AddEvent(Event{EventType: CpuCritical, EventDomain: InfraDomain})
+	c, _ := net.AddEvent(Event{EventType: ServerNodeChangeStatus, EventDomain: InfraDomain})
+
+	// a -> b -> c is a valid chain
+	require.NoError(t, net.AddEdge(a, b, "trigger"))
+	require.NoError(t, net.AddEdge(b, c, "trigger"))
+	require.NoError(t, net.AddEdge(a, c, "trigger"), "shortcuts are not cycles")
+
+	err := net.AddEdge(c, a, "trigger")
+	require.ErrorIs(t, err, ErrCycle)
+	require.ErrorContains(t, err, "cycle")
+	require.ErrorContains(t, err, c.String())
+
+	require.ErrorIs(t, net.AddEdge(b, b, "trigger"), ErrCycle)
+
+	parents, err := net.Parents(c)
+	require.NoError(t, err)
+	require.Empty(t, parents, "rejected edges are not stored")
+
+	net.StrictDAG = false
+	require.NoError(t, net.AddEdge(c, a, "trigger"))
+}
+
+// buildLinearChain links n events into one derivation ladder: ids[0] -> ids[1] -> ... -> ids[n-1].
+func buildLinearChain(tb testing.TB, n int) (*InMemoryEventNetwork, []EventID) {
+	tb.Helper()
+	net := NewInMemoryEventNetwork()
+	ids := make([]EventID, n)
+	for i := range ids {
+		id, err := net.AddEvent(Event{EventType: fmt.Sprintf("level_%d", i), EventDomain: InfraDomain})
+		require.NoError(tb, err)
+		ids[i] = id
+		if i > 0 {
+			require.NoError(tb, net.AddEdge(ids[i-1], id, "trigger"))
+		}
+	}
+	return net, ids
+}
+
+func TestInMemoryEventNetwork_DeepChainTraversal(t *testing.T) {
+	const depth = 10_000
+	net, ids := buildLinearChain(t, depth)
+
+	descendants, err := net.Descendants(ids[depth-1], depth)
+	require.NoError(t, err)
+	require.Len(t, descendants, depth-1)
+	require.Equal(t, ids[depth-2], descendants[0].ID, "nearest level first")
+
+	ancestors, err := net.Ancestors(ids[0], depth)
+	require.NoError(t, err)
+	require.Len(t, ancestors, depth-1)
+
+	limited, err := net.Descendants(ids[depth-1], 3)
+	require.NoError(t, err)
+	require.Equal(t, []EventID{ids[depth-2], ids[depth-3], ids[depth-4]}, collectIDs(limited))
+}
+
+func TestInMemoryEventNetwork_Descendants_UsesShortestDepth(t *testing.T) {
+	// root <- mid <- shared <- deep, plus a shortcut root <- shared.
+	net := NewInMemoryEventNetwork()
+	root, _ := net.AddEvent(Event{EventType: "root", EventDomain: InfraDomain})
+	mid, _ := net.AddEvent(Event{EventType: "mid", EventDomain: InfraDomain})
+	shared, _ := net.AddEvent(Event{EventType: "shared", EventDomain: InfraDomain})
+	deep, _ := net.AddEvent(Event{EventType: "deep", EventDomain: InfraDomain})
+	require.NoError(t, net.AddEdge(mid, root, "trigger"))
+	require.NoError(t, net.AddEdge(shared, mid, "trigger"))
+	require.NoError(t, net.AddEdge(shared, root, "trigger"))
+	require.NoError(t, net.AddEdge(deep, shared, "trigger"))
+
+	// shared is one level below root, so deep is within two levels.
+	descendants, err := net.Descendants(root, 2)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []EventID{mid, shared, deep}, collectIDs(descendants))
+}
+
+func BenchmarkInMemoryEventNetwork_DeepChain(b *testing.B) {
+	const depth = 10_000
+	net, ids := buildLinearChain(b, depth)
+
+	b.Run("Descendants", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = net.Descendants(ids[depth-1], depth)
+		}
+	})
+
+	b.Run("Ancestors", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = net.Ancestors(ids[0], depth)
+		}
+	})
+}
+
 func toProps(eventPropsPayload string) EventProps {
 	props := make(EventProps)
 	json.Unmarshal([]byte(eventPropsPayload), &props)
@@ -721,3 +985,305 @@ func TestInMemoryEventNetwork_Cousins_EdgeCases(t *testing.T) {
 		}
 	})
 }
+
+func TestInMemoryEventNetwork_AddEventFull(t *testing.T) {
+	eventNetwork := NewInMemoryEventNetwork()
+	stored, err := eventNetwork.AddEventFull(Event{
+		EventType:   CpuStatusChanged,
+		EventDomain: InfraDomain,
+		Properties:  toProps(cpuStatusChangedEvent),
+	})
+	require.NoError(t, err)
+	require.NotEqual(t, uuid.Nil, stored.ID)
+	require.False(t, stored.Timestamp.IsZero())
+	require.Equal(t, EventType(CpuStatusChanged), stored.EventType)
+
+	fetched, err := eventNetwork.GetByID(stored.ID)
+	require.NoError(t, err)
+	require.Equal(t, fetched, stored)
+
+	ts := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	stored, err = eventNetwork.AddEventFull(Event{
+		EventType:   CpuStatusChanged,
+		EventDomain: InfraDomain,
+		Timestamp:   ts,
+	})
+	require.NoError(t, err)
+	require.NotEqual(t, uuid.Nil, stored.ID)
+	require.Equal(t, ts, stored.Timestamp)
+}
+
+func TestInMemoryEventNetwork_RelationCounts(t *testing.T) {
+	network, parentNodes, _ := buildInfraSubGraph(t)
+	net := network.(*InMemoryEventNetwork)
+
+	// buildInfraSubGraph wires 8 "trigger" edges
+	require.Equal(t, map[string]int{"trigger": 8}, net.RelationCounts())
+
+	compositionID, err := net.AddEvent(Event{EventType: "composition", EventDomain: InfraDomain})
+	require.NoError(t, err)
+	require.NoError(t, net.AddEdge(parentNodes.ServerNodeChangeStatusID, compositionID, "pattern_composition"))
+	require.NoError(t, net.AddEdge(parentNodes.CpuCriticalID, compositionID, "contrib"))
+
+	require.Equal(t, map[string]int{
+		"trigger":             8,
+		"pattern_composition": 1,
+		"contrib":             1,
+	}, net.RelationCounts())
+
+	require.Empty(t, NewInMemoryEventNetwork().RelationCounts())
+}
+
+func TestInMemoryEventNetwork_AddEdge_Duplicate(t *testing.T) {
+	net := NewInMemoryEventNetwork()
+	contributorID, err := addCpuStatusChangedEvent(net, 98.3, "critical")
+	require.NoError(t, err)
+	derivedID, err := net.AddEvent(Event{EventType: CpuCritical, EventDomain: InfraDomain})
+	require.NoError(t, err)
+
+	require.NoError(t, net.AddEdge(contributorID, derivedID, "trigger"))
+	require.NoError(t, net.AddEdge(contributorID, derivedID, "trigger"))
+
+	children, err := net.Children(derivedID)
+	require.NoError(t, err)
+	require.Len(t, children, 1)
+
+	parents, err := net.Parents(contributorID)
+	require.NoError(t, err)
+	require.Len(t, parents, 1)
+	require.Equal(t, derivedID, parents[0].ID)
+
+	// Same endpoints with a different relation is a distinct edge
+	require.NoError(t, net.AddEdge(contributorID, derivedID, "pattern_composition"))
+	parents, err = net.Parents(contributorID)
+	require.NoError(t, err)
+	require.Len(t, parents, 2)
+}
+
+func TestInMemoryEventNetwork_Children_ReturnsContributors(t *testing.T) {
+	net := NewInMemoryEventNetwork()
+	c1, err := addCpuStatusChangedEvent(net, 98.3, "critical")
+	require.NoError(t, err)
+	c2, err := addCpuStatusChangedEvent(net, 95.2, "critical")
+	require.NoError(t, err)
+	derivedID, err := net.AddEvent(Event{EventType: CpuCritical, EventDomain: InfraDomain})
+	require.NoError(t, err)
+	require.NoError(t, net.AddEdge(c1, derivedID, "trigger"))
+	require.NoError(t, net.AddEdge(c2, derivedID, "trigger"))
+
+	children, err := net.Children(derivedID)
+	require.NoError(t, err)
+	require.Len(t, children, 2)
+	require.ElementsMatch(t, []EventID{c1, c2}, collectIDs(children))
+	for _, child := range children {
+		require.NotEqual(t, derivedID, child.ID)
+		require.Equal(t, EventType(CpuStatusChanged), child.EventType)
+	}
+
+	// MemoizedNetwork delegates to the base network
+	memoized := NewMemoizedNetwork(net, NewInMemoryStructuralMemory())
+	children, err = memoized.Children(derivedID)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []EventID{c1, c2}, collectIDs(children))
+}
+
+func TestInMemoryEventNetwork_Descendants_ReturnsContributors(t *testing.T) {
+	net := NewInMemoryEventNetwork()
+	c1, err := addCpuStatusChangedEvent(net, 98.3, "critical")
+	require.NoError(t, err)
+	c2, err := addCpuStatusChangedEvent(net, 95.2, "critical")
+	require.NoError(t, err)
+	c3, err := addCpuStatusChangedEvent(net, 91.7, "critical")
+	require.NoError(t, err)
+	cpuCriticalID, err := net.AddEvent(Event{EventType: CpuCritical, EventDomain: InfraDomain})
+	require.NoError(t, err)
+	serverID, err := net.AddEvent(Event{EventType: ServerNodeChangeStatus, EventDomain: InfraDomain})
+	require.NoError(t, err)
+	require.NoError(t, net.AddEdge(c1, cpuCriticalID, "trigger"))
+	require.NoError(t, net.AddEdge(c2, cpuCriticalID, "trigger"))
+	require.NoError(t, net.AddEdge(cpuCriticalID, serverID, "trigger"))
+	require.NoError(t, net.AddEdge(c3, serverID, "trigger"))
+
+	// Depth 1 is the direct contributors, never the anchor itself
+	descendants, err := net.Descendants(serverID, 1)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []EventID{cpuCriticalID, c3}, collectIDs(descendants))
+
+	// Depth 2 adds the contributors of those contributors
+	descendants, err = net.Descendants(serverID, 2)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []EventID{cpuCriticalID, c3, c1, c2}, collectIDs(descendants))
+
+	descendants, err = net.Descendants(c1, 2)
+	require.NoError(t, err)
+	require.Empty(t, descendants)
+
+	_, err = net.Descendants(EventID(uuid.New()), 1)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "event not found")
+
+	t.Run("memoized network keeps base semantics for depth <= 0", func(t *testing.T) {
+		memoized := NewMemoizedNetwork(net, NewInMemoryStructuralMemory())
+		for _, depth := range []int{0, -1} {
+			want, err := net.Descendants(serverID, depth)
+			require.NoError(t, err)
+			got, err := memoized.Descendants(serverID, depth)
+			require.NoError(t, err)
+			require.Empty(t, got)
+			require.ElementsMatch(t, collectIDs(want), collectIDs(got))
+
+			want, err = net.Cousins(c1, depth)
+			require.NoError(t, err)
+			got, err = memoized.Cousins(c1, depth)
+			require.NoError(t, err)
+			require.ElementsMatch(t, collectIDs(want), collectIDs(got))
+		}
+
+		got, err := memoized.Descendants(serverID, 1)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []EventID{cpuCriticalID, c3}, collectIDs(got))
+	})
+}
+
+func TestInMemoryEventNetwork_ConcurrentIngest(t *testing.T) {
+	synapse := newSynapseStoringDuplicates(nil)
+
+	const goroutines = 50
+	const perGoroutine = 20
+
+	// require must not be called off the test goroutine: each goroutine reports its first error
+	errs := make(chan error, goroutines)
+	var wg sync.WaitGroup
+	wg.Add(goroutines)
+	for g := 0; g < goroutines; g++ {
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perGoroutine; i++ {
+				if _, err := synapse.Ingest(createCpuStatusChangedEvent(float64(i), "critical")); err != nil {
+					errs <- err
+					return
+				}
+				// concurrent reads alongside writes
+				if _, err := synapse.GetNetwork().GetByType(CpuStatusChanged); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	events, err := synapse.GetNetwork().GetByType(CpuStatusChanged)
+	require.NoError(t, err)
+	require.Len(t, events, goroutines*perGoroutine)
+}
+
+func TestInMemoryEventNetwork_AllEdges(t *testing.T) {
+	network, _, _ := buildInfraSubGraph(t)
+	net := network.(*InMemoryEventNetwork)
+
+	events, err := net.GetByTimeRange(time.Time{}, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	var outDegrees []Edge
+	for _, ev := range events {
+		out, err := net.OutEdges(ev.ID)
+		require.NoError(t, err)
+		outDegrees = append(outDegrees, out...)
+	}
+	require.NotEmpty(t, outDegrees)
+
+	edges := net.AllEdges()
+	require.Len(t, edges, len(outDegrees))
+	require.ElementsMatch(t, outDegrees, edges)
+
+	// Re-adding an edge doesn't duplicate it
+	require.NoError(t, net.AddEdge(edges[0].From, edges[0].To, edges[0].Relation))
+	require.Len(t, net.AllEdges(), len(outDegrees))
+
+	visited := 0
+	net.ForEachEdge(func(Edge) bool {
+		visited++
+		return visited < 2
+	})
+	require.Equal(t, 2, visited, "stops when fn returns false")
+}
+
+func TestDiffNetworks(t *testing.T) {
+	synapse := NewSynapse([]PatternConfig{})
+	synapse.RegisterRule(CpuStatusChanged, NewDeriveEventRule("cpu_critical",
+		NewCondition().HasPeers(CpuStatusChanged, Conditions{
+			Counter: &Counter{HowMany: 1, HowManyOrMore: true},
+		}),
+		EventTemplate{EventType: CpuCritical, EventDomain: InfraDomain},
+	))
+	synapse.RegisterRule(CpuCritical, NewDeriveEventRule("node_status",
+		NewCondition().HasChild(CpuStatusChanged, Conditions{}),
+		EventTemplate{EventType: ServerNodeChangeStatus, EventDomain: InfraDomain},
+	))
+	net := synapse.Network.(*InMemoryEventNetwork)
+
+	_, err := synapse.Ingest(createCpuStatusChangedEvent(91, "critical"))
+	require.NoError(t, err)
+	before := net.Snapshot()
+	require.True(t, DiffNetworks(before, net).Empty())
+
+	result, err := synapse.IngestWithResult(createCpuStatusChangedEvent(95, "critical"))
+	require.NoError(t, err)
+	require.Len(t, result.Derived, 2, "cpu critical, then node status")
+
+	diff := DiffNetworks(before, net)
+	wantEvents := map[EventID]struct{}{result.EventID: {}}
+	var wantEdges []Edge
+	for _, derived := range result.Derived {
+		wantEvents[derived.ID] = struct{}{}
+		in, err := net.InEdges(derived.ID)
+		require.NoError(t, err)
+		wantEdges = append(wantEdges, in...)
+	}
+	require.Equal(t, wantEvents, diff.AddedEvents)
+	require.ElementsMatch(t, wantEdges, diff.AddedEdges)
+	require.Empty(t, diff.RemovedEvents)
+	require.Empty(t, diff.RemovedEdges)
+
+	// The snapshot is unaffected by the ingest, and the reverse diff reports removals
+	all, err := before.GetByTimeRange(time.Time{}, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	reverse := DiffNetworks(net, before)
+	require.Equal(t, wantEvents, reverse.RemovedEvents)
+	require.ElementsMatch(t, wantEdges, reverse.RemovedEdges)
+}
+
+func TestInMemoryEventNetwork_DeterministicOrdering(t *testing.T) {
+	network, parents, children := buildInfraSubGraph(t)
+
+	query := func() [][]Event {
+		var out [][]Event
+		add := func(events []Event, err error) {
+			require.NoError(t, err)
+			out = append(out, events)
+		}
+		add(network.GetByType(CpuStatusChanged))
+		add(network.GetByDomain(InfraDomain))
+		add(network.Children(parents.CpuCriticalID))
+		add(network.Siblings(children.CpuEventsIDs[0]))
+		add(network.Cousins(children.MemoryEventsIDs[0], 2))
+		add(network.Parents(children.CpuEventsIDs[0]))
+		add(network.GetByTimeRange(time.Time{}, time.Now().Add(time.Hour)))
+		return out
+	}
+
+	first := query()
+	require.Equal(t, first, query())
+	for _, events := range first {
+		for i := 1; i < len(events); i++ {
+			prev, cur := events[i-1], events[i]
+			require.True(t, prev.Timestamp.Before(cur.Timestamp) ||
+				(prev.Timestamp.Equal(cur.Timestamp) && prev.ID.String() < cur.ID.String()))
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package event_network
 
-import "sync"
+import (
+	"sync"
+	"sync/atomic"
+)
 
 //
 // ============================================
@@ -8,16 +11,77 @@ import "sync"
 // ============================================
 
 type PatternCache struct {
+	// MaxEntries bounds the number of cached relation results; 0 means unbounded.
+	// Beyond it the least recently used of a few sampled entries is evicted (approximate
+	// LRU, as in Redis), so entries keyed by outdated revisions go first. Set it before use.
+	MaxEntries int
+
 	mu  sync.RWMutex
-	rel map[relCacheKey]cachedIDs
+	rel map[relCacheKey]*cachedIDs
+	// tick orders cache accesses for eviction
+	tick atomic.Uint64
 }
 
 func NewPatternCache() *PatternCache {
-	return &PatternCache{rel: make(map[relCacheKey]cachedIDs)}
+	return &PatternCache{rel: make(map[relCacheKey]*cachedIDs)}
 }
 
 type cachedIDs struct {
-	IDs []EventID
+	IDs      []EventID
+	lastUsed atomic.Uint64
+}
+
+// evictionSample is how many entries are compared to pick the one to evict.
+const evictionSample = 8
+
+// Len returns the number of cached relation results.
+func (c *PatternCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return len(c.rel)
+}
+
+func (c *PatternCache) get(key relCacheKey) ([]EventID, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.rel[key]
+	if !ok {
+		return nil, false
+	}
+	entry.lastUsed.Store(c.tick.Add(1))
+	return entry.IDs, true
+}
+
+func (c *PatternCache) put(key relCacheKey, ids []EventID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &cachedIDs{IDs: ids}
+	entry.lastUsed.Store(c.tick.Add(1))
+	c.rel[key] = entry
+
+	for c.MaxEntries > 0 && len(c.rel) > c.MaxEntries {
+		c.evictOneLocked()
+	}
+}
+
+// evictOneLocked drops the least recently used of up to evictionSample entries.
+// Map iteration starts at a random position, which makes the sample random.
+func (c *PatternCache) evictOneLocked() {
+	var victim relCacheKey
+	oldest := uint64(0)
+	n := 0
+	for key, entry := range c.rel {
+		if used := entry.lastUsed.Load(); n == 0 || used < oldest {
+			victim, oldest = key, used
+		}
+		if n++; n == evictionSample {
+			break
+		}
+	}
+	delete(c.rel, victim)
 }
 
 type relationKind uint8
@@ -76,7 +140,7 @@ func (p *CachedRelationProvider) ParentsCached(anchor EventID, cond Conditions,
 
 func (p *CachedRelationProvider) DescendantsCached(anchor EventID, cond Conditions, filterType EventType) ([]Event, error) {
 	max := effectiveMaxDepth(cond)
-	return p.getOrCompute(relDescendants, anchor, Conditions{MaxDepth: max, Counter: cond.Counter, TimeWindow: cond.TimeWindow, PropertyValues: cond.PropertyValues}, filterType, func() ([]Event, error) {
+	return p.getOrCompute(relDescendants, anchor, Conditions{MaxDepth: max, Counter: cond.Counter, TimeWindow: cond.TimeWindow, PropertyValues: cond.PropertyValues, PropertyMatchers: cond.PropertyMatchers, PropertyMatchMode: cond.PropertyMatchMode, RequireTimestamp: cond.RequireTimestamp}, filterType, func() ([]Event, error) {
 		return p.Net.Descendants(anchor, max)
 	})
 }
@@ -97,7 +161,7 @@ func (p *CachedRelationProvider) PeersCached(anchor EventID, cond Conditions, fi
 
 func (p *CachedRelationProvider) CousinsCached(anchor EventID, cond Conditions, filterType EventType) ([]Event, error) {
 	max := effectiveMaxDepth(cond)
-	return p.getOrCompute(relCousins, anchor, Conditions{MaxDepth: max, Counter: cond.Counter, TimeWindow: cond.TimeWindow, PropertyValues: cond.PropertyValues}, filterType, func() ([]Event, error) {
+	return p.getOrCompute(relCousins, anchor, Conditions{MaxDepth: max, Counter: cond.Counter, TimeWindow: cond.TimeWindow, PropertyValues: cond.PropertyValues, PropertyMatchers: cond.PropertyMatchers, PropertyMatchMode: cond.PropertyMatchMode, RequireTimestamp: cond.RequireTimestamp}, filterType, func() ([]Event, error) {
 		return p.Net.Cousins(anchor, max)
 	})
 }
@@ -110,8 +174,9 @@ func (p *CachedRelationProvider) getOrCompute(
 	compute func() ([]Event, error),
 ) ([]Event, error) {
 
-	// Safe fallback when memory/caching isn't wired
-	if p.Mem == nil || p.Cache == nil {
+	// Safe fallback when memory/caching isn't wired. Matchers are functions that
+	// HashConditions cannot tell apart, so such terms are never cached either.
+	if p.Mem == nil || p.Cache == nil || cond.PropertyMatchers != nil {
 		evs, err := compute()
 		if err != nil {
 			return nil, err
@@ -124,7 +189,7 @@ func (p *CachedRelationProvider) getOrCompute(
 		Anchor:     anchor,
 		MaxDepth:   effectiveMaxDepth(cond),
 		FilterType: filterType,
-		CondHash:   hashConditions(cond),
+		CondHash:   HashConditions(cond),
 
 		// For single-hop relations, anchor revisions are often enough.
 		InRev:  p.Mem.InRev(anchor),
@@ -138,11 +203,13 @@ func (p *CachedRelationProvider) getOrCompute(
 		GlobalRev: p.Mem.GlobalRev(),
 	}
 
-	p.Cache.mu.RLock()
-	cached, ok := p.Cache.rel[key]
-	p.Cache.mu.RUnlock()
-	if ok {
-		evs, err := p.Net.GetByIDs(cached.IDs)
+	// An evicted entry is just a miss: it is recomputed below.
+	if cached, ok := p.Cache.get(key); ok {
+		if len(cached) == 0 {
+			// Negative results are cached too; serving them needs no network access.
+			return []Event{}, nil
+		}
+		evs, err := p.Net.GetByIDs(cached)
 		if err != nil {
 			return nil, err
 		}
@@ -165,9 +232,7 @@ func (p *CachedRelationProvider) getOrCompute(
 		ids = append(ids, e.ID)
 	}
 
-	p.Cache.mu.Lock()
-	p.Cache.rel[key] = cachedIDs{IDs: ids}
-	p.Cache.mu.Unlock()
+	p.Cache.put(key, ids)
 
 	return okEvs, nil
 }
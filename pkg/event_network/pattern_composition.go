@@ -2,14 +2,72 @@
 package event_network
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 )
 
+// CompositionIDProperty is the property key under which a composition-derived event
+// records the CompositionID of the spec that produced it.
+const CompositionIDProperty = "composition_id"
+
+// ContributingRuleIDsProperty is the property key under which a composition-derived event
+// lists the (sorted, distinct) rule IDs that produced its constituent patterns.
+const ContributingRuleIDsProperty = "contributing_rule_ids"
+
+// SourcePatternIDsProperty is the property key under which a composition-derived event
+// lists the DerivedIDs ([]EventID, sorted) of the pattern matches it was composed from.
+const SourcePatternIDsProperty = "source_pattern_ids"
+
 // PatternIdentifier uniquely identifies a pattern by type and domain
 type PatternIdentifier struct {
 	EventType   EventType
 	EventDomain EventDomain
+
+	// Depth optionally restricts the pattern to matches at this lineage depth
+	// (PatternMatch.Key.Depth). 0 means any depth.
+	Depth int
+}
+
+// String renders the identifier as "domain/type", with "@depth" when restricted to a depth.
+func (p PatternIdentifier) String() string {
+	if p.Depth == 0 {
+		return p.EventDomain + "/" + p.EventType
+	}
+	return fmt.Sprintf("%s/%s@%d", p.EventDomain, p.EventType, p.Depth)
+}
+
+// sortedIdentifiers returns the identifiers of set ordered by String().
+func sortedIdentifiers(set map[PatternIdentifier]struct{}) []PatternIdentifier {
+	out := make([]PatternIdentifier, 0, len(set))
+	for pid := range set {
+		out = append(out, pid)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].String() < out[j].String()
+	})
+	return out
+}
+
+// identifiersFor returns the identifiers a match can satisfy:
+// the depth-agnostic one and, for matches with a depth, the depth-specific one.
+func identifiersFor(match PatternMatch) []PatternIdentifier {
+	anyDepth := PatternIdentifier{
+		EventType:   match.Key.DerivedType,
+		EventDomain: match.Key.DerivedDomain,
+	}
+	if match.Key.Depth == 0 {
+		return []PatternIdentifier{anyDepth}
+	}
+	atDepth := anyDepth
+	atDepth.Depth = match.Key.Depth
+	return []PatternIdentifier{anyDepth, atDepth}
 }
 
 // PatternCompositionSpec defines which patterns must be recognized together
@@ -17,19 +75,107 @@ type PatternCompositionSpec struct {
 	// RequiredPatterns: set of pattern identifiers that must all be recognized
 	RequiredPatterns map[PatternIdentifier]struct{}
 
+	// AnyOfPatterns: groups of alternatives; each group is satisfied by any one of its
+	// patterns reaching its MinOccurrences, e.g. "external OR internal incident".
+	// All groups and all RequiredPatterns must hold. The satisfied alternative with the
+	// newest match takes part in the time window like a required pattern.
+	AnyOfPatterns []map[PatternIdentifier]struct{}
+
+	// ForbiddenPatterns: patterns that suppress the composition.
+	// If any of them has been recognized within TimeWindow (relative to the newest
+	// required match), the composition does not fire. Without a TimeWindow, any
+	// recorded forbidden match suppresses it. Useful for "all-clear"/recovery signals.
+	ForbiddenPatterns map[PatternIdentifier]struct{}
+
 	// TimeWindow: how close in time the patterns must be recognized
 	// All required patterns must be recognized within this window
 	TimeWindow *TimeWindow
 
+	// WindowAlignment: Rolling (default) measures TimeWindow as a delta between matches.
+	// CalendarDay / CalendarHour instead require the latest match of every required pattern
+	// to fall in the same calendar bucket as each other and as the evaluation time
+	// ("within the same business day"), in the evaluation time's location. Matches from
+	// earlier buckets are dropped; TimeWindow then only bounds forbidden patterns.
+	WindowAlignment WindowAlignment
+
 	// MinOccurrences: minimum number of times each pattern must be recognized
 	// If nil or empty, defaults to 1 for all patterns
 	MinOccurrences map[PatternIdentifier]int
 
+	// MinTotalOccurrences: minimum sum of the occurrences of all required patterns
+	// (and AnyOfPatterns alternatives) within TimeWindow of the evaluation time,
+	// on top of the per-pattern minimums ("at least 5 escalation signals of any kind").
+	// 0 disables the check.
+	MinTotalOccurrences int
+
 	// DerivedEventTemplate: what event to create when composition is recognized
 	DerivedEventTemplate EventTemplate
 
 	// CompositionID: unique identifier for this composition spec
 	CompositionID string
+
+	// Cooldown: after the composition is recognized it cannot derive again until this
+	// much time has passed, however many satisfying matches arrive meanwhile.
+	// Nil (default) allows a derivation on every satisfying match.
+	Cooldown *TimeWindow
+
+	// TriggerRules: whether rules run on the composition-derived event.
+	// nil (default) or true ingests it through Synapse.Ingest, as before.
+	// false stores it without rule evaluation (memory and pattern watchers are still
+	// notified). Synapse implementations without RuleFreeMaterializer always ingest.
+	TriggerRules *bool
+}
+
+// WindowAlignment selects how a composition's time window is measured.
+type WindowAlignment int
+
+const (
+	// Rolling measures PatternCompositionSpec.TimeWindow as a sliding delta.
+	Rolling WindowAlignment = iota
+	// CalendarDay buckets matches by calendar day.
+	CalendarDay
+	// CalendarHour buckets matches by calendar hour.
+	CalendarHour
+)
+
+// bucket returns the start of the calendar bucket holding t, in loc.
+func (a WindowAlignment) bucket(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	if a == CalendarHour {
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// format renders a bucket start for decision reasons.
+func (a WindowAlignment) format(bucket time.Time) string {
+	if a == CalendarHour {
+		return bucket.Format("2006-01-02 15:00")
+	}
+	return bucket.Format("2006-01-02")
+}
+
+// minOccurrences returns MinOccurrences for pid, defaulting to 1.
+func (s PatternCompositionSpec) minOccurrences(pid PatternIdentifier) int {
+	if n := s.MinOccurrences[pid]; n > 0 {
+		return n
+	}
+	return 1
+}
+
+// inAnyOf reports whether pid is an alternative of some AnyOfPatterns group.
+func (s PatternCompositionSpec) inAnyOf(pid PatternIdentifier) bool {
+	for _, group := range s.AnyOfPatterns {
+		if _, ok := group[pid]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// triggersRules reports whether the composed event should go through rule evaluation.
+func (s PatternCompositionSpec) triggersRules() bool {
+	return s.TriggerRules == nil || *s.TriggerRules
 }
 
 // PatternCompositionMatch represents a recognized pattern composition
@@ -38,6 +184,9 @@ type PatternCompositionMatch struct {
 	RecognizedAt time.Time
 	Patterns     []PatternMatch // The individual patterns that composed
 	DerivedEvent Event           // The derived event created (if any)
+
+	// SourcePatternIDs are the DerivedIDs of Patterns, sorted (see SourcePatternIDsProperty).
+	SourcePatternIDs []EventID
 }
 
 // PatternCompositionListener receives notifications when compositions are recognized
@@ -51,15 +200,28 @@ type PatternCompositionWatcher struct {
 	Synapse  Synapse
 	Listener PatternCompositionListener
 
-	// Track recent pattern matches within time window
-	mu            sync.RWMutex
-	recentMatches map[PatternIdentifier][]PatternMatch
+	// DecisionLog (optional) receives every composition decision as a JSON line
+	// (see CompositionDecision): fired or not, and why. Useful for tuning and incident review.
+	DecisionLog io.Writer
+
+	// Store keeps recent matches of required and forbidden patterns.
+	// Defaults to an InMemoryMatchStore; replace it (before use) with a shared store
+	// to let several watcher instances contribute to one composition.
+	Store MatchStore
 
-	// Track how many times each pattern has been recognized in current window
-	patternCounts map[PatternIdentifier]int
+	// FiredSet (optional) records the contributing matches of every derived composition;
+	// a combination already in it does not derive again. Persist and restore it to keep
+	// that across restarts. Nil keeps no record.
+	FiredSet FiredSet
 
-	// Cleanup old matches periodically
-	lastCleanup time.Time
+	// Serializes composition checks of this instance
+	mu sync.RWMutex
+
+	// fired counts recognized compositions
+	fired int
+
+	// lastRecognized is when the composition last fired, for Spec.Cooldown
+	lastRecognized time.Time
 }
 
 // NewPatternCompositionWatcher creates a new composition watcher
@@ -79,12 +241,10 @@ func NewPatternCompositionWatcher(
 	}
 
 	return &PatternCompositionWatcher{
-		Spec:          spec,
-		Synapse:       synapse,
-		Listener:      listener,
-		recentMatches: make(map[PatternIdentifier][]PatternMatch),
-		patternCounts: make(map[PatternIdentifier]int),
-		lastCleanup:   time.Now(),
+		Spec:     spec,
+		Synapse:  synapse,
+		Listener: listener,
+		Store:    NewInMemoryMatchStore(),
 	}
 }
 
@@ -95,54 +255,99 @@ func (w *PatternCompositionWatcher) OnPatternRepeated(match PatternMatch) {
 		return
 	}
 
-	// Identify which pattern this match belongs to
-	pid := PatternIdentifier{
-		EventType:   match.Key.DerivedType,
-		EventDomain: match.Key.DerivedDomain,
+	// Decided before locking: a composition-derived event is recognized as a pattern and
+	// forwarded back here while w.mu is held, so irrelevant matches must not touch the lock.
+	required, forbidden := w.identify(match)
+	if len(required) == 0 && len(forbidden) == 0 {
+		return
 	}
 
-	// Check if this pattern is part of our composition spec
-	if _, required := w.Spec.RequiredPatterns[pid]; !required {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.record(match, required, forbidden) {
 		return
 	}
 
+	// Check if composition is complete
+	w.checkComposition(time.Now())
+}
+
+// FiredCount returns how many times the composition was recognized.
+func (w *PatternCompositionWatcher) FiredCount() int {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.fired
+}
+
+// identify returns the required (or any-of) and forbidden identifiers of the spec that
+// match satisfies. It only reads Spec, so it needs no lock.
+func (w *PatternCompositionWatcher) identify(match PatternMatch) (required, forbidden []PatternIdentifier) {
+	for _, pid := range identifiersFor(match) {
+		if _, ok := w.Spec.RequiredPatterns[pid]; ok || w.Spec.inAnyOf(pid) {
+			required = append(required, pid)
+		}
+		if _, ok := w.Spec.ForbiddenPatterns[pid]; ok {
+			forbidden = append(forbidden, pid)
+		}
+	}
+	return required, forbidden
+}
+
+// record stores match under the identifiers found by identify and reports whether it
+// matched a required pattern (only those can complete the composition).
+// Callers must hold w.mu.
+func (w *PatternCompositionWatcher) record(match PatternMatch, required, forbidden []PatternIdentifier) bool {
+	for _, pid := range forbidden {
+		w.Store.Add(pid, match)
+	}
+	for _, pid := range required {
+		w.Store.Add(pid, match)
+	}
+	// A forbidden match can only suppress, never complete a composition.
+	return len(required) > 0
+}
+
+// Flush implements Flusher: it drops matches that fell out of the time window
+// and flushes DecisionLog when it buffers (Flush() error, e.g. *bufio.Writer)
+// or syncs to disk (Sync() error, e.g. *os.File).
+func (w *PatternCompositionWatcher) Flush(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	// Add to recent matches
-	w.recentMatches[pid] = append(w.recentMatches[pid], match)
-	w.patternCounts[pid]++
+	w.cleanupOldMatches(time.Now())
 
-	// Cleanup old matches periodically
-	now := time.Now()
-	if now.Sub(w.lastCleanup) > time.Minute {
-		w.cleanupOldMatches(now)
-		w.lastCleanup = now
+	switch log := w.DecisionLog.(type) {
+	case interface{ Flush() error }:
+		return log.Flush()
+	case interface{ Sync() error }:
+		return log.Sync()
 	}
-
-	// Check if composition is complete
-	w.checkComposition(now)
+	return nil
 }
 
 // cleanupOldMatches removes matches outside the time window
 func (w *PatternCompositionWatcher) cleanupOldMatches(now time.Time) {
+	if w.Spec.WindowAlignment != Rolling {
+		// earlier buckets can never complete the composition again
+		w.Store.Cleanup(w.Spec.WindowAlignment.bucket(now, now.Location()))
+		return
+	}
 	if w.Spec.TimeWindow == nil {
 		return
 	}
 
-	windowDuration := w.Spec.TimeWindow.TimeUnit.ToDuration(w.Spec.TimeWindow.Within)
-	cutoff := now.Add(-windowDuration)
+	windowDuration := w.Spec.TimeWindow.Duration()
+	w.Store.Cleanup(now.Add(-windowDuration))
+}
 
-	for pid, matches := range w.recentMatches {
-		validMatches := make([]PatternMatch, 0)
-		for _, m := range matches {
-			if m.At.After(cutoff) || m.At.Equal(cutoff) {
-				validMatches = append(validMatches, m)
-			}
-		}
-		w.recentMatches[pid] = validMatches
-		w.patternCounts[pid] = len(validMatches)
-	}
+// recent returns every retained match of pid.
+func (w *PatternCompositionWatcher) recent(pid PatternIdentifier) []PatternMatch {
+	return w.Store.Recent(pid, 0)
 }
 
 // checkComposition checks if all required patterns are recognized within the time window
@@ -151,34 +356,98 @@ func (w *PatternCompositionWatcher) checkComposition(now time.Time) {
 		return
 	}
 
-	// Check if all required patterns have minimum occurrences
-	for pid := range w.Spec.RequiredPatterns {
-		minOcc := w.Spec.MinOccurrences[pid]
-		if minOcc == 0 {
-			minOcc = 1
+	fired, reason := w.evaluatePruned(now)
+	w.logDecision(now, fired, reason)
+	if !fired {
+		return
+	}
+
+	// All conditions met - create composition match; only a derived one counts
+	// and starts the cooldown
+	if w.createCompositionMatch(now) {
+		w.fired++
+		w.lastRecognized = now
+	}
+}
+
+// evaluatePruned drops matches that fell out of the window (or bucket) and then runs
+// decide, so a stale match can never count toward the composition.
+func (w *PatternCompositionWatcher) evaluatePruned(now time.Time) (bool, string) {
+	w.cleanupOldMatches(now)
+	return w.decide(now)
+}
+
+// decide applies the cooldown and FiredSet on top of evaluateComposition.
+// The caller starts a new cooldown once the composition is derived.
+func (w *PatternCompositionWatcher) decide(now time.Time) (bool, string) {
+	fired, reason := w.evaluateComposition(now)
+	if !fired {
+		return false, reason
+	}
+
+	if w.Spec.Cooldown != nil && !w.lastRecognized.IsZero() {
+		cooldown := w.Spec.Cooldown.Duration()
+		if remaining := w.lastRecognized.Add(cooldown).Sub(now); remaining > 0 {
+			return false, fmt.Sprintf("cooling down for %s", formatDuration(remaining))
 		}
-		if w.patternCounts[pid] < minOcc {
-			return // Not all patterns have minimum occurrences
+	}
+	if w.FiredSet != nil && w.FiredSet.Contains(firedKey(w.Spec.CompositionID, w.latestRequired())) {
+		return false, "already fired for these matches"
+	}
+
+	return true, reason
+}
+
+// evaluateComposition decides whether the composition fires and explains why (not).
+func (w *PatternCompositionWatcher) evaluateComposition(now time.Time) (bool, string) {
+	// Check if all required patterns have minimum occurrences; sorted, so the
+	// reported pattern does not depend on map order
+	for _, pid := range sortedIdentifiers(w.Spec.RequiredPatterns) {
+		minOcc := w.Spec.minOccurrences(pid)
+		if count := len(w.recent(pid)); count < minOcc {
+			// Not all patterns have minimum occurrences
+			return false, fmt.Sprintf("pattern %s has %d of %d required occurrences",
+				pid, count, minOcc)
+		}
+	}
+	for i, group := range w.Spec.AnyOfPatterns {
+		if _, ok := w.anyOfChoice(group); !ok {
+			return false, fmt.Sprintf("no pattern of any-of group %d has its required occurrences", i+1)
+		}
+	}
+	if minTotal := w.Spec.MinTotalOccurrences; minTotal > 0 {
+		if total := w.totalOccurrences(now); total < minTotal {
+			return false, fmt.Sprintf("composition has %d of %d required total occurrences", total, minTotal)
 		}
 	}
 
+	// Latest required match is the reference point for forbidden patterns
+	var reference time.Time
+	for _, m := range w.latestRequired() {
+		if m.At.After(reference) {
+			reference = m.At
+		}
+	}
+	if pid, ok := w.forbiddenSeen(reference); ok {
+		// Suppressed by a recent forbidden pattern
+		return false, fmt.Sprintf("suppressed by forbidden pattern %s", pid)
+	}
+
+	if w.Spec.WindowAlignment != Rolling {
+		return w.evaluateCalendarBucket(now)
+	}
+
 	// If time window is specified, check that all patterns are within window
 	if w.Spec.TimeWindow != nil {
-		windowDuration := w.Spec.TimeWindow.TimeUnit.ToDuration(w.Spec.TimeWindow.Within)
+		windowDuration := w.Spec.TimeWindow.Duration()
 		cutoff := now.Add(-windowDuration)
 
 		// Find the earliest and latest pattern matches
 		var earliest, latest time.Time
 		var found bool
 
-		for pid := range w.Spec.RequiredPatterns {
-			matches := w.recentMatches[pid]
-			if len(matches) == 0 {
-				return // Pattern not found
-			}
-
-			// Get the most recent match for this pattern
-			mostRecent := matches[len(matches)-1]
+		// The most recent match of every required pattern (and chosen alternative)
+		for _, mostRecent := range w.latestRequired() {
 			if !found {
 				earliest = mostRecent.At
 				latest = mostRecent.At
@@ -194,36 +463,171 @@ func (w *PatternCompositionWatcher) checkComposition(now time.Time) {
 		}
 
 		// Check if all patterns are within the time window
-		if latest.Sub(earliest) > windowDuration {
-			return // Patterns are too far apart in time
+		if spread := latest.Sub(earliest); spread > windowDuration {
+			// Patterns are too far apart in time
+			return false, fmt.Sprintf("window exceeded by %s", formatDuration(spread-windowDuration))
 		}
 
 		// Check that all patterns are not too old
 		if earliest.Before(cutoff) {
-			return // Some patterns are too old
+			// Some patterns are too old
+			return false, fmt.Sprintf("earliest match is older than window by %s", formatDuration(cutoff.Sub(earliest)))
 		}
 	}
 
-	// All conditions met - create composition match
-	w.createCompositionMatch(now)
+	return true, "all required patterns matched"
 }
 
-// createCompositionMatch creates the derived event and notifies listener
-func (w *PatternCompositionWatcher) createCompositionMatch(recognizedAt time.Time) {
-	if w.Synapse == nil {
+// evaluateCalendarBucket requires the latest match of every required pattern, and now,
+// to share one calendar bucket. Buckets use now's location.
+func (w *PatternCompositionWatcher) evaluateCalendarBucket(now time.Time) (bool, string) {
+	var latest []time.Time
+	for _, m := range w.latestRequired() {
+		latest = append(latest, m.At)
+	}
+	if len(latest) == 0 {
+		return false, "no required patterns"
+	}
+	sort.Slice(latest, func(i, j int) bool { return latest[i].Before(latest[j]) })
+
+	alignment := w.Spec.WindowAlignment
+	loc := now.Location()
+	first := alignment.bucket(latest[0], loc)
+	last := alignment.bucket(latest[len(latest)-1], loc)
+	if !first.Equal(last) {
+		return false, fmt.Sprintf("matches span calendar buckets %s and %s",
+			alignment.format(first), alignment.format(last))
+	}
+	if current := alignment.bucket(now, loc); !current.Equal(last) {
+		return false, fmt.Sprintf("calendar bucket %s has ended", alignment.format(last))
+	}
+	return true, "all required patterns matched"
+}
+
+// CompositionDecision is one audited checkComposition outcome, written to DecisionLog as a JSON line.
+type CompositionDecision struct {
+	CompositionID string         `json:"composition_id"`
+	At            time.Time      `json:"at"`
+	Fired         bool           `json:"fired"`
+	Reason        string         `json:"reason"`
+	Counts        map[string]int `json:"counts"` // PatternIdentifier.String() -> matches in current window
+}
+
+// logDecision writes the decision as a JSON line when DecisionLog is set.
+func (w *PatternCompositionWatcher) logDecision(now time.Time, fired bool, reason string) {
+	if w.DecisionLog == nil {
 		return
 	}
 
-	// Collect all pattern matches
-	var allPatterns []PatternMatch
+	// Audit logging must never break composition: encoding errors are ignored.
+	_ = json.NewEncoder(w.DecisionLog).Encode(w.decision(now, fired, reason))
+}
+
+// decision snapshots the current per-pattern counts into a CompositionDecision.
+func (w *PatternCompositionWatcher) decision(now time.Time, fired bool, reason string) CompositionDecision {
+	counts := make(map[string]int, len(w.Spec.RequiredPatterns))
 	for pid := range w.Spec.RequiredPatterns {
-		matches := w.recentMatches[pid]
-		if len(matches) > 0 {
-			// Use the most recent match for each pattern
-			allPatterns = append(allPatterns, matches[len(matches)-1])
+		counts[pid.String()] = len(w.recent(pid))
+	}
+	for _, group := range w.Spec.AnyOfPatterns {
+		for pid := range group {
+			counts[pid.String()] = len(w.recent(pid))
 		}
 	}
 
+	return CompositionDecision{
+		CompositionID: w.Spec.CompositionID,
+		At:            now,
+		Fired:         fired,
+		Reason:        reason,
+		Counts:        counts,
+	}
+}
+
+// EvaluateCompositionSpec replays matches (in timestamp order) through spec and returns
+// the decision taken after each required match, without creating or ingesting anything.
+// It is meant for testing composition configurations against a recorded match log.
+//
+// clock supplies "now" for every step; nil replays each step at its match's own time.
+// Old matches are cleaned up as the live watcher does: before every evaluation.
+func EvaluateCompositionSpec(spec PatternCompositionSpec, matches []PatternMatch, clock Clock) []CompositionDecision {
+	var current time.Time
+	if clock == nil {
+		clock = ClockFunc(func() time.Time { return current })
+	}
+
+	ordered := make([]PatternMatch, len(matches))
+	copy(ordered, matches)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].At.Before(ordered[j].At) })
+
+	w := NewPatternCompositionWatcher(spec, nil, nil)
+
+	var decisions []CompositionDecision
+	for _, match := range ordered {
+		current = match.At
+		required, forbidden := w.identify(match)
+		if !w.record(match, required, forbidden) {
+			continue
+		}
+
+		now := clock.Now()
+		fired, reason := w.evaluatePruned(now)
+		if fired {
+			// Nothing is derived here: every fired decision starts the cooldown
+			w.lastRecognized = now
+		}
+		decisions = append(decisions, w.decision(now, fired, reason))
+	}
+	return decisions
+}
+
+// formatDuration renders d like time.Duration.String, without trailing zero units ("12m", not "12m0s").
+func formatDuration(d time.Duration) string {
+	str := d.Round(time.Second).String()
+	if strings.HasSuffix(str, "m0s") {
+		str = strings.TrimSuffix(str, "0s")
+	}
+	if strings.HasSuffix(str, "h0m") {
+		str = strings.TrimSuffix(str, "0m")
+	}
+	return str
+}
+
+// forbiddenSeen reports whether (and which) forbidden pattern was recognized recently enough
+// to suppress the composition. "Recently" means within TimeWindow of reference
+// (the newest required match); without a TimeWindow any forbidden match counts.
+func (w *PatternCompositionWatcher) forbiddenSeen(reference time.Time) (PatternIdentifier, bool) {
+	var windowDuration time.Duration
+	if w.Spec.TimeWindow != nil {
+		windowDuration = w.Spec.TimeWindow.Duration()
+	}
+
+	for _, pid := range sortedIdentifiers(w.Spec.ForbiddenPatterns) {
+		for _, m := range w.recent(pid) {
+			if w.Spec.TimeWindow == nil {
+				return pid, true
+			}
+			diff := reference.Sub(m.At)
+			if diff < 0 {
+				diff = -diff
+			}
+			if diff <= windowDuration {
+				return pid, true
+			}
+		}
+	}
+	return PatternIdentifier{}, false
+}
+
+// createCompositionMatch creates the derived event and notifies listener.
+// It reports whether the derived event was stored.
+func (w *PatternCompositionWatcher) createCompositionMatch(recognizedAt time.Time) bool {
+	if w.Synapse == nil {
+		return false
+	}
+
+	allPatterns := w.latestRequired()
+
 	// Create derived event from template
 	derived := Event{
 		EventType:   w.Spec.DerivedEventTemplate.EventType,
@@ -238,23 +642,39 @@ func (w *PatternCompositionWatcher) createCompositionMatch(recognizedAt time.Tim
 	}
 
 	// Add composition metadata
-	derived.Properties["composition_id"] = w.Spec.CompositionID
+	derived.Properties[CompositionIDProperty] = w.Spec.CompositionID
 	derived.Properties["pattern_count"] = len(allPatterns)
+	derived.Properties[ContributingRuleIDsProperty] = contributingRuleIDs(allPatterns)
+	sourceIDs := sourcePatternIDs(allPatterns)
+	derived.Properties[SourcePatternIDsProperty] = sourceIDs
+
+	if materializer, ok := w.Synapse.(RuleFreeMaterializer); ok && !w.Spec.triggersRules() {
+		// Store + link + notify memory/watchers, but skip rule evaluation
+		stored, err := materializer.MaterializeWithoutRules(derived, sourceIDs, "pattern_composition", w.Spec.CompositionID)
+		if err != nil && !errors.Is(err, ErrWatcherPanic) {
+			return false
+		}
+		derived = stored
+	} else {
+		// Ingest event through Synapse to trigger rules, memory updates, and pattern watchers
+		derivedID, err := w.Synapse.Ingest(derived)
+		if err != nil {
+			// Log error but continue
+			return false
+		}
+		derived.ID = derivedID
 
-	// Ingest event through Synapse to trigger rules, memory updates, and pattern watchers
-	derivedID, err := w.Synapse.Ingest(derived)
-	if err != nil {
-		// Log error but continue
-		return
-	}
-	derived.ID = derivedID
+		// Get network to add edges from pattern events to derived event
+		network := w.Synapse.GetNetwork()
 
-	// Get network to add edges from pattern events to derived event
-	network := w.Synapse.GetNetwork()
+		// Create edges from pattern events to derived event
+		for _, pattern := range allPatterns {
+			_ = network.AddEdge(pattern.DerivedID, derived.ID, "pattern_composition")
+		}
+	}
 
-	// Create edges from pattern events to derived event
-	for _, pattern := range allPatterns {
-		_ = network.AddEdge(pattern.DerivedID, derived.ID, "pattern_composition")
+	if w.FiredSet != nil {
+		w.FiredSet.Add(firedKey(w.Spec.CompositionID, allPatterns))
 	}
 
 	// Notify listener
@@ -263,25 +683,175 @@ func (w *PatternCompositionWatcher) createCompositionMatch(recognizedAt time.Tim
 		RecognizedAt: recognizedAt,
 		Patterns:     allPatterns,
 		DerivedEvent: derived,
+
+		SourcePatternIDs: sourceIDs,
 	}
 
 	w.Listener.OnCompositionRecognized(compositionMatch)
 
 	// Reset counts after composition is recognized (optional - you might want to keep them)
-	// w.resetCounts()
+	// w.resetLocked()
+	return true
+}
+
+// latestRequired returns the most recent match of each required pattern
+// and of the chosen alternative of each satisfied AnyOfPatterns group.
+func (w *PatternCompositionWatcher) latestRequired() []PatternMatch {
+	var latest []PatternMatch
+	for pid := range w.Spec.RequiredPatterns {
+		matches := w.recent(pid)
+		if len(matches) > 0 {
+			latest = append(latest, matches[len(matches)-1])
+		}
+	}
+	for _, group := range w.Spec.AnyOfPatterns {
+		if pid, ok := w.anyOfChoice(group); ok {
+			matches := w.recent(pid)
+			latest = append(latest, matches[len(matches)-1])
+		}
+	}
+	return latest
+}
+
+// totalOccurrences counts the matches of required patterns and AnyOfPatterns alternatives
+// within the rolling TimeWindow before now (all retained matches without one).
+// A pattern listed more than once is counted once.
+func (w *PatternCompositionWatcher) totalOccurrences(now time.Time) int {
+	var cutoff time.Time
+	if w.Spec.TimeWindow != nil && w.Spec.WindowAlignment == Rolling {
+		cutoff = now.Add(-w.Spec.TimeWindow.Duration())
+	}
+
+	counted := make(map[PatternIdentifier]bool)
+	total := 0
+	count := func(pid PatternIdentifier) {
+		if counted[pid] {
+			return
+		}
+		counted[pid] = true
+		for _, m := range w.recent(pid) {
+			if !m.At.Before(cutoff) {
+				total++
+			}
+		}
+	}
+	for pid := range w.Spec.RequiredPatterns {
+		count(pid)
+	}
+	for _, group := range w.Spec.AnyOfPatterns {
+		for pid := range group {
+			count(pid)
+		}
+	}
+	return total
+}
+
+// anyOfChoice picks the alternative of group that reached its MinOccurrences with the
+// newest match (ties by identifier), or false when none did.
+func (w *PatternCompositionWatcher) anyOfChoice(group map[PatternIdentifier]struct{}) (PatternIdentifier, bool) {
+	var chosen PatternIdentifier
+	var newest time.Time
+	var found bool
+	for pid := range group {
+		matches := w.recent(pid)
+		if len(matches) == 0 || len(matches) < w.Spec.minOccurrences(pid) {
+			continue
+		}
+		at := matches[len(matches)-1].At
+		if !found || at.After(newest) || (at.Equal(newest) && pid.String() < chosen.String()) {
+			chosen, newest, found = pid, at, true
+		}
+	}
+	return chosen, found
+}
+
+// sourcePatternIDs returns the DerivedIDs of patterns, sorted.
+func sourcePatternIDs(patterns []PatternMatch) []EventID {
+	ids := make([]EventID, 0, len(patterns))
+	for _, p := range patterns {
+		ids = append(ids, p.DerivedID)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
+	return ids
+}
+
+// contributingRuleIDs returns the distinct RuleIDs of patterns, sorted.
+func contributingRuleIDs(patterns []PatternMatch) []string {
+	seen := make(map[string]bool, len(patterns))
+	ids := make([]string, 0, len(patterns))
+	for _, p := range patterns {
+		if p.RuleID == "" || seen[p.RuleID] {
+			continue
+		}
+		seen[p.RuleID] = true
+		ids = append(ids, p.RuleID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Reset clears the composition state, e.g. after an operator acknowledged the alert:
+// the matches of every spec pattern (required, any-of and forbidden) and the cooldown.
+// FiredSet is kept. With a shared Store the matches are gone for all its watchers.
+func (w *PatternCompositionWatcher) Reset() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.resetLocked()
+}
+
+// ResetPattern clears the matches recorded under pid only.
+func (w *PatternCompositionWatcher) ResetPattern(pid PatternIdentifier) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.Store.Remove(pid)
 }
 
-// resetCounts resets pattern counts (call after composition is recognized if desired)
-func (w *PatternCompositionWatcher) resetCounts() {
+// OnPatternDecayed implements PatternDecayListener: the decayed motif is no longer hot,
+// so its recorded matches stop counting towards (or suppressing) the composition.
+// Matches of other motifs under the same identifiers are kept.
+func (w *PatternCompositionWatcher) OnPatternDecayed(key LineageKey) {
+	if w == nil {
+		return
+	}
+
+	// Checked before locking, as in OnPatternRepeated
+	required, forbidden := w.identify(PatternMatch{Key: key})
+	if len(required) == 0 && len(forbidden) == 0 {
+		return
+	}
+
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	for pid := range w.recentMatches {
-		w.recentMatches[pid] = nil
-		w.patternCounts[pid] = 0
+	for _, pid := range append(required, forbidden...) {
+		matches := w.Store.Recent(pid, 0)
+		kept := make([]PatternMatch, 0, len(matches))
+		for _, m := range matches {
+			if m.Key != key {
+				kept = append(kept, m)
+			}
+		}
+		if len(kept) == len(matches) {
+			continue
+		}
+		w.Store.Remove(pid)
+		for _, m := range kept {
+			w.Store.Add(pid, m)
+		}
 	}
 }
 
+// resetLocked implements Reset; callers must hold w.mu.
+func (w *PatternCompositionWatcher) resetLocked() {
+	specPatterns := append([]map[PatternIdentifier]struct{}{w.Spec.RequiredPatterns, w.Spec.ForbiddenPatterns}, w.Spec.AnyOfPatterns...)
+	for _, patterns := range specPatterns {
+		for pid := range patterns {
+			w.Store.Remove(pid)
+		}
+	}
+	w.lastRecognized = time.Time{}
+}
+
 // CompositePatternListener forwards pattern matches to a composition watcher
 // This allows PatternWatcher to send matches to PatternCompositionWatcher
 type CompositePatternListener struct {
@@ -290,6 +860,24 @@ type CompositePatternListener struct {
 	baseListener PatternListener // Optional: forward to another listener too
 }
 
+// Flush implements Flusher for every composition watcher and the base listener.
+func (l *CompositePatternListener) Flush(ctx context.Context) error {
+	l.mu.Lock()
+	watchers := make([]*PatternCompositionWatcher, len(l.watchers))
+	copy(watchers, l.watchers)
+	base := l.baseListener
+	l.mu.Unlock()
+
+	var errs []error
+	for _, w := range watchers {
+		errs = append(errs, w.Flush(ctx))
+	}
+	if f, ok := base.(Flusher); ok {
+		errs = append(errs, f.Flush(ctx))
+	}
+	return errors.Join(errs...)
+}
+
 // NewCompositePatternListener creates a listener that forwards to composition watchers
 func NewCompositePatternListener(baseListener PatternListener) *CompositePatternListener {
 	return &CompositePatternListener{
@@ -298,6 +886,32 @@ func NewCompositePatternListener(baseListener PatternListener) *CompositePattern
 	}
 }
 
+// CompositionWatchers returns the composition watchers matches are forwarded to.
+func (l *CompositePatternListener) CompositionWatchers() []*PatternCompositionWatcher {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	watchers := make([]*PatternCompositionWatcher, len(l.watchers))
+	copy(watchers, l.watchers)
+	return watchers
+}
+
+// OnPatternDecayed implements PatternDecayListener by forwarding to the base listener,
+// if it listens for decay, and to all composition watchers.
+func (l *CompositePatternListener) OnPatternDecayed(key LineageKey) {
+	l.mu.Lock()
+	watchers := make([]*PatternCompositionWatcher, len(l.watchers))
+	copy(watchers, l.watchers)
+	base := l.baseListener
+	l.mu.Unlock()
+
+	if d, ok := base.(PatternDecayListener); ok {
+		d.OnPatternDecayed(key)
+	}
+	for _, watcher := range watchers {
+		watcher.OnPatternDecayed(key)
+	}
+}
+
 // AddCompositionWatcher adds a composition watcher to receive pattern matches
 func (l *CompositePatternListener) AddCompositionWatcher(watcher *PatternCompositionWatcher) {
 	l.mu.Lock()
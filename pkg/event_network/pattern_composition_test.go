@@ -2,6 +2,8 @@
 package event_network
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"sync"
 	"testing"
@@ -128,6 +130,15 @@ func TestPatternCompositionWatcher_BasicComposition(t *testing.T) {
 	require.Equal(t, NaturalDisasterWarningSystem, composition.DerivedEvent.EventDomain)
 	require.Len(t, composition.Patterns, 2)
 	require.Equal(t, "cross-domain-catastrophe", composition.DerivedEvent.Properties["composition_id"])
+	require.Equal(t, []string{"animal-rule", "tremor-rule"}, composition.DerivedEvent.Properties[ContributingRuleIDsProperty])
+
+	stored, err := synapse.GetNetwork().GetByID(composition.DerivedEvent.ID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"animal-rule", "tremor-rule"}, stored.Properties[ContributingRuleIDsProperty])
+
+	// Provenance: the derived IDs of both source patterns
+	require.ElementsMatch(t, []EventID{animalMatch.DerivedID, tremorMatch.DerivedID}, stored.Properties[SourcePatternIDsProperty])
+	require.Equal(t, stored.Properties[SourcePatternIDsProperty], composition.SourcePatternIDs)
 }
 
 func TestPatternCompositionWatcher_TimeWindow(t *testing.T) {
@@ -492,9 +503,7 @@ func TestPatternCompositionWatcher_CleanupOldMatches(t *testing.T) {
 		EventType:   MultipleAnimalUnexpectedBehavior,
 		EventDomain: AnimalObservation,
 	}
-	watcher.recentMatches[pid] = []PatternMatch{oldMatch}
-	watcher.patternCounts[pid] = 1
-	watcher.lastCleanup = baseTime.Add(-2 * time.Minute) // Set last cleanup to 2 minutes ago
+	watcher.Store.Add(pid, oldMatch)
 	watcher.mu.Unlock()
 
 	// Add new match to trigger cleanup
@@ -515,18 +524,43 @@ func TestPatternCompositionWatcher_CleanupOldMatches(t *testing.T) {
 	watcher.OnPatternRepeated(newMatch)
 
 	// Verify old match was cleaned up
-	watcher.mu.RLock()
-	matches := watcher.recentMatches[pid]
-	count := watcher.patternCounts[pid]
-	watcher.mu.RUnlock()
+	matches := watcher.Store.Recent(pid, 0)
 
 	// Should only have the new match
 	require.Len(t, matches, 1)
 	require.Equal(t, baseTime.Unix(), matches[0].At.Unix())
-	require.Equal(t, 1, count)
 }
 
-func TestPatternCompositionWatcher_ResetCounts(t *testing.T) {
+func TestPatternCompositionWatcher_StaleMatchPruned(t *testing.T) {
+	synapse := newTestSynapse(t)
+	listener := &testCompositionListener{}
+
+	animalPID := PatternIdentifier{EventType: MultipleAnimalUnexpectedBehavior, EventDomain: AnimalObservation}
+	spec := newForbiddenPatternSpec()
+	spec.MinOccurrences = map[PatternIdentifier]int{animalPID: 2}
+	watcher := NewPatternCompositionWatcher(spec, synapse, listener)
+
+	// A match from two windows ago is still retained when the fresh ones arrive
+	now := time.Now()
+	stale := newTestPatternMatch(MultipleAnimalUnexpectedBehavior, AnimalObservation, now.Add(-2*time.Hour))
+	watcher.Store.Add(animalPID, stale)
+
+	watcher.OnPatternRepeated(newTestPatternMatch(MultipleAnimalUnexpectedBehavior, AnimalObservation, now))
+	watcher.OnPatternRepeated(newTestPatternMatch(HighFrequencyOfMinorTremors, Geology, now))
+	require.Equal(t, 0, listener.Count(), "the stale match must not count toward MinOccurrences")
+
+	watcher.OnPatternRepeated(newTestPatternMatch(MultipleAnimalUnexpectedBehavior, AnimalObservation, now.Add(time.Minute)))
+	require.Equal(t, 1, listener.Count())
+
+	for _, m := range listener.All()[0].Patterns {
+		require.NotEqual(t, stale.DerivedID, m.DerivedID)
+	}
+	for _, m := range watcher.Store.Recent(animalPID, 0) {
+		require.NotEqual(t, stale.DerivedID, m.DerivedID)
+	}
+}
+
+func TestPatternCompositionWatcher_Reset(t *testing.T) {
 	synapse := newTestSynapse(t)
 	listener := &testCompositionListener{}
 
@@ -566,25 +600,13 @@ func TestPatternCompositionWatcher_ResetCounts(t *testing.T) {
 	watcher.OnPatternRepeated(match)
 
 	// Verify counts are set
-	watcher.mu.RLock()
-	count := watcher.patternCounts[pid]
-	matches := watcher.recentMatches[pid]
-	watcher.mu.RUnlock()
-
-	require.Equal(t, 1, count)
-	require.Len(t, matches, 1)
+	require.Len(t, watcher.Store.Recent(pid, 0), 1)
 
 	// Reset counts
-	watcher.resetCounts()
+	watcher.Reset()
 
 	// Verify counts are reset
-	watcher.mu.RLock()
-	count = watcher.patternCounts[pid]
-	matches = watcher.recentMatches[pid]
-	watcher.mu.RUnlock()
-
-	require.Equal(t, 0, count)
-	require.Nil(t, matches)
+	require.Empty(t, watcher.Store.Recent(pid, 0))
 }
 
 func TestPatternCompositionWatcher_NilSynapse(t *testing.T) {
@@ -737,21 +759,7 @@ func TestPatternCompositionWatcher_EmptyPatternMatches(t *testing.T) {
 
 	watcher := NewPatternCompositionWatcher(spec, synapse, listener)
 
-	// Manually set empty matches to test edge case
-	watcher.mu.Lock()
-	pid1 := PatternIdentifier{
-		EventType:   MultipleAnimalUnexpectedBehavior,
-		EventDomain: AnimalObservation,
-	}
-	pid2 := PatternIdentifier{
-		EventType:   HighFrequencyOfMinorTremors,
-		EventDomain: Geology,
-	}
-	watcher.recentMatches[pid1] = []PatternMatch{} // Empty
-	watcher.recentMatches[pid2] = []PatternMatch{}
-	watcher.patternCounts[pid1] = 0
-	watcher.patternCounts[pid2] = 0
-	watcher.mu.Unlock()
+	// No matches stored for either pattern
 
 	// Try to check composition - should return early
 	watcher.checkComposition(time.Now())
@@ -803,6 +811,23 @@ func TestPatternCompositionWatcher_IngestError(t *testing.T) {
 
 	// Should not create composition due to ingest error
 	require.Equal(t, 0, listener.Count())
+	require.Equal(t, 0, watcher.FiredCount(), "a failed derivation is not counted")
+
+	t.Run("a failed derivation does not start the cooldown", func(t *testing.T) {
+		mockSynapse := &mockSynapseWithError{network: base, ingestError: fmt.Errorf("ingest failed")}
+		spec := spec
+		spec.Cooldown = &TimeWindow{Within: 1, TimeUnit: Hour}
+		listener := &testCompositionListener{}
+		watcher := NewPatternCompositionWatcher(spec, mockSynapse, listener)
+
+		watcher.OnPatternRepeated(match)
+		require.Equal(t, 0, listener.Count())
+
+		mockSynapse.ingestError = nil
+		watcher.OnPatternRepeated(match)
+		require.Equal(t, 1, listener.Count())
+		require.Equal(t, 1, watcher.FiredCount())
+	})
 }
 
 // mockSynapseWithError is a test helper that fails on ingest
@@ -826,3 +851,866 @@ func (m *mockSynapseWithError) RegisterRuleForTypes(eventTypes []EventType, rule
 func (m *mockSynapseWithError) GetNetwork() EventNetwork {
 	return m.network
 }
+
+// newTestPatternMatch builds a PatternMatch for the given derived type/domain at the given time.
+func newTestPatternMatch(eventType EventType, domain EventDomain, at time.Time) PatternMatch {
+	return PatternMatch{
+		Key: LineageKey{
+			DerivedType:   eventType,
+			DerivedDomain: domain,
+			Depth:         4,
+			Sig:           12345,
+		},
+		Occurrence:     2,
+		At:             at,
+		DerivedID:      EventID(uuid.New()),
+		RuleID:         "test-rule",
+		ContributorIDs: []EventID{},
+	}
+}
+
+const systemRecovered = "system_recovered"
+
+func newForbiddenPatternSpec() PatternCompositionSpec {
+	return PatternCompositionSpec{
+		RequiredPatterns: map[PatternIdentifier]struct{}{
+			{EventType: MultipleAnimalUnexpectedBehavior, EventDomain: AnimalObservation}: {},
+			{EventType: HighFrequencyOfMinorTremors, EventDomain: Geology}:                {},
+		},
+		ForbiddenPatterns: map[PatternIdentifier]struct{}{
+			{EventType: systemRecovered, EventDomain: Geology}: {},
+		},
+		TimeWindow: &TimeWindow{
+			Within:   1,
+			TimeUnit: Hour,
+		},
+		DerivedEventTemplate: EventTemplate{
+			EventType:   PotentialNaturalCatastrophic,
+			EventDomain: NaturalDisasterWarningSystem,
+		},
+		CompositionID: "test",
+	}
+}
+
+func TestPatternCompositionWatcher_ForbiddenPatternWithinWindowSuppresses(t *testing.T) {
+	synapse := newTestSynapse(t)
+	listener := &testCompositionListener{}
+	watcher := NewPatternCompositionWatcher(newForbiddenPatternSpec(), synapse, listener)
+
+	baseTime := time.Now()
+
+	// Recovery signal seen 10 minutes before the required patterns complete
+	watcher.OnPatternRepeated(newTestPatternMatch(systemRecovered, Geology, baseTime.Add(-10*time.Minute)))
+	require.Equal(t, 0, listener.Count())
+
+	watcher.OnPatternRepeated(newTestPatternMatch(MultipleAnimalUnexpectedBehavior, AnimalObservation, baseTime))
+	watcher.OnPatternRepeated(newTestPatternMatch(HighFrequencyOfMinorTremors, Geology, baseTime.Add(5*time.Minute)))
+
+	// Otherwise-qualifying composition is blocked by the forbidden pattern
+	require.Equal(t, 0, listener.Count())
+}
+
+func TestPatternCompositionWatcher_ForbiddenPatternOutsideWindowIgnored(t *testing.T) {
+	synapse := newTestSynapse(t)
+	listener := &testCompositionListener{}
+	watcher := NewPatternCompositionWatcher(newForbiddenPatternSpec(), synapse, listener)
+
+	baseTime := time.Now()
+
+	// Recovery signal is older than the 1h window relative to the newest required match
+	watcher.OnPatternRepeated(newTestPatternMatch(systemRecovered, Geology, baseTime.Add(-3*time.Hour)))
+
+	watcher.OnPatternRepeated(newTestPatternMatch(MultipleAnimalUnexpectedBehavior, AnimalObservation, baseTime))
+	watcher.OnPatternRepeated(newTestPatternMatch(HighFrequencyOfMinorTremors, Geology, baseTime.Add(5*time.Minute)))
+
+	require.Equal(t, 1, listener.Count())
+}
+
+func TestPatternCompositionWatcher_ForbiddenPatternWithoutWindow(t *testing.T) {
+	synapse := newTestSynapse(t)
+	listener := &testCompositionListener{}
+	spec := newForbiddenPatternSpec()
+	spec.TimeWindow = nil
+	watcher := NewPatternCompositionWatcher(spec, synapse, listener)
+
+	baseTime := time.Now()
+	watcher.OnPatternRepeated(newTestPatternMatch(MultipleAnimalUnexpectedBehavior, AnimalObservation, baseTime))
+	watcher.OnPatternRepeated(newTestPatternMatch(systemRecovered, Geology, baseTime.Add(-48*time.Hour)))
+	watcher.OnPatternRepeated(newTestPatternMatch(HighFrequencyOfMinorTremors, Geology, baseTime))
+
+	// Without a window, any recorded forbidden match suppresses
+	require.Equal(t, 0, listener.Count())
+
+	watcher.Reset()
+	watcher.OnPatternRepeated(newTestPatternMatch(MultipleAnimalUnexpectedBehavior, AnimalObservation, baseTime))
+	watcher.OnPatternRepeated(newTestPatternMatch(HighFrequencyOfMinorTremors, Geology, baseTime))
+	require.Equal(t, 1, listener.Count())
+}
+
+// recordingObserver captures OnMaterialized calls
+type recordingObserver struct {
+	mu      sync.Mutex
+	derived []Event
+}
+
+func (o *recordingObserver) OnMaterialized(derived Event, contributors []Event, ruleID string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.derived = append(o.derived, derived)
+}
+
+func (o *recordingObserver) ofType(eventType EventType) []Event {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var out []Event
+	for _, ev := range o.derived {
+		if ev.EventType == eventType {
+			out = append(out, ev)
+		}
+	}
+	return out
+}
+
+func TestPatternCompositionWatcher_TriggerRules(t *testing.T) {
+	const escalation = "escalation"
+
+	run := func(t *testing.T, triggerRules *bool) (*SynapseRuntime, *recordingObserver, *testCompositionListener) {
+		synapse := newTestSynapse(t)
+		observer := &recordingObserver{}
+		synapse.PatternWatcher = []PatternObserver{observer}
+		synapse.RegisterRule(PotentialNaturalCatastrophic, NewDeriveEventRule("escalate",
+			NewCondition().IsTypeOf(PotentialNaturalCatastrophic, Conditions{}),
+			EventTemplate{EventType: escalation, EventDomain: NaturalDisasterWarningSystem},
+		))
+
+		listener := &testCompositionListener{}
+		spec := PatternCompositionSpec{
+			RequiredPatterns: map[PatternIdentifier]struct{}{
+				{EventType: MultipleAnimalUnexpectedBehavior, EventDomain: AnimalObservation}: {},
+				{EventType: HighFrequencyOfMinorTremors, EventDomain: Geology}:                {},
+			},
+			DerivedEventTemplate: EventTemplate{
+				EventType:   PotentialNaturalCatastrophic,
+				EventDomain: NaturalDisasterWarningSystem,
+			},
+			CompositionID: "test",
+			TriggerRules:  triggerRules,
+		}
+		watcher := NewPatternCompositionWatcher(spec, synapse, listener)
+
+		now := time.Now()
+		animal, err := synapse.Network.AddEventFull(Event{EventType: MultipleAnimalUnexpectedBehavior, EventDomain: AnimalObservation, Timestamp: now})
+		require.NoError(t, err)
+		tremor, err := synapse.Network.AddEventFull(Event{EventType: HighFrequencyOfMinorTremors, EventDomain: Geology, Timestamp: now})
+		require.NoError(t, err)
+
+		animalMatch := newTestPatternMatch(MultipleAnimalUnexpectedBehavior, AnimalObservation, now)
+		animalMatch.DerivedID = animal.ID
+		tremorMatch := newTestPatternMatch(HighFrequencyOfMinorTremors, Geology, now)
+		tremorMatch.DerivedID = tremor.ID
+
+		watcher.OnPatternRepeated(animalMatch)
+		watcher.OnPatternRepeated(tremorMatch)
+		require.Equal(t, 1, listener.Count())
+		return synapse, observer, listener
+	}
+
+	t.Run("default triggers rules", func(t *testing.T) {
+		synapse, _, _ := run(t, nil)
+		escalations, err := synapse.Network.GetByType(escalation)
+		require.NoError(t, err)
+		require.Len(t, escalations, 1)
+	})
+
+	t.Run("TriggerRules false skips rules but watchers see the event", func(t *testing.T) {
+		skip := false
+		synapse, observer, listener := run(t, &skip)
+
+		escalations, err := synapse.Network.GetByType(escalation)
+		require.NoError(t, err)
+		require.Empty(t, escalations)
+
+		composed := listener.All()[0].DerivedEvent
+		seen := observer.ofType(PotentialNaturalCatastrophic)
+		require.Len(t, seen, 1)
+		require.Equal(t, composed.ID, seen[0].ID)
+
+		// Pattern events are linked as contributors
+		children, err := synapse.Network.Children(composed.ID)
+		require.NoError(t, err)
+		require.Len(t, children, 2)
+	})
+}
+
+func TestPatternCompositionWatcher_ReentrantDerivation(t *testing.T) {
+	const (
+		trigger  EventType = "a"
+		derived  EventType = "x"
+		composed EventType = "composed"
+	)
+
+	// The composed event is itself recognized as a repeated pattern and forwarded back to
+	// the same composition watcher, from inside its own derivation.
+	composite := NewCompositePatternListener(nil)
+	synapse := newSynapseStoringDuplicates([]PatternConfig{{Depth: 1, MinCount: 2, PatternListener: composite}})
+	synapse.RegisterRule(trigger, &cascadeRule{template: EventTemplate{EventType: derived, EventDomain: InfraDomain}})
+
+	skip := false
+	listener := &testCompositionListener{}
+	composite.AddCompositionWatcher(NewPatternCompositionWatcher(PatternCompositionSpec{
+		RequiredPatterns:     map[PatternIdentifier]struct{}{{EventType: derived, EventDomain: InfraDomain}: {}},
+		DerivedEventTemplate: EventTemplate{EventType: composed, EventDomain: InfraDomain},
+		CompositionID:        "reentrant",
+		TriggerRules:         &skip,
+	}, synapse, listener))
+
+	done := make(chan error, 1)
+	go func() {
+		for i := 0; i < 5; i++ {
+			if _, err := synapse.Ingest(Event{EventType: trigger, EventDomain: InfraDomain, Timestamp: time.Now()}); err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("ingest deadlocked on re-entry into the composition watcher")
+	}
+	require.Positive(t, listener.Count())
+}
+
+func TestPatternCompositionWatcher_DecisionLog(t *testing.T) {
+	synapse := newTestSynapse(t)
+	listener := &testCompositionListener{}
+
+	spec := PatternCompositionSpec{
+		RequiredPatterns: map[PatternIdentifier]struct{}{
+			{EventType: MultipleAnimalUnexpectedBehavior, EventDomain: AnimalObservation}: {},
+			{EventType: HighFrequencyOfMinorTremors, EventDomain: Geology}:                {},
+		},
+		TimeWindow: &TimeWindow{
+			Within:   1,
+			TimeUnit: Hour,
+		},
+		DerivedEventTemplate: EventTemplate{
+			EventType:   PotentialNaturalCatastrophic,
+			EventDomain: NaturalDisasterWarningSystem,
+		},
+		CompositionID: "audited",
+	}
+
+	var log bytes.Buffer
+	watcher := NewPatternCompositionWatcher(spec, synapse, listener)
+	watcher.DecisionLog = &log
+
+	baseTime := time.Now()
+	watcher.OnPatternRepeated(newTestPatternMatch(MultipleAnimalUnexpectedBehavior, AnimalObservation, baseTime))
+	watcher.OnPatternRepeated(newTestPatternMatch(HighFrequencyOfMinorTremors, Geology, baseTime.Add(72*time.Minute)))
+	require.Equal(t, 0, listener.Count())
+
+	decode := func() []CompositionDecision {
+		var out []CompositionDecision
+		dec := json.NewDecoder(bytes.NewReader(log.Bytes()))
+		for dec.More() {
+			var d CompositionDecision
+			require.NoError(t, dec.Decode(&d))
+			out = append(out, d)
+		}
+		return out
+	}
+
+	decisions := decode()
+	require.Len(t, decisions, 2)
+
+	require.False(t, decisions[0].Fired)
+	require.Equal(t, "pattern geology/high_frequency_of_minor_tremors has 0 of 1 required occurrences", decisions[0].Reason)
+
+	require.False(t, decisions[1].Fired)
+	require.Equal(t, "window exceeded by 12m", decisions[1].Reason)
+	require.Equal(t, "audited", decisions[1].CompositionID)
+	require.Equal(t, map[string]int{
+		"animal_observation/multiple_animal_unexpected_behavior": 1,
+		"geology/high_frequency_of_minor_tremors":                1,
+	}, decisions[1].Counts)
+
+	// A qualifying match now fires and is logged as such
+	watcher.OnPatternRepeated(newTestPatternMatch(MultipleAnimalUnexpectedBehavior, AnimalObservation, baseTime.Add(70*time.Minute)))
+	require.Equal(t, 1, listener.Count())
+
+	decisions = decode()
+	require.Len(t, decisions, 3)
+	require.True(t, decisions[2].Fired)
+}
+
+func TestFormatDuration(t *testing.T) {
+	require.Equal(t, "12m", formatDuration(12*time.Minute))
+	require.Equal(t, "1h", formatDuration(time.Hour))
+	require.Equal(t, "1h30m", formatDuration(90*time.Minute))
+	require.Equal(t, "45s", formatDuration(45*time.Second))
+}
+
+func TestPatternCompositionWatcher_DepthSpecificPatterns(t *testing.T) {
+	synapse := newTestSynapse(t)
+	listener := &testCompositionListener{}
+	spec := PatternCompositionSpec{
+		RequiredPatterns: map[PatternIdentifier]struct{}{
+			{EventType: HighFrequencyOfMinorTremors, EventDomain: Geology, Depth: 2}: {},
+			{EventType: HighFrequencyOfMinorTremors, EventDomain: Geology, Depth: 4}: {},
+		},
+		TimeWindow: &TimeWindow{Within: 1, TimeUnit: Hour},
+		DerivedEventTemplate: EventTemplate{
+			EventType:   PotentialNaturalCatastrophic,
+			EventDomain: NaturalDisasterWarningSystem,
+		},
+		CompositionID: "layered",
+	}
+	watcher := NewPatternCompositionWatcher(spec, synapse, listener)
+
+	atDepth := func(depth int, at time.Time) PatternMatch {
+		m := newTestPatternMatch(HighFrequencyOfMinorTremors, Geology, at)
+		m.Key.Depth = depth
+		return m
+	}
+
+	baseTime := time.Now()
+
+	// Same type, other depths: ignored
+	watcher.OnPatternRepeated(atDepth(3, baseTime))
+	watcher.OnPatternRepeated(atDepth(4, baseTime))
+	watcher.OnPatternRepeated(atDepth(5, baseTime))
+	require.Equal(t, 0, listener.Count())
+	require.Len(t, watcher.Store.Recent(PatternIdentifier{EventType: HighFrequencyOfMinorTremors, EventDomain: Geology, Depth: 4}, 0), 1)
+
+	// The depth-2 layer completes the composition
+	watcher.OnPatternRepeated(atDepth(2, baseTime.Add(time.Minute)))
+	require.Equal(t, 1, listener.Count())
+
+	patterns := listener.All()[0].Patterns
+	require.Len(t, patterns, 2)
+	depths := []int{patterns[0].Key.Depth, patterns[1].Key.Depth}
+	require.ElementsMatch(t, []int{2, 4}, depths)
+}
+
+func TestPatternCompositionWatcher_AnyDepthPatternAcceptsAllDepths(t *testing.T) {
+	synapse := newTestSynapse(t)
+	listener := &testCompositionListener{}
+	spec := newForbiddenPatternSpec()
+	spec.ForbiddenPatterns = nil
+	watcher := NewPatternCompositionWatcher(spec, synapse, listener)
+
+	baseTime := time.Now()
+	first := newTestPatternMatch(MultipleAnimalUnexpectedBehavior, AnimalObservation, baseTime)
+	first.Key.Depth = 2
+	second := newTestPatternMatch(HighFrequencyOfMinorTremors, Geology, baseTime)
+	second.Key.Depth = 7
+
+	watcher.OnPatternRepeated(first)
+	watcher.OnPatternRepeated(second)
+	require.Equal(t, 1, listener.Count())
+}
+
+func TestPatternIdentifier_String(t *testing.T) {
+	require.Equal(t, "geology/tremors", PatternIdentifier{EventType: "tremors", EventDomain: "geology"}.String())
+	require.Equal(t, "geology/tremors@2", PatternIdentifier{EventType: "tremors", EventDomain: "geology", Depth: 2}.String())
+}
+
+func TestEvaluateCompositionSpec_DecisionTimeline(t *testing.T) {
+	spec := PatternCompositionSpec{
+		RequiredPatterns: map[PatternIdentifier]struct{}{
+			{EventType: MultipleAnimalUnexpectedBehavior, EventDomain: AnimalObservation}: {},
+			{EventType: HighFrequencyOfMinorTremors, EventDomain: Geology}:                {},
+		},
+		ForbiddenPatterns: map[PatternIdentifier]struct{}{
+			{EventType: systemRecovered, EventDomain: Geology}: {},
+		},
+		TimeWindow: &TimeWindow{
+			Within:   1,
+			TimeUnit: Hour,
+		},
+		DerivedEventTemplate: EventTemplate{
+			EventType:   PotentialNaturalCatastrophic,
+			EventDomain: NaturalDisasterWarningSystem,
+		},
+		CompositionID: "dry-run",
+	}
+
+	baseTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	// Deliberately out of order: the evaluator replays by timestamp.
+	matches := []PatternMatch{
+		newTestPatternMatch(HighFrequencyOfMinorTremors, Geology, baseTime.Add(30*time.Minute)),
+		newTestPatternMatch(MultipleAnimalUnexpectedBehavior, AnimalObservation, baseTime),
+		newTestPatternMatch("unrelated", "elsewhere", baseTime.Add(35*time.Minute)),
+		newTestPatternMatch(systemRecovered, Geology, baseTime.Add(40*time.Minute)),
+		newTestPatternMatch(HighFrequencyOfMinorTremors, Geology, baseTime.Add(50*time.Minute)),
+	}
+
+	decisions := EvaluateCompositionSpec(spec, matches, nil)
+	require.Len(t, decisions, 3, "only required matches produce decisions")
+
+	require.Equal(t, baseTime, decisions[0].At)
+	require.False(t, decisions[0].Fired)
+	require.Equal(t, "pattern geology/high_frequency_of_minor_tremors has 0 of 1 required occurrences", decisions[0].Reason)
+
+	require.Equal(t, baseTime.Add(30*time.Minute), decisions[1].At)
+	require.True(t, decisions[1].Fired)
+	require.Equal(t, "all required patterns matched", decisions[1].Reason)
+	require.Equal(t, "dry-run", decisions[1].CompositionID)
+
+	require.Equal(t, baseTime.Add(50*time.Minute), decisions[2].At)
+	require.False(t, decisions[2].Fired)
+	require.Equal(t, "suppressed by forbidden pattern geology/system_recovered", decisions[2].Reason)
+	require.Equal(t, map[string]int{
+		"animal_observation/multiple_animal_unexpected_behavior": 1,
+		"geology/high_frequency_of_minor_tremors":                2,
+	}, decisions[2].Counts)
+
+	// The input slice is left untouched.
+	require.Equal(t, baseTime.Add(30*time.Minute), matches[0].At)
+}
+
+func TestEvaluateCompositionSpec_UsesClock(t *testing.T) {
+	spec := PatternCompositionSpec{
+		RequiredPatterns: map[PatternIdentifier]struct{}{
+			{EventType: MultipleAnimalUnexpectedBehavior, EventDomain: AnimalObservation}: {},
+		},
+		TimeWindow: &TimeWindow{
+			Within:   1,
+			TimeUnit: Hour,
+		},
+		CompositionID: "clocked",
+	}
+
+	baseTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	now := baseTime.Add(90 * time.Minute)
+	clock := ClockFunc(func() time.Time { return now })
+
+	// Decisions are stamped with the clock, not the match time.
+	spec.TimeWindow.Within = 2
+	decisions := EvaluateCompositionSpec(spec, []PatternMatch{
+		newTestPatternMatch(MultipleAnimalUnexpectedBehavior, AnimalObservation, baseTime),
+	}, clock)
+	require.Len(t, decisions, 1)
+	require.Equal(t, now, decisions[0].At)
+	require.True(t, decisions[0].Fired)
+
+	// With a one hour window the match is already stale at the clock's "now" and is cleaned up.
+	spec.TimeWindow.Within = 1
+	decisions = EvaluateCompositionSpec(spec, []PatternMatch{
+		newTestPatternMatch(MultipleAnimalUnexpectedBehavior, AnimalObservation, baseTime),
+	}, clock)
+	require.Len(t, decisions, 1)
+	require.False(t, decisions[0].Fired)
+	require.Equal(t, "pattern animal_observation/multiple_animal_unexpected_behavior has 0 of 1 required occurrences", decisions[0].Reason)
+}
+
+func TestPatternCompositionWatcher_EdgesLabeledPatternComposition(t *testing.T) {
+	synapse := newTestSynapse(t)
+	listener := &testCompositionListener{}
+
+	spec := PatternCompositionSpec{
+		RequiredPatterns: map[PatternIdentifier]struct{}{
+			{EventType: MultipleAnimalUnexpectedBehavior, EventDomain: AnimalObservation}: {},
+			{EventType: HighFrequencyOfMinorTremors, EventDomain: Geology}:                {},
+		},
+		DerivedEventTemplate: EventTemplate{
+			EventType:   PotentialNaturalCatastrophic,
+			EventDomain: NaturalDisasterWarningSystem,
+		},
+		CompositionID: "labeled",
+	}
+	watcher := NewPatternCompositionWatcher(spec, synapse, listener)
+
+	now := time.Now()
+	animalID, err := synapse.GetNetwork().AddEvent(Event{EventType: MultipleAnimalUnexpectedBehavior, EventDomain: AnimalObservation, Timestamp: now})
+	require.NoError(t, err)
+	tremorID, err := synapse.GetNetwork().AddEvent(Event{EventType: HighFrequencyOfMinorTremors, EventDomain: Geology, Timestamp: now})
+	require.NoError(t, err)
+
+	animalMatch := newTestPatternMatch(MultipleAnimalUnexpectedBehavior, AnimalObservation, now)
+	animalMatch.DerivedID = animalID
+	tremorMatch := newTestPatternMatch(HighFrequencyOfMinorTremors, Geology, now)
+	tremorMatch.DerivedID = tremorID
+
+	watcher.OnPatternRepeated(animalMatch)
+	watcher.OnPatternRepeated(tremorMatch)
+	require.Equal(t, 1, listener.Count())
+	derived := listener.All()[0].DerivedEvent
+
+	in, err := synapse.GetNetwork().InEdges(derived.ID)
+	require.NoError(t, err)
+	require.Len(t, in, 2)
+	for _, edge := range in {
+		require.Equal(t, "pattern_composition", edge.Relation)
+	}
+
+	out, err := synapse.GetNetwork().OutEdges(animalID)
+	require.NoError(t, err)
+	require.Equal(t, []Edge{{From: animalID, To: derived.ID, Relation: "pattern_composition"}}, out)
+}
+
+func TestPatternCompositionWatcher_WindowAlignment(t *testing.T) {
+	spec := func(alignment WindowAlignment) PatternCompositionSpec {
+		return PatternCompositionSpec{
+			RequiredPatterns: map[PatternIdentifier]struct{}{
+				{EventType: MultipleAnimalUnexpectedBehavior, EventDomain: AnimalObservation}: {},
+				{EventType: HighFrequencyOfMinorTremors, EventDomain: Geology}:                {},
+			},
+			WindowAlignment: alignment,
+			DerivedEventTemplate: EventTemplate{
+				EventType:   PotentialNaturalCatastrophic,
+				EventDomain: NaturalDisasterWarningSystem,
+			},
+			CompositionID: "same-day",
+		}
+	}
+	last := func(alignment WindowAlignment, animals, tremors time.Time) CompositionDecision {
+		decisions := EvaluateCompositionSpec(spec(alignment), []PatternMatch{
+			newTestPatternMatch(MultipleAnimalUnexpectedBehavior, AnimalObservation, animals),
+			newTestPatternMatch(HighFrequencyOfMinorTremors, Geology, tremors),
+		}, nil)
+		require.Len(t, decisions, 2)
+		return decisions[1]
+	}
+	day := time.Date(2026, 4, 25, 0, 0, 0, 0, time.UTC)
+
+	t.Run("same calendar day fires", func(t *testing.T) {
+		d := last(CalendarDay, day.Add(8*time.Hour), day.Add(22*time.Hour+30*time.Minute))
+		require.True(t, d.Fired, d.Reason)
+	})
+
+	t.Run("straddling midnight does not fire", func(t *testing.T) {
+		d := last(CalendarDay, day.Add(-30*time.Minute), day.Add(15*time.Minute))
+		require.False(t, d.Fired, "the previous day's match was dropped at midnight")
+
+		// A rolling composition has no calendar boundary
+		require.True(t, last(Rolling, day.Add(-30*time.Minute), day.Add(15*time.Minute)).Fired)
+	})
+
+	t.Run("calendar hour", func(t *testing.T) {
+		require.True(t, last(CalendarHour, day.Add(10*time.Hour+5*time.Minute), day.Add(10*time.Hour+55*time.Minute)).Fired)
+
+		d := last(CalendarHour, day.Add(10*time.Hour+55*time.Minute), day.Add(11*time.Hour+5*time.Minute))
+		require.False(t, d.Fired)
+	})
+
+	t.Run("bucket must still be current", func(t *testing.T) {
+		clock := ClockFunc(func() time.Time { return day.Add(24*time.Hour + time.Minute) })
+		decisions := EvaluateCompositionSpec(spec(CalendarDay), []PatternMatch{
+			newTestPatternMatch(MultipleAnimalUnexpectedBehavior, AnimalObservation, day.Add(23*time.Hour)),
+			newTestPatternMatch(HighFrequencyOfMinorTremors, Geology, day.Add(23*time.Hour+50*time.Minute)),
+		}, clock)
+		require.Len(t, decisions, 2)
+		require.False(t, decisions[1].Fired)
+	})
+
+	t.Run("live watcher drops the previous bucket", func(t *testing.T) {
+		var log bytes.Buffer
+		listener := &testCompositionListener{}
+		watcher := NewPatternCompositionWatcher(spec(CalendarDay), nil, listener)
+		watcher.DecisionLog = &log
+
+		// Every evaluation prunes earlier buckets, so the previous day's match never counts
+		watcher.OnPatternRepeated(newTestPatternMatch(MultipleAnimalUnexpectedBehavior, AnimalObservation, day.Add(-30*time.Minute)))
+		watcher.OnPatternRepeated(newTestPatternMatch(HighFrequencyOfMinorTremors, Geology, day.Add(15*time.Minute)))
+		require.Equal(t, 0, listener.Count())
+
+		var decision CompositionDecision
+		dec := json.NewDecoder(&log)
+		for dec.More() {
+			require.NoError(t, dec.Decode(&decision))
+		}
+		require.False(t, decision.Fired)
+		require.Equal(t, "pattern animal_observation/multiple_animal_unexpected_behavior has 0 of 1 required occurrences", decision.Reason)
+	})
+}
+
+func TestPatternCompositionWatcher_FiredSetAcrossRestart(t *testing.T) {
+	synapse := newTestSynapse(t)
+	now := time.Now()
+	animal := newTestPatternMatch(MultipleAnimalUnexpectedBehavior, AnimalObservation, now)
+	tremor := newTestPatternMatch(HighFrequencyOfMinorTremors, Geology, now)
+
+	derivedCount := func() int {
+		events, err := synapse.GetNetwork().GetByType(PotentialNaturalCatastrophic)
+		require.NoError(t, err)
+		return len(events)
+	}
+
+	first := &testCompositionListener{}
+	watcher := NewPatternCompositionWatcher(newForbiddenPatternSpec(), synapse, first)
+	watcher.FiredSet = NewInMemoryFiredSet()
+	watcher.OnPatternRepeated(animal)
+	watcher.OnPatternRepeated(tremor)
+	require.Equal(t, 1, first.Count())
+	require.Equal(t, 1, derivedCount())
+
+	// Persist the fired set, then restore it into a fresh watcher (as after a restart).
+	persisted, err := json.Marshal(watcher.FiredSet)
+	require.NoError(t, err)
+	restored, err := LoadFiredSetJSON(bytes.NewReader(persisted))
+	require.NoError(t, err)
+
+	var log bytes.Buffer
+	replayed := &testCompositionListener{}
+	restarted := NewPatternCompositionWatcher(newForbiddenPatternSpec(), synapse, replayed)
+	restarted.FiredSet = restored
+	restarted.DecisionLog = &log
+	restarted.OnPatternRepeated(animal)
+	restarted.OnPatternRepeated(tremor)
+	require.Zero(t, replayed.Count(), "same matches derive at most once")
+	require.Equal(t, 1, derivedCount())
+	require.Contains(t, log.String(), "already fired for these matches")
+
+	// A new combination still derives.
+	restarted.OnPatternRepeated(newTestPatternMatch(HighFrequencyOfMinorTremors, Geology, now))
+	require.Equal(t, 1, replayed.Count())
+	require.Equal(t, 2, derivedCount())
+
+	// Without a fired set the replay derives again.
+	again := NewPatternCompositionWatcher(newForbiddenPatternSpec(), synapse, &testCompositionListener{})
+	again.OnPatternRepeated(animal)
+	again.OnPatternRepeated(tremor)
+	require.Equal(t, 3, derivedCount())
+}
+
+func TestPatternCompositionWatcher_AnyOfPatterns(t *testing.T) {
+	const (
+		governanceActionRequired = "governance_action_required"
+		externalIncident         = "external_incident"
+		internalIncident         = "internal_incident"
+		governanceDomain         = EventDomain("governance")
+	)
+	spec := func() PatternCompositionSpec {
+		return PatternCompositionSpec{
+			RequiredPatterns: map[PatternIdentifier]struct{}{
+				{EventType: governanceActionRequired, EventDomain: governanceDomain}: {},
+			},
+			AnyOfPatterns: []map[PatternIdentifier]struct{}{{
+				{EventType: externalIncident, EventDomain: governanceDomain}: {},
+				{EventType: internalIncident, EventDomain: governanceDomain}: {},
+			}},
+			TimeWindow: &TimeWindow{Within: 1, TimeUnit: Hour},
+			DerivedEventTemplate: EventTemplate{
+				EventType:   PotentialNaturalCatastrophic,
+				EventDomain: NaturalDisasterWarningSystem,
+			},
+			CompositionID: "governance-escalation",
+		}
+	}
+	now := time.Now()
+
+	for _, incident := range []EventType{externalIncident, internalIncident} {
+		t.Run(string(incident)+" satisfies the group", func(t *testing.T) {
+			listener := &testCompositionListener{}
+			watcher := NewPatternCompositionWatcher(spec(), newTestSynapse(t), listener)
+
+			watcher.OnPatternRepeated(newTestPatternMatch(governanceActionRequired, governanceDomain, now))
+			require.Zero(t, listener.Count(), "the any-of group is still open")
+
+			watcher.OnPatternRepeated(newTestPatternMatch(incident, governanceDomain, now))
+			require.Equal(t, 1, listener.Count())
+			require.Len(t, listener.All()[0].Patterns, 2)
+			require.Equal(t, incident, listener.All()[0].Patterns[1].Key.DerivedType)
+		})
+	}
+
+	t.Run("alternatives alone do not fire", func(t *testing.T) {
+		var log bytes.Buffer
+		listener := &testCompositionListener{}
+		watcher := NewPatternCompositionWatcher(spec(), newTestSynapse(t), listener)
+		watcher.DecisionLog = &log
+
+		watcher.OnPatternRepeated(newTestPatternMatch(externalIncident, governanceDomain, now))
+		watcher.OnPatternRepeated(newTestPatternMatch(internalIncident, governanceDomain, now))
+		require.Zero(t, listener.Count())
+		require.Contains(t, log.String(), "governance_action_required")
+	})
+
+	t.Run("alternative outside the window does not count", func(t *testing.T) {
+		listener := &testCompositionListener{}
+		watcher := NewPatternCompositionWatcher(spec(), newTestSynapse(t), listener)
+
+		watcher.OnPatternRepeated(newTestPatternMatch(externalIncident, governanceDomain, now.Add(-3*time.Hour)))
+		watcher.OnPatternRepeated(newTestPatternMatch(governanceActionRequired, governanceDomain, now))
+		require.Zero(t, listener.Count())
+	})
+
+	t.Run("replayed through EvaluateCompositionSpec", func(t *testing.T) {
+		decisions := EvaluateCompositionSpec(spec(), []PatternMatch{
+			newTestPatternMatch(governanceActionRequired, governanceDomain, now),
+			newTestPatternMatch(internalIncident, governanceDomain, now.Add(time.Minute)),
+		}, nil)
+		require.Len(t, decisions, 2)
+		require.False(t, decisions[0].Fired)
+		require.Equal(t, "no pattern of any-of group 1 has its required occurrences", decisions[0].Reason)
+		require.True(t, decisions[1].Fired)
+		require.Equal(t, 1, decisions[1].Counts[PatternIdentifier{EventType: internalIncident, EventDomain: governanceDomain}.String()])
+	})
+}
+
+func TestPatternCompositionWatcher_MinTotalOccurrences(t *testing.T) {
+	spec := newForbiddenPatternSpec()
+	spec.MinTotalOccurrences = 5
+	now := time.Now()
+
+	var log bytes.Buffer
+	listener := &testCompositionListener{}
+	watcher := NewPatternCompositionWatcher(spec, newTestSynapse(t), listener)
+	watcher.DecisionLog = &log
+
+	// Each pattern meets its minimum of 1, but 4 signals are below the total of 5.
+	for i := 0; i < 2; i++ {
+		watcher.OnPatternRepeated(newTestPatternMatch(MultipleAnimalUnexpectedBehavior, AnimalObservation, now))
+		watcher.OnPatternRepeated(newTestPatternMatch(HighFrequencyOfMinorTremors, Geology, now))
+	}
+	require.Zero(t, listener.Count())
+	require.Contains(t, log.String(), "composition has 4 of 5 required total occurrences")
+
+	watcher.OnPatternRepeated(newTestPatternMatch(HighFrequencyOfMinorTremors, Geology, now))
+	require.Equal(t, 1, listener.Count())
+
+	t.Run("only occurrences within the window count", func(t *testing.T) {
+		var log bytes.Buffer
+		listener := &testCompositionListener{}
+		watcher := NewPatternCompositionWatcher(spec, newTestSynapse(t), listener)
+		watcher.DecisionLog = &log
+
+		watcher.OnPatternRepeated(newTestPatternMatch(MultipleAnimalUnexpectedBehavior, AnimalObservation, now.Add(-3*time.Hour)))
+		for i := 0; i < 2; i++ {
+			watcher.OnPatternRepeated(newTestPatternMatch(MultipleAnimalUnexpectedBehavior, AnimalObservation, now))
+			watcher.OnPatternRepeated(newTestPatternMatch(HighFrequencyOfMinorTremors, Geology, now))
+		}
+		require.Zero(t, listener.Count())
+		require.Contains(t, log.String(), "composition has 4 of 5 required total occurrences")
+	})
+}
+
+func TestPatternCompositionWatcher_Cooldown(t *testing.T) {
+	spec := newForbiddenPatternSpec()
+	spec.Cooldown = &TimeWindow{Within: 1, TimeUnit: Hour}
+
+	synapse := newTestSynapse(t)
+	listener := &testCompositionListener{}
+	watcher := NewPatternCompositionWatcher(spec, synapse, listener)
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		watcher.OnPatternRepeated(newTestPatternMatch(MultipleAnimalUnexpectedBehavior, AnimalObservation, now))
+		watcher.OnPatternRepeated(newTestPatternMatch(HighFrequencyOfMinorTremors, Geology, now))
+	}
+	require.Equal(t, 1, listener.Count())
+	derived, err := synapse.GetNetwork().GetByType(PotentialNaturalCatastrophic)
+	require.NoError(t, err)
+	require.Len(t, derived, 1, "back-to-back satisfying matches derive once during cooldown")
+
+	t.Run("derives again once the cooldown elapsed", func(t *testing.T) {
+		decisions := EvaluateCompositionSpec(spec, []PatternMatch{
+			newTestPatternMatch(MultipleAnimalUnexpectedBehavior, AnimalObservation, now),
+			newTestPatternMatch(HighFrequencyOfMinorTremors, Geology, now),
+			newTestPatternMatch(HighFrequencyOfMinorTremors, Geology, now.Add(20*time.Minute)),
+			newTestPatternMatch(MultipleAnimalUnexpectedBehavior, AnimalObservation, now.Add(61*time.Minute)),
+			newTestPatternMatch(HighFrequencyOfMinorTremors, Geology, now.Add(61*time.Minute)),
+		}, nil)
+
+		var fired []bool
+		for _, d := range decisions {
+			fired = append(fired, d.Fired)
+		}
+		require.Equal(t, []bool{false, true, false, true, false}, fired)
+		require.Equal(t, "cooling down for 40m", decisions[2].Reason)
+	})
+}
+
+func TestPatternCompositionWatcher_ResetPattern(t *testing.T) {
+	spec := newForbiddenPatternSpec()
+	spec.Cooldown = &TimeWindow{Within: 1, TimeUnit: Hour}
+	listener := &testCompositionListener{}
+	watcher := NewPatternCompositionWatcher(spec, newTestSynapse(t), listener)
+
+	animal := PatternIdentifier{EventType: MultipleAnimalUnexpectedBehavior, EventDomain: AnimalObservation}
+	tremor := PatternIdentifier{EventType: HighFrequencyOfMinorTremors, EventDomain: Geology}
+	recovered := PatternIdentifier{EventType: systemRecovered, EventDomain: Geology}
+	now := time.Now()
+
+	watcher.OnPatternRepeated(newTestPatternMatch(animal.EventType, animal.EventDomain, now))
+	watcher.OnPatternRepeated(newTestPatternMatch(animal.EventType, animal.EventDomain, now))
+	watcher.OnPatternRepeated(newTestPatternMatch(tremor.EventType, tremor.EventDomain, now))
+	require.Equal(t, 1, listener.Count())
+
+	watcher.ResetPattern(animal)
+	require.Empty(t, watcher.Store.Recent(animal, 0))
+	require.Len(t, watcher.Store.Recent(tremor, 0), 1, "other identifiers are untouched")
+
+	watcher.OnPatternRepeated(newTestPatternMatch(recovered.EventType, recovered.EventDomain, now.Add(-48*time.Hour)))
+	watcher.Reset()
+	for _, pid := range []PatternIdentifier{animal, tremor, recovered} {
+		require.Empty(t, watcher.Store.Recent(pid, 0))
+	}
+
+	// Reset also ends the cooldown, so the composition can fire again right away.
+	watcher.OnPatternRepeated(newTestPatternMatch(animal.EventType, animal.EventDomain, now))
+	watcher.OnPatternRepeated(newTestPatternMatch(tremor.EventType, tremor.EventDomain, now))
+	require.Equal(t, 2, listener.Count())
+}
+
+func TestJSONAuditListener(t *testing.T) {
+	synapse := newTestSynapse(t)
+	var log bytes.Buffer
+	watcher := NewPatternCompositionWatcher(newForbiddenPatternSpec(), synapse, NewJSONAuditListener(&log))
+
+	now := time.Now()
+	watcher.OnPatternRepeated(newTestPatternMatch(MultipleAnimalUnexpectedBehavior, AnimalObservation, now))
+	watcher.OnPatternRepeated(newTestPatternMatch(HighFrequencyOfMinorTremors, Geology, now))
+
+	require.Contains(t, log.String(), `"composition_id":"test"`)
+	require.Contains(t, log.String(), `"derived_type":"`+PotentialNaturalCatastrophic+`"`)
+
+	var record CompositionAuditRecord
+	require.NoError(t, json.Unmarshal(log.Bytes(), &record))
+	require.ElementsMatch(t, []string{
+		AnimalObservation + "/" + MultipleAnimalUnexpectedBehavior,
+		Geology + "/" + HighFrequencyOfMinorTremors,
+	}, record.PatternTypes)
+	require.NotEqual(t, EventID{}, record.DerivedEventID)
+
+	derived, err := synapse.GetNetwork().GetByType(PotentialNaturalCatastrophic)
+	require.NoError(t, err)
+	require.Len(t, derived, 1)
+	require.Equal(t, derived[0].ID, record.DerivedEventID)
+}
+
+func TestCompositePatternListener_ForwardsDecay(t *testing.T) {
+	baseListener := &decayRecordingListener{}
+	composite := NewCompositePatternListener(baseListener)
+	animal := PatternIdentifier{EventType: MultipleAnimalUnexpectedBehavior, EventDomain: AnimalObservation}
+	tremor := PatternIdentifier{EventType: HighFrequencyOfMinorTremors, EventDomain: Geology}
+
+	listener := &testCompositionListener{}
+	spec := newForbiddenPatternSpec()
+	spec.MinOccurrences = map[PatternIdentifier]int{animal: 2}
+	watcher := NewPatternCompositionWatcher(spec, newTestSynapse(t), listener)
+	composite.AddCompositionWatcher(watcher)
+	now := time.Now()
+
+	decaying := newTestPatternMatch(animal.EventType, animal.EventDomain, now)
+	other := newTestPatternMatch(animal.EventType, animal.EventDomain, now)
+	other.Key.Sig = 54321
+	composite.OnPatternRepeated(decaying)
+	composite.OnPatternRepeated(other)
+	require.Len(t, watcher.Store.Recent(animal, 0), 2)
+
+	composite.OnPatternDecayed(decaying.Key)
+	require.Equal(t, []LineageKey{decaying.Key}, baseListener.Decayed())
+	remaining := watcher.Store.Recent(animal, 0)
+	require.Len(t, remaining, 1, "only the decayed motif's matches are dropped")
+	require.Equal(t, other.Key, remaining[0].Key)
+
+	// The animal pattern needs 2 occurrences: with one left, the tremor does not complete it
+	composite.OnPatternRepeated(newTestPatternMatch(tremor.EventType, tremor.EventDomain, now))
+	require.Equal(t, 0, listener.Count())
+}
@@ -3,6 +3,9 @@ package event_network
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"sync"
+	"time"
 )
 
 // PatternListener is  “fire event or method call” sink.
@@ -10,6 +13,14 @@ type PatternListener interface {
 	OnPatternRepeated(match PatternMatch)
 }
 
+// PatternDecayListener is an optional extension of PatternListener. A windowed
+// PatternWatcher calls OnPatternDecayed when a motif that reached MinCount falls back
+// below it because its occurrences slid out of the window.
+type PatternDecayListener interface {
+	PatternListener
+	OnPatternDecayed(key LineageKey)
+}
+
 func NewPatternListenerPoc() *PatternListenerPoc {
 	return &PatternListenerPoc{}
 }
@@ -23,3 +34,44 @@ func (p *PatternListenerPoc) OnPatternRepeated(match PatternMatch) {
 	fmt.Println(string(str))
 	fmt.Println("-------------------------------", match.Occurrence)
 }
+
+// CompositionAuditRecord is one recognized composition, written by JSONAuditListener as a JSON line.
+type CompositionAuditRecord struct {
+	CompositionID  string    `json:"composition_id"`
+	RecognizedAt   time.Time `json:"recognized_at"`
+	PatternTypes   []string  `json:"pattern_types"` // PatternIdentifier.String() of each composed pattern
+	DerivedEventID EventID   `json:"derived_event_id"`
+	DerivedType    EventType `json:"derived_type"`
+}
+
+// JSONAuditListener is a PatternCompositionListener that appends every recognized
+// composition to W as a JSON line (see CompositionAuditRecord), e.g. to persist
+// governance decisions. It is safe for concurrent use.
+type JSONAuditListener struct {
+	W io.Writer
+
+	mu sync.Mutex
+}
+
+func NewJSONAuditListener(w io.Writer) *JSONAuditListener {
+	return &JSONAuditListener{W: w}
+}
+
+func (l *JSONAuditListener) OnCompositionRecognized(match PatternCompositionMatch) {
+	types := make([]string, 0, len(match.Patterns))
+	for _, p := range match.Patterns {
+		types = append(types, PatternIdentifier{EventType: p.Key.DerivedType, EventDomain: p.Key.DerivedDomain}.String())
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Like DecisionLog, auditing must never break composition: encoding errors are ignored.
+	_ = json.NewEncoder(l.W).Encode(CompositionAuditRecord{
+		CompositionID:  match.Spec.CompositionID,
+		RecognizedAt:   match.RecognizedAt,
+		PatternTypes:   types,
+		DerivedEventID: match.DerivedEvent.ID,
+		DerivedType:    match.DerivedEvent.EventType,
+	})
+}
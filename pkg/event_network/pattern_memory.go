@@ -38,9 +38,18 @@ type PatternMemory interface {
 	// k>0 includes contributor history recursively (k-hop provenance fingerprint).
 	EventSignature(eventID EventID, k int) (sig uint64, ok bool)
 
+	// EventsWithSignature returns the events whose depth-k signature is sig,
+	// i.e. "which events share this k-hop provenance shape".
+	EventsWithSignature(k int, sig uint64) []EventID
+	// SimilarEvents returns the other events sharing id's depth-k signature.
+	SimilarEvents(id EventID, k int) []EventID
+
 	// GetLineageStats LineageKey identifies a *class* of patterns (NOT concrete IDs).
 	GetLineageStats(key LineageKey) (LineageStats, bool)
-	// ListLineages() []LineageKey
+	// LineagesForType returns the lineage keys recorded for derived type t, at any depth.
+	LineagesForType(t EventType) []LineageKey
+	// TopLineages returns up to n lineage keys, most frequent (by Count) first.
+	TopLineages(n int) []LineageKey
 }
 
 // LineageKey is a normalized identifier for a multi-hop derivation pattern.
@@ -91,4 +100,9 @@ type LineageStats struct {
 
 	// Small bounded sample list for debug / inspection
 	Samples []LineageSample
+
+	// Recent holds the latest occurrences in the order they were recorded, with At set to
+	// the derived event's Timestamp (bounded, oldest dropped first).
+	// Windowed PatternWatcher counts are taken from it.
+	Recent []LineageSample
 }
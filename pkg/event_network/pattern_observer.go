@@ -1,17 +1,53 @@
 package event_network
 
+import "errors"
+
 type PatternObserver interface {
 	OnMaterialized(derived Event, contributors []Event, ruleID string)
 }
 
+// MultiObserver fans a materialization out to several observers, in order.
 type MultiObserver struct {
 	Observers []PatternObserver
 }
 
+func NewMultiObserver(observers ...PatternObserver) MultiObserver {
+	return MultiObserver{Observers: observers}
+}
+
+// FallibleObserver is an optional PatternObserver extension for observers that report
+// failures instead of panicking. SynapseRuntime calls OnMaterializedErr in place of
+// OnMaterialized and collects the error in IngestResult.WatcherErrors.
+type FallibleObserver interface {
+	OnMaterializedErr(derived Event, contributors []Event, ruleID string) error
+}
+
+// OnMaterialized notifies every observer, even when one of them panics. Recovered panics
+// are dropped; use OnMaterializedErr to get them.
 func (m MultiObserver) OnMaterialized(derived Event, contributors []Event, ruleID string) {
+	_ = m.OnMaterializedErr(derived, contributors, ruleID)
+}
+
+// OnMaterializedErr notifies every observer, even when one of them panics, and returns
+// the recovered panics (each an ErrWatcherPanic) joined, or nil.
+func (m MultiObserver) OnMaterializedErr(derived Event, contributors []Event, ruleID string) error {
+	var errs []error
 	for _, o := range m.Observers {
-		if o != nil {
-			o.OnMaterialized(derived, contributors, ruleID)
+		if o == nil {
+			continue
+		}
+		if err := notifyWatcher(o, derived, contributors, ruleID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// OnRetracted forwards to the observers implementing MaterializationRetractor.
+func (m MultiObserver) OnRetracted(derived Event, contributors []Event, ruleID string) {
+	for _, o := range m.Observers {
+		if r, ok := o.(MaterializationRetractor); ok {
+			r.OnRetracted(derived, contributors, ruleID)
 		}
 	}
 }
@@ -24,6 +60,10 @@ func NewPatternWatcher(mem PatternMemory, config PatternConfig) *PatternWatcher
 		MinCount: config.MinCount,
 		Listener: config.PatternListener,
 		Spec:     config.Spec,
+
+		OccurrenceScope:  config.OccurrenceScope,
+		OccurrenceWindow: config.OccurrenceWindow,
+		Window:           config.Window,
 	}
 }
 
@@ -2,6 +2,10 @@
 package event_network
 
 import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -9,9 +13,16 @@ type WatchSpec struct {
 	// If empty => watch all
 	DerivedTypes map[EventType]struct{}
 	Domains      map[EventDomain]struct{}
+
+	// RuleIDs restricts the watcher to materializations of these rules. Lineage keys are
+	// rule-agnostic, so without it two rules deriving the same shape share one count;
+	// with it only the listed rules' occurrences are counted.
+	RuleIDs map[string]struct{}
 }
 
-func (s WatchSpec) Allows(derived Event) bool {
+// Allows is the single filtering entry point of a WatchSpec: it reports whether the
+// materialization of derived by ruleID is watched, checking DerivedTypes, Domains and RuleIDs.
+func (s WatchSpec) Allows(derived Event, ruleID string) bool {
 	if s.DerivedTypes != nil {
 		if _, ok := s.DerivedTypes[derived.EventType]; !ok {
 			return false
@@ -22,7 +33,28 @@ func (s WatchSpec) Allows(derived Event) bool {
 			return false
 		}
 	}
-	return true
+	return s.allowsRule(ruleID)
+}
+
+// allowsRule is the RuleIDs part of Allows, for occurrences known only by their rule.
+func (s WatchSpec) allowsRule(ruleID string) bool {
+	if s.RuleIDs == nil {
+		return true
+	}
+	_, ok := s.RuleIDs[ruleID]
+	return ok
+}
+
+// countFor is the lineage count attributable to the watched rules.
+func (s WatchSpec) countFor(stats LineageStats) int {
+	if s.RuleIDs == nil {
+		return stats.Count
+	}
+	count := 0
+	for ruleID := range s.RuleIDs {
+		count += stats.RuleCounts[ruleID]
+	}
+	return count
 }
 
 // PatternMatch is what we get when a repeated pattern is detected.
@@ -63,13 +95,75 @@ type PatternWatcher struct {
 
 	Listener PatternListener
 	Spec     WatchSpec
+
+	// OccurrenceScope selects what PatternMatch.Occurrence (and MinCount) counts:
+	// AllTime (default) the lineage Count, PerWindow only materializations of the shape
+	// within OccurrenceWindow before the current one ("3rd occurrence this hour").
+	OccurrenceScope  OccurrenceScope
+	OccurrenceWindow *TimeWindow
+
+	// Window, when set, makes MinCount a sliding count: only occurrences within this
+	// trailing window (by the timestamps recorded in the lineage stats) are counted,
+	// so old stragglers never add up to a match. It takes precedence over OccurrenceScope.
+	Window *TimeWindow
+
+	// fired counts OnPatternRepeated calls
+	fired atomic.Int64
+
+	// shards serialize the counting of OnMaterialized per LineageKey (sharded by Sig):
+	// same-shape occurrences are numbered in order, different shapes run in parallel.
+	// Listeners are called outside the shard lock.
+	shards [patternWatcherShards]patternWatcherShard
+}
+
+const patternWatcherShards = 64
+
+type patternWatcherShard struct {
+	mu sync.Mutex
+	// lastOccurrence is the last Occurrence reported per key; keeps numbering contiguous
+	// when memory counters were bumped concurrently before this watcher ran.
+	lastOccurrence map[LineageKey]int
+	// hot marks keys whose windowed count reached MinCount and has not decayed yet
+	hot map[LineageKey]bool
+	// tracked is the latest count and materialization time per key, for Stats
+	tracked map[LineageKey]PatternStat
+}
+
+// PatternStat is a PatternWatcher's current view of one lineage shape.
+type PatternStat struct {
+	Key LineageKey
+	// Count is the occurrence count the watcher compares with MinCount
+	// (windowed when the watcher counts per window).
+	Count int
+	// LastSeen is the timestamp of the latest materialization of the shape.
+	LastSeen time.Time
+	// Hot reports whether Count has reached MinCount.
+	Hot bool
+}
+
+// OccurrenceScope selects how PatternWatcher numbers occurrences.
+type OccurrenceScope int
+
+const (
+	// AllTime counts every materialization of the shape, forever.
+	AllTime OccurrenceScope = iota
+	// PerWindow counts materializations within PatternWatcher.OccurrenceWindow
+	// (see PatternWatcher.Window); without a window it behaves like AllTime.
+	PerWindow
+)
+
+func (w *PatternWatcher) shardFor(key LineageKey) *patternWatcherShard {
+	return &w.shards[key.Sig%patternWatcherShards]
 }
 
 type PatternConfig struct {
-	Depth           int
-	MinCount        int
-	Spec            WatchSpec
-	PatternListener PatternListener
+	Depth            int
+	MinCount         int
+	Spec             WatchSpec
+	PatternListener  PatternListener
+	OccurrenceScope  OccurrenceScope
+	OccurrenceWindow *TimeWindow
+	Window           *TimeWindow
 }
 
 func (w *PatternWatcher) SetDepth(depth int) {
@@ -85,6 +179,14 @@ func (w *PatternWatcher) SetListener(listener PatternListener) {
 	w.Listener = listener
 }
 
+// Flush implements Flusher by forwarding to the listener, if it buffers anything.
+func (w *PatternWatcher) Flush(ctx context.Context) error {
+	if f, ok := w.Listener.(Flusher); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
+
 // OnMaterialized should be called *after*:
 //  1. derived event added
 //  2. all contributor -> derived edges added
@@ -97,7 +199,7 @@ func (w *PatternWatcher) OnMaterialized(derived Event, contributors []Event, rul
 		return
 	}
 
-	if !w.Spec.Allows(derived) {
+	if !w.Spec.Allows(derived, ruleID) {
 		// Debug: log when spec doesn't allow
 		// fmt.Printf("PatternWatcher: spec doesn't allow %s (depth=%d, watching=%v)\n", derived.EventType, w.Depth, w.Spec.DerivedTypes)
 		return
@@ -124,26 +226,212 @@ func (w *PatternWatcher) OnMaterialized(derived Event, contributors []Event, rul
 		Sig:           sig,
 	}
 
+	occurrence, fire, decayed := w.observe(key, derived.Timestamp)
+	if decayed {
+		w.notifyDecayed(key)
+	}
+	if !fire {
+		return
+	}
+
+	w.fired.Add(1)
+	w.Listener.OnPatternRepeated(PatternMatch{
+		Key:            key,
+		Occurrence:     occurrence,
+		At:             derived.Timestamp,
+		DerivedID:      derived.ID,
+		RuleID:         ruleID,
+		ContributorIDs: collectIDs(contributors),
+	})
+}
+
+// observe records a materialization of key at "at" in its shard and returns the occurrence
+// to report, whether it reaches MinCount and whether a hot windowed key decayed meanwhile.
+//
+// Only shard state is touched under the shard lock. Listeners are called by the caller
+// after unlocking: composition listeners re-enter Ingest from their callbacks, and the
+// composed event's key may land in the same shard.
+func (w *PatternWatcher) observe(key LineageKey, at time.Time) (occurrence int, fire, decayed bool) {
+	shard := w.shardFor(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
 	stats, ok := w.Mem.GetLineageStats(key)
 	if !ok {
-		return
+		return 0, false, false
 	}
 
+	// Memory counts are bumped before watchers run, so concurrent same-shape materializations
+	// can all observe the same (already advanced) Count. Each call corresponds to exactly one
+	// bump, so continue from the last reported occurrence instead of reusing Count.
+	occurrence = w.Spec.countFor(stats)
+	if shard.lastOccurrence == nil {
+		shard.lastOccurrence = make(map[LineageKey]int)
+	}
+	if last, seen := shard.lastOccurrence[key]; seen && last < occurrence {
+		occurrence = last + 1
+	}
+	shard.lastOccurrence[key] = occurrence
+
+	if window := w.countingWindow(); window != nil {
+		occurrence = w.windowedCount(stats, at, *window)
+		if occurrence < w.MinCount && shard.hot[key] {
+			delete(shard.hot, key)
+			decayed = true
+		}
+	}
+
+	if shard.tracked == nil {
+		shard.tracked = make(map[LineageKey]PatternStat)
+	}
+	shard.tracked[key] = PatternStat{Key: key, Count: occurrence, LastSeen: at}
+
 	// "Repeated" policy:
 	// - first time Count=1 => NOT repeated => no fire
 	// - Count>=2 => repeated => fire on every occurrence
 	// Note: stats.Count is incremented in bumpLineageStatsLocked BEFORE this check
 	// So when we check here, Count already includes the current occurrence
-	if stats.Count < w.MinCount {
+	if occurrence < w.MinCount {
+		return occurrence, false, decayed
+	}
+
+	if w.countingWindow() != nil {
+		if shard.hot == nil {
+			shard.hot = make(map[LineageKey]bool)
+		}
+		shard.hot[key] = true
+	}
+	return occurrence, true, decayed
+}
+
+// OnRetracted implements MaterializationRetractor: it takes derived's occurrence back
+// from its shape's count, so a re-derivation continues the numbering instead of skipping.
+// It must run before memory forgets derived's signatures.
+func (w *PatternWatcher) OnRetracted(derived Event, contributors []Event, ruleID string) {
+	if w == nil || w.Mem == nil || !w.Spec.Allows(derived, ruleID) {
+		return
+	}
+	sig, ok := w.Mem.EventSignature(derived.ID, w.Depth)
+	if !ok {
 		return
 	}
+	key := LineageKey{
+		DerivedType:   derived.EventType,
+		DerivedDomain: derived.EventDomain,
+		Depth:         w.Depth,
+		Sig:           sig,
+	}
 
-	w.Listener.OnPatternRepeated(PatternMatch{
-		Key:            key,
-		Occurrence:     stats.Count,
-		At:             derived.Timestamp,
-		DerivedID:      derived.ID,
-		RuleID:         ruleID,
-		ContributorIDs: collectIDs(contributors),
+	shard := w.shardFor(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	if last, seen := shard.lastOccurrence[key]; seen && last > 0 {
+		shard.lastOccurrence[key] = last - 1
+	}
+	if st, ok := shard.tracked[key]; ok && st.Count > 0 {
+		st.Count--
+		shard.tracked[key] = st
+	}
+}
+
+// FiredCount returns how many pattern matches the watcher reported.
+func (w *PatternWatcher) FiredCount() int {
+	return int(w.fired.Load())
+}
+
+// Stats returns the watcher's current count per tracked shape (every shape it has seen
+// pass its WatchSpec), hottest first, without waiting for listener callbacks.
+func (w *PatternWatcher) Stats() []PatternStat {
+	var out []PatternStat
+	for i := range w.shards {
+		shard := &w.shards[i]
+		shard.mu.Lock()
+		for _, st := range shard.tracked {
+			st.Hot = st.Count >= w.MinCount
+			out = append(out, st)
+		}
+		shard.mu.Unlock()
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		if !out[i].LastSeen.Equal(out[j].LastSeen) {
+			return out[i].LastSeen.After(out[j].LastSeen)
+		}
+		return out[i].Key.Sig < out[j].Key.Sig
 	})
+	return out
+}
+
+// CheckDecay reports motifs that are no longer hot at "now": for every key whose windowed
+// count had reached MinCount, it recounts the occurrences within the window ending at now
+// and calls OnPatternDecayed when fewer than MinCount remain. Without new occurrences the
+// watcher never runs for a key, so callers invoke CheckDecay periodically (e.g. on a ticker).
+// It is a no-op for all-time counting or when the listener is not a PatternDecayListener.
+func (w *PatternWatcher) CheckDecay(now time.Time) {
+	window := w.countingWindow()
+	if window == nil {
+		return
+	}
+	if _, ok := w.Listener.(PatternDecayListener); !ok {
+		return
+	}
+
+	// Listeners run after each shard is unlocked, as in OnMaterialized
+	var decayed []LineageKey
+	for i := range w.shards {
+		shard := &w.shards[i]
+		shard.mu.Lock()
+		for key := range shard.hot {
+			stats, _ := w.Mem.GetLineageStats(key)
+			count := w.windowedCount(stats, now, *window)
+			if st, ok := shard.tracked[key]; ok {
+				st.Count = count
+				shard.tracked[key] = st
+			}
+
+			if count < w.MinCount {
+				delete(shard.hot, key)
+				decayed = append(decayed, key)
+			}
+		}
+		shard.mu.Unlock()
+	}
+	for _, key := range decayed {
+		w.notifyDecayed(key)
+	}
+}
+
+func (w *PatternWatcher) notifyDecayed(key LineageKey) {
+	if l, ok := w.Listener.(PatternDecayListener); ok {
+		l.OnPatternDecayed(key)
+	}
+}
+
+// countingWindow is the trailing window occurrences are counted in, or nil for all-time counts.
+func (w *PatternWatcher) countingWindow() *TimeWindow {
+	if w.Window != nil {
+		return w.Window
+	}
+	if w.OccurrenceScope == PerWindow {
+		return w.OccurrenceWindow
+	}
+	return nil
+}
+
+// windowedCount returns how many occurrences recorded in stats (of the watched rules)
+// fall within window before at, at included.
+func (w *PatternWatcher) windowedCount(stats LineageStats, at time.Time, window TimeWindow) int {
+	cutoff := at.Add(-window.Duration())
+	count := 0
+	for _, occ := range stats.Recent {
+		if occ.At.Before(cutoff) || occ.At.After(at) || !w.Spec.allowsRule(occ.RuleID) {
+			continue
+		}
+		count++
+	}
+	return count
 }
@@ -1,6 +1,7 @@
 package event_network
 
 import (
+	"sort"
 	"sync"
 	"testing"
 	"time"
@@ -539,7 +540,7 @@ func TestPatternWatcher_WatchSpec_FiltersByDerivedType(t *testing.T) {
 	}
 
 	// multiplex them (so both can observe the same materialization stream)
-	patterns := MultiObserver{Observers: []PatternObserver{wTremor, wAnimal}}
+	patterns := NewMultiObserver(wTremor, wAnimal)
 
 	// helper that simulates "materialize derived event" correctly:
 	// Add derived, add edges, Memory.OnMaterialized, then Patterns.OnMaterialized. :contentReference[oaicite:3]{index=3}
@@ -653,7 +654,7 @@ func TestWatchSpec_Allows(t *testing.T) {
 			EventType:   CpuCritical,
 			EventDomain: InfraDomain,
 		}
-		require.True(t, spec.Allows(event))
+		require.True(t, spec.Allows(event, ""))
 	})
 
 	t.Run("filters by derived type", func(t *testing.T) {
@@ -667,13 +668,13 @@ func TestWatchSpec_Allows(t *testing.T) {
 			EventType:   CpuCritical,
 			EventDomain: InfraDomain,
 		}
-		require.True(t, spec.Allows(allowedEvent))
+		require.True(t, spec.Allows(allowedEvent, ""))
 
 		disallowedEvent := Event{
 			EventType:   MemoryCritical,
 			EventDomain: InfraDomain,
 		}
-		require.False(t, spec.Allows(disallowedEvent))
+		require.False(t, spec.Allows(disallowedEvent, ""))
 	})
 
 	t.Run("filters by domain", func(t *testing.T) {
@@ -687,13 +688,13 @@ func TestWatchSpec_Allows(t *testing.T) {
 			EventType:   CpuCritical,
 			EventDomain: InfraDomain,
 		}
-		require.True(t, spec.Allows(allowedEvent))
+		require.True(t, spec.Allows(allowedEvent, ""))
 
 		disallowedEvent := Event{
 			EventType:   CpuCritical,
 			EventDomain: AnimalObservation,
 		}
-		require.False(t, spec.Allows(disallowedEvent))
+		require.False(t, spec.Allows(disallowedEvent, ""))
 	})
 
 	t.Run("filters by both type and domain", func(t *testing.T) {
@@ -710,18 +711,378 @@ func TestWatchSpec_Allows(t *testing.T) {
 			EventType:   CpuCritical,
 			EventDomain: InfraDomain,
 		}
-		require.True(t, spec.Allows(allowedEvent))
+		require.True(t, spec.Allows(allowedEvent, ""))
 
 		wrongTypeEvent := Event{
 			EventType:   MemoryCritical,
 			EventDomain: InfraDomain,
 		}
-		require.False(t, spec.Allows(wrongTypeEvent))
+		require.False(t, spec.Allows(wrongTypeEvent, ""))
 
 		wrongDomainEvent := Event{
 			EventType:   CpuCritical,
 			EventDomain: AnimalObservation,
 		}
-		require.False(t, spec.Allows(wrongDomainEvent))
+		require.False(t, spec.Allows(wrongDomainEvent, ""))
 	})
+
+	t.Run("filters by rule", func(t *testing.T) {
+		spec := WatchSpec{
+			DerivedTypes: map[EventType]struct{}{
+				CpuCritical: {},
+			},
+			RuleIDs: map[string]struct{}{
+				"cpu_peers": {},
+			},
+		}
+
+		event := Event{
+			EventType:   CpuCritical,
+			EventDomain: InfraDomain,
+		}
+		require.True(t, spec.Allows(event, "cpu_peers"))
+		require.False(t, spec.Allows(event, "cpu_threshold"))
+
+		wrongTypeEvent := Event{
+			EventType:   MemoryCritical,
+			EventDomain: InfraDomain,
+		}
+		require.False(t, spec.Allows(wrongTypeEvent, "cpu_peers"))
+	})
+}
+
+func TestPatternWatcher_OnMaterialized_ConcurrentOccurrencesContiguousPerShape(t *testing.T) {
+	mem := NewInMemoryStructuralMemory()
+	listener := &testPatternListener{}
+	watcher := NewPatternWatcher(mem, PatternConfig{
+		Depth:           1,
+		MinCount:        1,
+		PatternListener: listener,
+	})
+
+	const perShape = 50
+	shapes := []EventType{CpuCritical, MemoryCritical}
+
+	var wg sync.WaitGroup
+	for _, derivedType := range shapes {
+		for i := 0; i < perShape; i++ {
+			wg.Add(1)
+			go func(derivedType EventType) {
+				defer wg.Done()
+				contributors := []Event{
+					{ID: uuid.New(), EventType: CpuStatusChanged, EventDomain: InfraDomain, Timestamp: time.Now()},
+				}
+				derived := Event{ID: uuid.New(), EventType: derivedType, EventDomain: InfraDomain, Timestamp: time.Now()}
+				mem.OnMaterialized(derived, contributors, "rule")
+				watcher.OnMaterialized(derived, contributors, "rule")
+			}(derivedType)
+		}
+	}
+	wg.Wait()
+
+	occurrences := map[EventType][]int{}
+	for _, m := range listener.All() {
+		occurrences[m.Key.DerivedType] = append(occurrences[m.Key.DerivedType], m.Occurrence)
+	}
+	require.Len(t, occurrences, len(shapes))
+	for _, derivedType := range shapes {
+		got := occurrences[derivedType]
+		require.Len(t, got, perShape, "shape %s", derivedType)
+		sort.Ints(got)
+		for i, occ := range got {
+			require.Equal(t, i+1, occ, "shape %s occurrences must be contiguous: %v", derivedType, got)
+		}
+	}
+}
+
+func TestPatternWatcher_OccurrenceScope(t *testing.T) {
+	base := time.Date(2026, 4, 25, 5, 0, 0, 0, time.UTC)
+	offsets := []time.Duration{0, 20 * time.Minute, 40 * time.Minute, 70 * time.Minute, 130 * time.Minute}
+
+	occurrences := func(scope OccurrenceScope) []int {
+		mem := NewInMemoryStructuralMemory()
+		listener := &testPatternListener{}
+		watcher := NewPatternWatcher(mem, PatternConfig{
+			Depth:            4,
+			MinCount:         1,
+			PatternListener:  listener,
+			OccurrenceScope:  scope,
+			OccurrenceWindow: &TimeWindow{Within: 1, TimeUnit: Hour},
+		})
+
+		contributors := []Event{{EventType: CpuStatusChanged, EventDomain: InfraDomain, Timestamp: base}}
+		for _, offset := range offsets {
+			derived := Event{ID: uuid.New(), EventType: CpuCritical, EventDomain: InfraDomain, Timestamp: base.Add(offset)}
+			mem.OnMaterialized(derived, contributors, "ruleA")
+			watcher.OnMaterialized(derived, contributors, "ruleA")
+		}
+
+		var out []int
+		for _, m := range listener.All() {
+			out = append(out, m.Occurrence)
+		}
+		return out
+	}
+
+	require.Equal(t, []int{1, 2, 3, 4, 5}, occurrences(AllTime), "all-time keeps climbing")
+	require.Equal(t, []int{1, 2, 3, 3, 2}, occurrences(PerWindow), "per-window drops materializations older than an hour")
+}
+
+func TestPatternWatcher_SlidingWindow(t *testing.T) {
+	base := time.Date(2026, 4, 25, 5, 0, 0, 0, time.UTC)
+
+	matches := func(window *TimeWindow, offsets ...time.Duration) []PatternMatch {
+		mem := NewInMemoryStructuralMemory()
+		listener := &testPatternListener{}
+		watcher := NewPatternWatcher(mem, PatternConfig{
+			Depth:           4,
+			MinCount:        3,
+			PatternListener: listener,
+			Window:          window,
+		})
+
+		contributors := []Event{{EventType: CpuStatusChanged, EventDomain: InfraDomain, Timestamp: base}}
+		for _, offset := range offsets {
+			derived := Event{ID: uuid.New(), EventType: CpuCritical, EventDomain: InfraDomain, Timestamp: base.Add(offset)}
+			mem.OnMaterialized(derived, contributors, "ruleA")
+			watcher.OnMaterialized(derived, contributors, "ruleA")
+		}
+		return listener.All()
+	}
+
+	hour := &TimeWindow{Within: 1, TimeUnit: Hour}
+	spread := []time.Duration{0, 50 * time.Minute, 100 * time.Minute, 150 * time.Minute, 200 * time.Minute}
+
+	require.Len(t, matches(nil, spread...), 3, "lifetime count reaches 3 on the third occurrence")
+	require.Empty(t, matches(hour, spread...), "never 3 occurrences within an hour")
+
+	burst := matches(hour, 0, 3*time.Hour, 3*time.Hour+10*time.Minute, 3*time.Hour+20*time.Minute)
+	require.Len(t, burst, 1)
+	require.Equal(t, 3, burst[0].Occurrence)
+	require.Equal(t, base.Add(3*time.Hour+20*time.Minute), burst[0].At)
+
+	t.Run("counts the occurrences recorded in lineage stats", func(t *testing.T) {
+		mem := NewInMemoryStructuralMemory()
+		listener := &testPatternListener{}
+		watcher := NewPatternWatcher(mem, PatternConfig{
+			Depth:           4,
+			MinCount:        3,
+			PatternListener: listener,
+			Window:          hour,
+		})
+
+		// Two occurrences reach memory before the watcher sees any
+		contributors := []Event{{EventType: CpuStatusChanged, EventDomain: InfraDomain, Timestamp: base}}
+		var derived Event
+		for _, offset := range []time.Duration{0, 10 * time.Minute, 20 * time.Minute} {
+			derived = Event{ID: uuid.New(), EventType: CpuCritical, EventDomain: InfraDomain, Timestamp: base.Add(offset)}
+			mem.OnMaterialized(derived, contributors, "ruleA")
+		}
+		watcher.OnMaterialized(derived, contributors, "ruleA")
+
+		all := listener.All()
+		require.Len(t, all, 1)
+		require.Equal(t, 3, all[0].Occurrence)
+
+		stats, ok := mem.GetLineageStats(all[0].Key)
+		require.True(t, ok)
+		var recorded []time.Time
+		for _, occ := range stats.Recent {
+			recorded = append(recorded, occ.At)
+		}
+		require.Equal(t, []time.Time{base, base.Add(10 * time.Minute), base.Add(20 * time.Minute)}, recorded)
+	})
+}
+
+type decayRecordingListener struct {
+	testPatternListener
+	decayed []LineageKey
+}
+
+func (l *decayRecordingListener) OnPatternDecayed(key LineageKey) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.decayed = append(l.decayed, key)
+}
+
+func (l *decayRecordingListener) Decayed() []LineageKey {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]LineageKey(nil), l.decayed...)
+}
+
+func TestPatternWatcher_Decay(t *testing.T) {
+	base := time.Date(2026, 4, 25, 5, 0, 0, 0, time.UTC)
+	contributors := []Event{{EventType: CpuStatusChanged, EventDomain: InfraDomain, Timestamp: base}}
+
+	setup := func() (*PatternWatcher, *decayRecordingListener, func(offset time.Duration)) {
+		mem := NewInMemoryStructuralMemory()
+		listener := &decayRecordingListener{}
+		watcher := NewPatternWatcher(mem, PatternConfig{
+			Depth:           4,
+			MinCount:        2,
+			PatternListener: listener,
+			Window:          &TimeWindow{Within: 1, TimeUnit: Hour},
+		})
+		materialize := func(offset time.Duration) {
+			derived := Event{ID: uuid.New(), EventType: CpuCritical, EventDomain: InfraDomain, Timestamp: base.Add(offset)}
+			mem.OnMaterialized(derived, contributors, "ruleA")
+			watcher.OnMaterialized(derived, contributors, "ruleA")
+		}
+		return watcher, listener, materialize
+	}
+
+	t.Run("fires after the window elapses without new occurrences", func(t *testing.T) {
+		watcher, listener, materialize := setup()
+		materialize(0)
+		materialize(10 * time.Minute)
+		require.Len(t, listener.All(), 1)
+
+		watcher.CheckDecay(base.Add(30 * time.Minute))
+		require.Empty(t, listener.Decayed(), "both occurrences still within the hour")
+
+		watcher.CheckDecay(base.Add(65 * time.Minute))
+		decayed := listener.Decayed()
+		require.Len(t, decayed, 1)
+		require.Equal(t, listener.All()[0].Key, decayed[0])
+
+		watcher.CheckDecay(base.Add(3 * time.Hour))
+		require.Len(t, listener.Decayed(), 1, "decay is reported once")
+	})
+
+	t.Run("fires when a late occurrence finds the window empty", func(t *testing.T) {
+		_, listener, materialize := setup()
+		materialize(0)
+		materialize(10 * time.Minute)
+		materialize(3 * time.Hour)
+		require.Len(t, listener.All(), 1)
+		require.Len(t, listener.Decayed(), 1)
+
+		materialize(3*time.Hour + 5*time.Minute)
+		require.Len(t, listener.All(), 2, "hot again")
+		require.Len(t, listener.Decayed(), 1)
+	})
+}
+
+// reentrantDecayListener reads the watcher's stats from inside its callbacks, as a
+// composition listener re-entering Ingest would touch the watcher's shards.
+type reentrantDecayListener struct {
+	decayRecordingListener
+	watcher *PatternWatcher
+}
+
+func (l *reentrantDecayListener) OnPatternRepeated(m PatternMatch) {
+	l.watcher.Stats()
+	l.decayRecordingListener.OnPatternRepeated(m)
+}
+
+func (l *reentrantDecayListener) OnPatternDecayed(key LineageKey) {
+	l.watcher.Stats()
+	l.decayRecordingListener.OnPatternDecayed(key)
+}
+
+func TestPatternWatcher_ListenerCalledOutsideShardLock(t *testing.T) {
+	base := time.Date(2026, 4, 25, 5, 0, 0, 0, time.UTC)
+	contributors := []Event{{EventType: CpuStatusChanged, EventDomain: InfraDomain, Timestamp: base}}
+	mem := NewInMemoryStructuralMemory()
+	listener := &reentrantDecayListener{}
+	watcher := NewPatternWatcher(mem, PatternConfig{
+		Depth:           4,
+		MinCount:        2,
+		PatternListener: listener,
+		Window:          &TimeWindow{Within: 1, TimeUnit: Hour},
+	})
+	listener.watcher = watcher
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, offset := range []time.Duration{0, 10 * time.Minute, 3 * time.Hour, 3*time.Hour + 5*time.Minute} {
+			derived := Event{ID: uuid.New(), EventType: CpuCritical, EventDomain: InfraDomain, Timestamp: base.Add(offset)}
+			mem.OnMaterialized(derived, contributors, "ruleA")
+			watcher.OnMaterialized(derived, contributors, "ruleA")
+		}
+		watcher.CheckDecay(base.Add(5 * time.Hour))
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("listener callback deadlocked on the watcher's shard lock")
+	}
+	require.Len(t, listener.All(), 2)
+	require.Len(t, listener.Decayed(), 2)
+}
+
+func TestPatternWatcher_RuleIDs(t *testing.T) {
+	contributors := []Event{
+		{EventType: CpuStatusChanged, EventDomain: InfraDomain, Timestamp: time.Now()},
+		{EventType: CpuStatusChanged, EventDomain: InfraDomain, Timestamp: time.Now()},
+	}
+
+	occurrences := func(spec WatchSpec) []int {
+		mem := NewInMemoryStructuralMemory()
+		listener := &testPatternListener{}
+		watcher := NewPatternWatcher(mem, PatternConfig{
+			Depth:           4,
+			MinCount:        2,
+			Spec:            spec,
+			PatternListener: listener,
+		})
+
+		// Two rules derive the same CpuCritical shape, interleaved.
+		for _, ruleID := range []string{"cpu_peers", "cpu_threshold", "cpu_threshold", "cpu_peers"} {
+			derived := Event{ID: uuid.New(), EventType: CpuCritical, EventDomain: InfraDomain, Timestamp: time.Now()}
+			mem.OnMaterialized(derived, contributors, ruleID)
+			watcher.OnMaterialized(derived, contributors, ruleID)
+		}
+
+		var out []int
+		for _, m := range listener.All() {
+			require.True(t, spec.Allows(Event{EventType: CpuCritical, EventDomain: InfraDomain}, m.RuleID))
+			out = append(out, m.Occurrence)
+		}
+		return out
+	}
+
+	require.Equal(t, []int{2, 3, 4}, occurrences(WatchSpec{}), "rule-agnostic: both rules share the shape count")
+	require.Equal(t, []int{2}, occurrences(WatchSpec{RuleIDs: map[string]struct{}{"cpu_peers": {}}}),
+		"only cpu_peers' second occurrence")
+}
+
+func TestPatternWatcher_Stats(t *testing.T) {
+	base := time.Date(2026, 4, 25, 5, 0, 0, 0, time.UTC)
+	mem := NewInMemoryStructuralMemory()
+	listener := &testPatternListener{}
+	watcher := NewPatternWatcher(mem, PatternConfig{
+		Depth:           4,
+		MinCount:        3,
+		PatternListener: listener,
+	})
+	require.Empty(t, watcher.Stats())
+
+	contributors := []Event{{EventType: CpuStatusChanged, EventDomain: InfraDomain, Timestamp: base}}
+	materialize := func(offset time.Duration) {
+		derived := Event{ID: uuid.New(), EventType: CpuCritical, EventDomain: InfraDomain, Timestamp: base.Add(offset)}
+		mem.OnMaterialized(derived, contributors, "ruleA")
+		watcher.OnMaterialized(derived, contributors, "ruleA")
+	}
+
+	materialize(0)
+	materialize(time.Minute)
+	require.Empty(t, listener.All(), "below MinCount")
+
+	stats := watcher.Stats()
+	require.Len(t, stats, 1)
+	require.Equal(t, CpuCritical, stats[0].Key.DerivedType)
+	require.Equal(t, 2, stats[0].Count)
+	require.Equal(t, base.Add(time.Minute), stats[0].LastSeen)
+	require.False(t, stats[0].Hot)
+
+	materialize(2 * time.Minute)
+	stats = watcher.Stats()
+	require.Len(t, stats, 1)
+	require.Equal(t, 3, stats[0].Count)
+	require.True(t, stats[0].Hot)
+	require.Equal(t, listener.All()[0].Key, stats[0].Key)
 }
@@ -2,9 +2,57 @@ package event_network
 
 import (
 	"fmt"
+	"io"
+	"strconv"
 	"time"
 )
 
+// ExportDOT writes the network as a Graphviz digraph: one node per event, labeled
+// with its type and domain, and one edge per derivation link, labeled with its relation.
+// Derived events (with contributors) are filled light blue; leaf observations stay white.
+// Output order is stable (timestamp, then ID), so exports can be diffed.
+func ExportDOT(n EventNetwork, w io.Writer) error {
+	events, err := n.AllEvents()
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintln(w, "digraph events {"); err != nil {
+		return err
+	}
+
+	var edges []Edge
+	for _, ev := range events {
+		in, err := n.InEdges(ev.ID)
+		if err != nil {
+			return err
+		}
+		fill := "white"
+		if len(in) > 0 {
+			fill = "lightblue"
+		}
+		label := strconv.Quote(ev.EventType + "\n" + ev.EventDomain)
+		if _, err := fmt.Fprintf(w, "  %q [label=%s, style=filled, fillcolor=%s];\n", ev.ID.String(), label, fill); err != nil {
+			return err
+		}
+
+		out, err := n.OutEdges(ev.ID)
+		if err != nil {
+			return err
+		}
+		edges = append(edges, out...)
+	}
+
+	for _, e := range edges {
+		if _, err := fmt.Fprintf(w, "  %q -> %q [label=%s];\n", e.From.String(), e.To.String(), strconv.Quote(e.Relation)); err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprintln(w, "}")
+	return err
+}
+
 func printDerivedFrom(net *InMemoryEventNetwork, ev Event, indent string) {
 	edges := net.in[ev.ID] // contributors → ev
 	if len(edges) == 0 {
@@ -24,6 +72,9 @@ func printDerivedFrom(net *InMemoryEventNetwork, ev Event, indent string) {
 
 func PrintEventGraph(network EventNetwork) {
 	net := network.(*InMemoryEventNetwork)
+	net.mu.RLock()
+	defer net.mu.RUnlock()
+
 	levels := computeDerivationLevels(net)
 
 	grouped := make(map[int][]Event)
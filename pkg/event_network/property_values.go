@@ -0,0 +1,187 @@
+package event_network
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// PropertyMatcher accepts or rejects a property value, see Conditions.PropertyMatchers.
+type PropertyMatcher func(v any) bool
+
+// GreaterThan accepts numeric values above threshold.
+func GreaterThan(threshold float64) PropertyMatcher {
+	return func(v any) bool {
+		f, ok := toFloat64(v)
+		return ok && f > threshold
+	}
+}
+
+// LessThan accepts numeric values below threshold.
+func LessThan(threshold float64) PropertyMatcher {
+	return func(v any) bool {
+		f, ok := toFloat64(v)
+		return ok && f < threshold
+	}
+}
+
+// OneOf accepts values equal (as in PropertyValues) to any of values.
+func OneOf(values ...any) PropertyMatcher {
+	return func(v any) bool {
+		for _, want := range values {
+			if propertyValueEquals(v, want) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// matchConditionProperties applies cond.PropertyValues and cond.PropertyMatchers to props
+// using cond.PropertyMatchMode. Nil maps mean "no property constraint".
+func matchConditionProperties(props EventProps, cond Conditions) bool {
+	if cond.PropertyValues == nil && cond.PropertyMatchers == nil {
+		return true
+	}
+	if cond.PropertyMatchMode == Exact && !sameKeySet(props, constrainedKeys(props, cond)) {
+		return false
+	}
+	if !matchPropertyValues(props, cond.PropertyValues) {
+		return false
+	}
+	for k, match := range cond.PropertyMatchers {
+		v, ok := lookupProperty(props, k)
+		if !ok || !match(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// constrainedKeys is the set of top-level keys that PropertyValues and PropertyMatchers
+// constrain in props (a dotted path counts as its first segment).
+func constrainedKeys(props EventProps, cond Conditions) map[string]struct{} {
+	keys := make(map[string]struct{}, len(cond.PropertyValues)+len(cond.PropertyMatchers))
+	for k := range cond.PropertyValues {
+		keys[topLevelKey(props, k)] = struct{}{}
+	}
+	for k := range cond.PropertyMatchers {
+		keys[topLevelKey(props, k)] = struct{}{}
+	}
+	return keys
+}
+
+// sameKeySet reports whether props has exactly the given top-level keys. A constrained key
+// missing from props fails even when its wanted value is nil.
+func sameKeySet(props EventProps, keys map[string]struct{}) bool {
+	if len(props) != len(keys) {
+		return false
+	}
+	for k := range keys {
+		if _, ok := props[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// lookupProperty resolves key in props. A key that is not a top-level property is read as a
+// dotted path through nested maps, e.g. "props4.objProp"; a missing segment is not found.
+func lookupProperty(props EventProps, key string) (any, bool) {
+	if v, ok := props[key]; ok {
+		return v, true
+	}
+	head, rest, found := strings.Cut(key, ".")
+	if !found {
+		return nil, false
+	}
+	nested, ok := props[head].(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	return lookupProperty(nested, rest)
+}
+
+// topLevelKey is the property of props that key resolves through.
+func topLevelKey(props EventProps, key string) string {
+	if _, ok := props[key]; ok {
+		return key
+	}
+	head, _, _ := strings.Cut(key, ".")
+	return head
+}
+
+// matchPropertyValues reports whether props satisfy every key/value in want.
+// Keys may be dotted paths into nested maps (see lookupProperty).
+//
+// Values are compared with propertyValueEquals, so numbers decoded from JSON
+// (always float64) still match int literals used in rule conditions.
+func matchPropertyValues(props EventProps, want map[string]any) bool {
+	for k, v := range want {
+		if props == nil {
+			return false
+		}
+		actual, ok := lookupProperty(props, k)
+		if !ok && v != nil {
+			return false
+		}
+		if !propertyValueEquals(actual, v) {
+			return false
+		}
+	}
+	return true
+}
+
+// propertyValueEquals compares two property values.
+//
+// Numeric values are normalized first, so int(3), int64(3), float64(3.0)
+// and json.Number("3") are all equal. Everything else falls back to deep equality.
+func propertyValueEquals(a, b any) bool {
+	af, aNum := toFloat64(a)
+	bf, bNum := toFloat64(b)
+	if aNum && bNum {
+		return af == bf
+	}
+	if aNum != bNum {
+		return false
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// toFloat64 converts any Go numeric value to float64.
+func toFloat64(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		if err != nil {
+			return 0, false
+		}
+		return f, true
+	default:
+		return 0, false
+	}
+}
@@ -15,6 +15,12 @@ type ActionType string
 
 const (
 	DeriveNode ActionType = "DeriveNode"
+	// AnnotateNode merges the template's EventProps into the matched events and the anchor,
+	// without creating a node.
+	AnnotateNode ActionType = "AnnotateNode"
+	// DeriveEdge links the matched events to the anchor (matched -> anchor) with the rule's
+	// relation, without creating a node.
+	DeriveEdge ActionType = "DeriveEdge"
 )
 
 type Rule interface {
@@ -25,29 +31,55 @@ type Rule interface {
 	GetID() string
 }
 
-type DeriveEventRule struct {
-	ID                string `json:"id"`
-	ActionType        ActionType
-	Network           EventNetwork  `json:"-"`
-	Condition         *Condition    `json:"condition"`
-	EventTemplate     EventTemplate `json:"event_template"`
-	conditionCompiler *ConditionCompiler
+// RelationLabeler is an optional Rule extension.
+// Rules implementing it choose the relation label of the edges linking their
+// derived events to the contributors; other rules use "trigger".
+type RelationLabeler interface {
+	GetRelation() string
 }
 
-func NewDeriveEventRule(
-	uniqueName string,
-	condition *Condition,
-	eventTemplate EventTemplate) *DeriveEventRule {
-	return &DeriveEventRule{
-		ID:            uniqueName,
-		ActionType:    DeriveNode,
-		Condition:     condition,
-		EventTemplate: eventTemplate,
+// relationFor returns the edge relation for events derived by rule.
+func relationFor(rule Rule) string {
+	if labeler, ok := rule.(RelationLabeler); ok {
+		return labeler.GetRelation()
 	}
+	return "trigger"
+}
+
+// Prioritizer is an optional Rule extension.
+// Rules registered for the same event type run by descending priority; rules
+// without it have priority 0, and equal priorities keep registration order.
+type Prioritizer interface {
+	GetPriority() int
+}
+
+// priorityFor returns the priority of rule.
+func priorityFor(rule Rule) int {
+	if p, ok := rule.(Prioritizer); ok {
+		return p.GetPriority()
+	}
+	return 0
+}
+
+// RuleBase holds what the condition-driven rules (DeriveEventRule, AnnotateRule,
+// DeriveEdgeRule) share: identity, priority and the Condition evaluated against each
+// ingested event. Embedding it provides Process, BindNetwork, GetID and GetPriority.
+type RuleBase struct {
+	ID                string       `json:"id"`
+	Network           EventNetwork `json:"-"`
+	Condition         *Condition   `json:"condition"`
+	Priority          int          `json:"priority,omitempty"`
+	conditionCompiler *ConditionCompiler
+}
+
+func (r *RuleBase) Process(event Event) (bool, []Event, error) {
+	return processCondition(r.conditionCompiler, r.Condition, event)
 }
 
-func (r *DeriveEventRule) Process(event Event) (bool, []Event, error) {
-	expression, err := r.conditionCompiler.Compile(r.Condition, &event)
+// processCondition compiles condition against event and evaluates it;
+// an unsatisfied condition is reported as ErrNotSatisfied.
+func processCondition(compiler *ConditionCompiler, condition *Condition, event Event) (bool, []Event, error) {
+	expression, err := compiler.Compile(condition, &event)
 	if err != nil {
 		return false, nil, err
 	}
@@ -61,11 +93,38 @@ func (r *DeriveEventRule) Process(event Event) (bool, []Event, error) {
 	return ok, events, nil
 }
 
-func (r *DeriveEventRule) BindNetwork(network EventNetwork) {
+func (r *RuleBase) BindNetwork(network EventNetwork) {
 	r.Network = network
 	r.conditionCompiler = NewConditionCompiler(network)
 }
 
+func (r *RuleBase) GetID() string {
+	return r.ID
+}
+
+// GetPriority orders the rule among the rules of its event type (higher runs first).
+func (r *RuleBase) GetPriority() int {
+	return r.Priority
+}
+
+type DeriveEventRule struct {
+	RuleBase
+	ActionType    ActionType
+	EventTemplate EventTemplate `json:"event_template"`
+	Relation      string        `json:"relation,omitempty"`
+}
+
+func NewDeriveEventRule(
+	uniqueName string,
+	condition *Condition,
+	eventTemplate EventTemplate) *DeriveEventRule {
+	return &DeriveEventRule{
+		RuleBase:      RuleBase{ID: uniqueName, Condition: condition},
+		ActionType:    DeriveNode,
+		EventTemplate: eventTemplate,
+	}
+}
+
 func (r *DeriveEventRule) GetActionType() ActionType {
 	return r.ActionType
 }
@@ -74,6 +133,70 @@ func (r *DeriveEventRule) GetActionTemplate() EventTemplate {
 	return r.EventTemplate
 }
 
-func (r *DeriveEventRule) GetID() string {
-	return r.ID
+// GetRelation labels the contributor edges of derived events.
+// Without an explicit Relation it is "trigger:" + ID, so relation-filtered
+// queries can tell rules apart without a separate provenance store.
+func (r *DeriveEventRule) GetRelation() string {
+	if r.Relation != "" {
+		return r.Relation
+	}
+	return "trigger:" + r.ID
+}
+
+// AnnotateRule tags existing events instead of deriving new ones: when Condition matches,
+// Properties are merged into the anchor and the events the condition matched
+// (the events a DeriveEventRule would have linked as contributors).
+// Existing keys are overwritten; no node or edge is created.
+type AnnotateRule struct {
+	RuleBase
+	Properties EventProps `json:"properties"`
+}
+
+func NewAnnotateRule(uniqueName string, condition *Condition, properties EventProps) *AnnotateRule {
+	return &AnnotateRule{
+		RuleBase:   RuleBase{ID: uniqueName, Condition: condition},
+		Properties: properties,
+	}
+}
+
+func (r *AnnotateRule) GetActionType() ActionType {
+	return AnnotateNode
+}
+
+// GetActionTemplate carries the annotation in EventProps.
+func (r *AnnotateRule) GetActionTemplate() EventTemplate {
+	return EventTemplate{EventProps: r.Properties}
+}
+
+// DeriveEdgeRule correlates existing events instead of deriving new ones: when Condition matches,
+// every matched event is linked to the anchor (matched -> anchor) with Relation,
+// e.g. "link these two suspicious events as correlated". The matched events then have
+// the anchor as parent, so they are siblings of each other and no longer peers.
+type DeriveEdgeRule struct {
+	RuleBase
+	Relation string `json:"relation,omitempty"`
+}
+
+func NewDeriveEdgeRule(uniqueName string, condition *Condition, relation string) *DeriveEdgeRule {
+	return &DeriveEdgeRule{
+		RuleBase: RuleBase{ID: uniqueName, Condition: condition},
+		Relation: relation,
+	}
+}
+
+func (r *DeriveEdgeRule) GetActionType() ActionType {
+	return DeriveEdge
+}
+
+// GetActionTemplate is empty: no event is created.
+func (r *DeriveEdgeRule) GetActionTemplate() EventTemplate {
+	return EventTemplate{}
+}
+
+// GetRelation labels the created edges; like DeriveEventRule it defaults to "trigger:" + ID.
+func (r *DeriveEdgeRule) GetRelation() string {
+	if r.Relation != "" {
+		return r.Relation
+	}
+	return "trigger:" + r.ID
 }
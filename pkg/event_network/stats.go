@@ -0,0 +1,115 @@
+package event_network
+
+// SynapseStats is a one-shot snapshot of a runtime: what the network holds, what memory
+// has learned, and how often watchers and compositions fired. See SynapseRuntime.FullStats.
+type SynapseStats struct {
+	Network      NetworkStats
+	Memory       MemoryStats
+	Watchers     []WatcherStats
+	Compositions []CompositionStats
+}
+
+type NetworkStats struct {
+	Events          int
+	Edges           int
+	EventsByType    map[EventType]int
+	EventsByDomain  map[EventDomain]int
+	EdgesByRelation map[string]int
+}
+
+type MemoryStats struct {
+	Motifs int
+	// Lineages is the number of distinct lineage shapes; 0 when Memory is not a LineageCounter.
+	Lineages  int
+	GlobalRev uint64
+}
+
+// WatcherStats describes one PatternWatcher registered with the runtime.
+type WatcherStats struct {
+	Depth    int
+	MinCount int
+	Fired    int
+}
+
+// CompositionStats describes one PatternCompositionWatcher reachable from the runtime's
+// pattern watchers (through a CompositePatternListener).
+type CompositionStats struct {
+	CompositionID string
+	Fired         int
+}
+
+// LineageCounter is an optional StructuralMemory extension reporting how many distinct
+// lineage shapes it tracks.
+type LineageCounter interface {
+	LineageCount() int
+}
+
+// FullStats aggregates network, memory, watcher and composition state in one call,
+// e.g. for a debug page. Network errors leave the network section partially filled.
+func (s *SynapseRuntime) FullStats() SynapseStats {
+	stats := SynapseStats{
+		Network: NetworkStats{
+			EventsByType:    make(map[EventType]int),
+			EventsByDomain:  make(map[EventDomain]int),
+			EdgesByRelation: make(map[string]int),
+		},
+	}
+
+	if n, ok := s.Network.(interface{ Stats() NetworkStats }); ok {
+		stats.Network = n.Stats()
+	} else if s.Network != nil {
+		events, _ := s.Network.AllEvents()
+		for _, ev := range events {
+			stats.Network.Events++
+			stats.Network.EventsByType[ev.EventType]++
+			stats.Network.EventsByDomain[ev.EventDomain]++
+
+			out, err := s.Network.OutEdges(ev.ID)
+			if err != nil {
+				continue
+			}
+			for _, edge := range out {
+				stats.Network.Edges++
+				stats.Network.EdgesByRelation[edge.Relation]++
+			}
+		}
+	}
+
+	if s.Memory != nil {
+		stats.Memory.Motifs = len(s.Memory.ListMotifs())
+		stats.Memory.GlobalRev = s.Memory.GlobalRev()
+		if lc, ok := s.Memory.(LineageCounter); ok {
+			stats.Memory.Lineages = lc.LineageCount()
+		}
+	}
+
+	seen := make(map[*PatternCompositionWatcher]bool)
+	for _, observer := range s.PatternWatcher {
+		watcher, ok := observer.(*PatternWatcher)
+		if !ok {
+			continue
+		}
+		stats.Watchers = append(stats.Watchers, WatcherStats{
+			Depth:    watcher.Depth,
+			MinCount: watcher.MinCount,
+			Fired:    watcher.FiredCount(),
+		})
+
+		composite, ok := watcher.Listener.(*CompositePatternListener)
+		if !ok {
+			continue
+		}
+		for _, cw := range composite.CompositionWatchers() {
+			if seen[cw] {
+				continue
+			}
+			seen[cw] = true
+			stats.Compositions = append(stats.Compositions, CompositionStats{
+				CompositionID: cw.Spec.CompositionID,
+				Fired:         cw.FiredCount(),
+			})
+		}
+	}
+
+	return stats
+}
@@ -1,5 +1,7 @@
 package event_network
 
+import "context"
+
 type Synapse interface {
 	Ingest(event Event) (EventID, error)
 	RegisterRule(eventType EventType, rule Rule)
@@ -7,6 +9,15 @@ type Synapse interface {
 	GetNetwork() EventNetwork
 }
 
+// RuleFreeMaterializer is an optional Synapse extension for storing derived events
+// without evaluating rules on them (memory and pattern watchers are still notified).
+// Recovered watcher panics (ErrWatcherPanic) are returned with the stored event.
+//
+// Components like PatternCompositionWatcher type-assert to it when configured to skip rules.
+type RuleFreeMaterializer interface {
+	MaterializeWithoutRules(derived Event, contributorIDs []EventID, relation string, originID string) (Event, error)
+}
+
 func NewSynapse(patternConfig []PatternConfig) *SynapseRuntime {
 	base := NewInMemoryEventNetwork()
 	memory := NewInMemoryStructuralMemory()
@@ -19,6 +30,10 @@ func NewSynapse(patternConfig []PatternConfig) *SynapseRuntime {
 			MinCount:        config.MinCount,
 			Spec:            config.Spec,
 			PatternListener: config.PatternListener,
+
+			OccurrenceScope:  config.OccurrenceScope,
+			OccurrenceWindow: config.OccurrenceWindow,
+			Window:           config.Window,
 		})
 		watchers = append(watchers, watcher)
 	}
@@ -29,5 +44,16 @@ func NewSynapse(patternConfig []PatternConfig) *SynapseRuntime {
 		Memory:         memory,
 		rulesByType:    make(map[EventType][]Rule),
 		PatternWatcher: watchers,
+
+		ConflictResolver: KeepExisting,
 	}
 }
+
+// Flusher is an optional extension for components that buffer work internally
+// (pattern watchers, composition watchers, audit sinks).
+//
+// SynapseRuntime.Flush type-asserts its Memory and PatternWatchers to it;
+// listeners forward the call to whatever they wrap.
+type Flusher interface {
+	Flush(ctx context.Context) error
+}
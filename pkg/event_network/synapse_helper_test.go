@@ -99,3 +99,12 @@ func getMinorTremorDerivedEventTemplate() EventTemplate {
 		EventDomain: Geology,
 	}
 }
+
+// newSynapseStoringDuplicates is NewSynapse without content deduplication. Scenarios that
+// repeat an event differing only in timestamp (e.g. minor tremors) mean distinct occurrences,
+// so each ingest must stay its own node instead of collapsing under KeepExisting.
+func newSynapseStoringDuplicates(configs []PatternConfig) *SynapseRuntime {
+	synapse := NewSynapse(configs)
+	synapse.ConflictResolver = nil
+	return synapse
+}
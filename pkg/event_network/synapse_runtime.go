@@ -1,9 +1,13 @@
 package event_network
 
 import (
+	"context"
 	"errors"
-
-	"github.com/google/uuid"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"sync"
 	"time"
 )
 
@@ -13,29 +17,242 @@ type SynapseRuntime struct {
 	Memory         StructuralMemory
 	rulesByType    map[EventType][]Rule
 	PatternWatcher []PatternObserver
+
+	// rulesMu guards rulesByType, so rules can be (un)registered while ingestion runs.
+	// The slices are copy-on-write: Ingest iterates a snapshot (see rulesFor) without
+	// holding the lock, as rules may re-enter Ingest through composition watchers.
+	rulesMu sync.RWMutex
+
+	// ReevaluateOnChange re-runs existing derivations when an ingested event fires
+	// no DeriveNode rule of its own, so late-arriving contributors can join (or break up)
+	// clusters derived before they arrived. See reevaluate. Off by default.
+	ReevaluateOnChange bool
+
+	// ConflictResolver handles re-ingesting an event whose ContentHash matches an earlier
+	// ingest. NewSynapse defaults it to KeepExisting; set it to nil to store every ingest as a new node.
+	ConflictResolver ConflictResolver
+	// ContentIndexLimit bounds how many ingested events ConflictResolver remembers by
+	// content; beyond it the oldest are forgotten (and no longer deduplicated). 0 means unbounded.
+	ContentIndexLimit int
+	content           contentIndex
+
+	// in-flight Ingest calls (including nested ones from composition watchers), see Flush
+	ingestMu sync.Mutex
+	inflight int
+	idle     chan struct{}
 }
 
 func (s *SynapseRuntime) RegisterRule(eventType EventType, rule Rule) {
-	// IMPORTANT: bind rules to EvalNet so Expression evaluation benefits from caching
-	rule.BindNetwork(s.Network)
-	s.rulesByType[eventType] = append(s.rulesByType[eventType], rule)
+	s.RegisterRuleForTypes([]EventType{eventType}, rule)
 }
 
 func (s *SynapseRuntime) RegisterRuleForTypes(eventTypes []EventType, rule Rule) {
+	s.rulesMu.Lock()
+	defer s.rulesMu.Unlock()
+
+	s.registerLocked(eventTypes, rule)
+}
+
+func (s *SynapseRuntime) registerLocked(eventTypes []EventType, rule Rule) {
 	// IMPORTANT: bind rules to EvalNet so Expression evaluation benefits from caching
 	rule.BindNetwork(s.Network)
 	for _, eventType := range eventTypes {
-		s.rulesByType[eventType] = append(s.rulesByType[eventType], rule)
+		s.addRule(eventType, rule)
 	}
 }
 
+// ErrDuplicateRule is returned by RegisterRuleE / RegisterRuleForTypesE when a rule
+// with the same ID is already registered for the event type.
+var ErrDuplicateRule = errors.New("duplicate rule ID")
+
+// RegisterRuleE is RegisterRule, refusing a rule whose ID is already registered for eventType
+// (duplicates make UnregisterRule ambiguous and can fire twice).
+func (s *SynapseRuntime) RegisterRuleE(eventType EventType, rule Rule) error {
+	return s.RegisterRuleForTypesE([]EventType{eventType}, rule)
+}
+
+// RegisterRuleForTypesE is RegisterRuleForTypes with the RegisterRuleE check;
+// on a conflict the rule is registered for none of the types.
+func (s *SynapseRuntime) RegisterRuleForTypesE(eventTypes []EventType, rule Rule) error {
+	s.rulesMu.Lock()
+	defer s.rulesMu.Unlock()
+
+	id := rule.GetID()
+	for _, eventType := range eventTypes {
+		for _, existing := range s.rulesByType[eventType] {
+			if existing.GetID() == id {
+				return fmt.Errorf("%w: rule %q is already registered for %s", ErrDuplicateRule, id, eventType)
+			}
+		}
+	}
+	s.registerLocked(eventTypes, rule)
+	return nil
+}
+
+// addRule appends rule to eventType, keeping the rules ordered by descending priority
+// (see Prioritizer) so Ingest runs e.g. coarse "suppress" rules before finer derivations.
+// Priority is read at registration. The slice is rebuilt, never modified in place.
+func (s *SynapseRuntime) addRule(eventType EventType, rule Rule) {
+	rules := append(slices.Clone(s.rulesByType[eventType]), rule)
+	sort.SliceStable(rules, func(i, j int) bool {
+		return priorityFor(rules[i]) > priorityFor(rules[j])
+	})
+	s.rulesByType[eventType] = rules
+}
+
+// UnregisterRule removes the rule with ruleID (matched by GetID) from eventType.
+// Returns false when no such rule was registered for the type.
+func (s *SynapseRuntime) UnregisterRule(eventType EventType, ruleID string) bool {
+	s.rulesMu.Lock()
+	defer s.rulesMu.Unlock()
+
+	return s.unregisterLocked(eventType, ruleID)
+}
+
+func (s *SynapseRuntime) unregisterLocked(eventType EventType, ruleID string) bool {
+	rules := s.rulesByType[eventType]
+	kept := make([]Rule, 0, len(rules))
+	for _, rule := range rules {
+		if rule.GetID() != ruleID {
+			kept = append(kept, rule)
+		}
+	}
+	if len(kept) == len(rules) {
+		return false
+	}
+	if len(kept) == 0 {
+		delete(s.rulesByType, eventType)
+	} else {
+		s.rulesByType[eventType] = kept
+	}
+	return true
+}
+
+// UnregisterRuleForAllTypes removes the rule with ruleID from every event type
+// and returns how many types it was removed from.
+func (s *SynapseRuntime) UnregisterRuleForAllTypes(ruleID string) int {
+	s.rulesMu.Lock()
+	defer s.rulesMu.Unlock()
+
+	types := make([]EventType, 0, len(s.rulesByType))
+	for eventType := range s.rulesByType {
+		types = append(types, eventType)
+	}
+
+	removed := 0
+	for _, eventType := range types {
+		if s.unregisterLocked(eventType, ruleID) {
+			removed++
+		}
+	}
+	return removed
+}
+
+// rulesFor returns the rules registered for eventType. The slice is a snapshot:
+// later registrations replace it rather than modify it.
+func (s *SynapseRuntime) rulesFor(eventType EventType) []Rule {
+	s.rulesMu.RLock()
+	defer s.rulesMu.RUnlock()
+
+	return s.rulesByType[eventType]
+}
+
+// IngestResult describes what a single Ingest stored and derived.
+type IngestResult struct {
+	// EventID of the stored (or, on a resolved conflict, the existing) event
+	EventID EventID
+	// Derived events materialized by rules, in derivation order (cascades included)
+	Derived []Event
+	// RuleIDs[i] is the ID of the rule that derived Derived[i]
+	RuleIDs []string
+	// WatcherErrors are the pattern watcher panics recovered while materializing
+	// Derived (see ErrWatcherPanic). They do not fail the ingest.
+	WatcherErrors []error
+}
+
+// ListRules returns the IDs of the rules registered per trigger type, in the order Ingest runs them.
+// A rule registered for several types appears under each of them.
+func (s *SynapseRuntime) ListRules() map[EventType][]string {
+	s.rulesMu.RLock()
+	defer s.rulesMu.RUnlock()
+
+	out := make(map[EventType][]string, len(s.rulesByType))
+	for eventType, rules := range s.rulesByType {
+		ids := make([]string, 0, len(rules))
+		for _, rule := range rules {
+			ids = append(ids, rule.GetID())
+		}
+		out[eventType] = ids
+	}
+	return out
+}
+
+// GetRule returns the registered rule with the given ID.
+func (s *SynapseRuntime) GetRule(id string) (Rule, bool) {
+	s.rulesMu.RLock()
+	defer s.rulesMu.RUnlock()
+
+	for _, rules := range s.rulesByType {
+		for _, rule := range rules {
+			if rule.GetID() == id {
+				return rule, true
+			}
+		}
+	}
+	return nil, false
+}
+
 func (s *SynapseRuntime) Ingest(event Event) (EventID, error) {
+	return s.IngestCtx(context.Background(), event)
+}
+
+// IngestCtx is Ingest bounded by ctx: it checks ctx between rule evaluations and before
+// each materialization and returns ctx.Err() as soon as it is done. Whatever was stored or
+// derived before that stays in the network, each derived event fully materialized.
+func (s *SynapseRuntime) IngestCtx(ctx context.Context, event Event) (EventID, error) {
+	result, err := s.ingest(ctx, event)
+	return result.EventID, err
+}
+
+// IngestWithResult is Ingest, reporting the derived events and the rules that fired.
+func (s *SynapseRuntime) IngestWithResult(event Event) (IngestResult, error) {
+	return s.ingest(context.Background(), event)
+}
+
+func (s *SynapseRuntime) ingest(ctx context.Context, event Event) (IngestResult, error) {
+	s.beginIngest()
+	defer s.endIngest()
+
+	var result IngestResult
+	err := s.withEvictionHeld(func() (err error) {
+		result, err = s.ingestEvent(ctx, event)
+		return err
+	})
+	return result, err
+}
+
+func (s *SynapseRuntime) ingestEvent(ctx context.Context, event Event) (IngestResult, error) {
+	if err := event.Valid(); err != nil {
+		return IngestResult{}, err
+	}
+	if err := ctx.Err(); err != nil {
+		return IngestResult{}, err
+	}
+
 	// 1) Add event
-	id, err := s.Network.AddEvent(event)
+	var err error
+	if s.ConflictResolver != nil {
+		var id EventID
+		var handled bool
+		if event, id, handled, err = s.addOrResolve(event); handled {
+			return IngestResult{EventID: id}, err
+		}
+	} else {
+		event, err = s.Network.AddEventFull(event)
+	}
 	if err != nil {
-		return uuid.UUID{}, err
+		return IngestResult{}, err
 	}
-	event.ID = id
 
 	// Leaf/ingested event: update type cohort (Peers caches)
 	if s.Memory != nil {
@@ -45,38 +262,70 @@ func (s *SynapseRuntime) Ingest(event Event) (EventID, error) {
 	// 2) Process rules using a queue so derived events run AFTER materialization
 	queue := []Event{event}
 	var derivedEvents []Event
+	var watcherErrs []error
 	var contributedEvents = make(map[EventID][]Event)
 	var rulesId = make(map[EventID]string)
+	commit := func(anchor Event, contributors []Event, rule Rule) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		derived, errs, err := s.materializeDerived(anchor, contributors, rule)
+		if err != nil {
+			return err
+		}
+		watcherErrs = append(watcherErrs, errs...)
+		derivedEvents = append(derivedEvents, derived)
+		contributedEvents[derived.ID] = append(contributors, anchor)
+		rulesId[derived.ID] = rule.GetID()
+
+		// Now that derived is fully materialized, it is safe to run rules for it
+		queue = append(queue, derived)
+		return nil
+	}
 	for len(queue) > 0 {
 		cur := queue[0]
 		queue = queue[1:]
 
-		for _, rule := range s.rulesByType[cur.EventType] {
-			if rule.GetActionType() != DeriveNode {
+		for _, rule := range s.rulesFor(cur.EventType) {
+			if err := ctx.Err(); err != nil {
+				return IngestResult{}, err
+			}
+			action := rule.GetActionType()
+			if action != DeriveNode && action != AnnotateNode && action != DeriveEdge {
 				continue
 			}
 
 			ok, contributors, err := rule.Process(cur)
 			if err != nil && !errors.Is(err, ErrNotSatisfied) {
-				return uuid.UUID{}, err
+				return IngestResult{}, err
 			}
 			if !ok {
+				if action == DeriveNode && s.ReevaluateOnChange && cur.ID == event.ID {
+					if err := s.reevaluate(cur, rule, commit); err != nil {
+						return IngestResult{}, err
+					}
+				}
 				continue
 			}
 
-			derived, err := s.materializeDerived(cur, contributors, rule)
-
-			derivedEvents = append(derivedEvents, derived)
-			contributedEvents[derived.ID] = append(contributors, cur)
+			if action == AnnotateNode {
+				// later rules see the annotated anchor
+				if cur, err = s.annotate(cur, contributors, rule.GetActionTemplate().EventProps); err != nil {
+					return IngestResult{}, err
+				}
+				continue
+			}
+			if action == DeriveEdge {
+				if err := s.link(cur, contributors, relationFor(rule)); err != nil {
+					return IngestResult{}, err
+				}
+				continue
+			}
 
-			rulesId[derived.ID] = rule.GetID()
-			if err != nil {
-				return uuid.UUID{}, err
+			if err := commit(cur, contributors, rule); err != nil {
+				return IngestResult{}, err
 			}
 			//s.lookForPatterns(buildMotifKey(derived, contributors, rule.GetID()))
-
-			// Now that derived is fully materialized, it is safe to run rules for it
-			queue = append(queue, derived)
 		}
 	}
 
@@ -91,7 +340,35 @@ func (s *SynapseRuntime) Ingest(event Event) (EventID, error) {
 			rulesId[derivedEvent.ID]))
 	}
 
-	return event.ID, nil
+	result := IngestResult{EventID: event.ID, Derived: derivedEvents, WatcherErrors: watcherErrs}
+	for _, derived := range derivedEvents {
+		result.RuleIDs = append(result.RuleIDs, rulesId[derived.ID])
+	}
+	return result, nil
+}
+
+// IngestBatch ingests events oldest first, so time-window conditions see the same
+// history regardless of the caller's order (ties keep input order).
+// IDs are returned in input order. It stops at the first error, returning the IDs
+// ingested so far (zero for the rest).
+func (s *SynapseRuntime) IngestBatch(events []Event) ([]EventID, error) {
+	order := make([]int, len(events))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return events[order[a]].Timestamp.Before(events[order[b]].Timestamp)
+	})
+
+	ids := make([]EventID, len(events))
+	for _, i := range order {
+		id, err := s.Ingest(events[i])
+		if err != nil {
+			return ids, fmt.Errorf("event %d: %w", i, err)
+		}
+		ids[i] = id
+	}
+	return ids, nil
 }
 
 func findEarliestDate(events []Event) time.Time {
@@ -104,19 +381,20 @@ func findEarliestDate(events []Event) time.Time {
 	return earliest
 }
 
-func (s *SynapseRuntime) materializeDerived(anchor Event, matched []Event, rule Rule) (Event, error) {
+func (s *SynapseRuntime) materializeDerived(anchor Event, matched []Event, rule Rule) (Event, []error, error) {
 	template := rule.GetActionTemplate()
 	contributors := append(append([]Event(nil), matched...), anchor) // same as today :contentReference[oaicite:5]{index=5}
-	return s.materializeFromTemplate(template, contributors, rule.GetID())
+	return s.materializeFromTemplate(template, contributors, relationFor(rule), rule.GetID())
 }
 
 // Materialize a derived event from a template + explicit contributors.
-// originID can be ruleID or patternID. No rules executed here.
+// Contributors are linked with relation; originID can be ruleID or patternID. No rules executed here.
 func (s *SynapseRuntime) materializeFromTemplate(
 	template EventTemplate,
 	contributors []Event,
+	relation string,
 	originID string,
-) (Event, error) {
+) (Event, []error, error) {
 	derived := Event{
 		EventType:   template.EventType,
 		EventDomain: template.EventDomain,
@@ -125,45 +403,563 @@ func (s *SynapseRuntime) materializeFromTemplate(
 
 	derived.Timestamp = findEarliestDate(contributors) // matches existing behavior :contentReference[oaicite:2]{index=2}
 
-	// IMPORTANT: do NOT call s.Ingest here (edges must exist first). :contentReference[oaicite:3]{index=3}
-	id, err := s.Network.AddEvent(derived)
+	return s.materialize(derived, contributors, relation, originID)
+}
+
+// MaterializeWithoutRules stores an already-built derived event, links it to its contributors
+// with the given relation and notifies structural memory and pattern watchers.
+// Unlike Ingest, no rules are evaluated for the stored event. Recovered watcher panics
+// (see ErrWatcherPanic) are returned, joined, together with the stored event.
+func (s *SynapseRuntime) MaterializeWithoutRules(
+	derived Event,
+	contributorIDs []EventID,
+	relation string,
+	originID string,
+) (Event, error) {
+	var watcherErrs []error
+	err := s.withEvictionHeld(func() error {
+		contributors, err := s.Network.GetByIDs(contributorIDs)
+		if err != nil {
+			return err
+		}
+		derived, watcherErrs, err = s.materialize(derived, contributors, relation, originID)
+		return err
+	})
 	if err != nil {
 		return Event{}, err
 	}
-	derived.ID = id
+	return derived, errors.Join(watcherErrs...)
+}
+
+// withEvictionHeld runs fn with eviction held when the network evicts events on its own
+// (see EvictionHolder), so contributors stay until derived events are linked to them.
+func (s *SynapseRuntime) withEvictionHeld(fn func() error) error {
+	holder, ok := s.Network.(EvictionHolder)
+	if !ok {
+		return fn()
+	}
+
+	holder.HoldEviction()
+	err := fn()
+	if rerr := holder.ReleaseEviction(); err == nil {
+		err = rerr
+	}
+	return err
+}
+
+// materialize adds derived, its contributor edges and runs the commit hooks.
+//
+// Hooks run in a fixed order once all edges exist: Memory first (so watchers see updated
+// signatures and lineage stats), then PatternWatcher in slice order. A panicking watcher
+// does not stop the others; its panic is returned as an ErrWatcherPanic error.
+func (s *SynapseRuntime) materialize(
+	derived Event,
+	contributors []Event,
+	relation string,
+	originID string,
+) (Event, []error, error) {
+	// IMPORTANT: do NOT call s.Ingest here (edges must exist first). :contentReference[oaicite:3]{index=3}
+	derived, err := s.Network.AddEventFull(derived)
+	if err != nil {
+		return Event{}, nil, err
+	}
 
 	for _, ev := range contributors {
-		if err := s.Network.AddEdge(ev.ID, derived.ID, "trigger"); err != nil {
-			return Event{}, err
+		if err := s.Network.AddEdge(ev.ID, derived.ID, relation); err != nil {
+			return Event{}, nil, err
 		}
 	}
 
+	var watcherErrs []error
 	if s.Memory != nil {
 		s.Memory.OnMaterialized(derived, contributors, originID)
 		for _, w := range s.PatternWatcher { // your newer version already supports multi :contentReference[oaicite:4]{index=4}
-			w.OnMaterialized(derived, contributors, originID)
+			if err := notifyWatcher(w, derived, contributors, originID); err != nil {
+				watcherErrs = append(watcherErrs, err)
+			}
+		}
+	}
+
+	return derived, watcherErrs, nil
+}
+
+// ErrWatcherPanic wraps a panic recovered from a PatternObserver, so a misbehaving
+// watcher cannot crash ingestion. See IngestResult.WatcherErrors.
+var ErrWatcherPanic = errors.New("pattern watcher panicked")
+
+// notifyWatcher calls w.OnMaterialized (OnMaterializedErr for a FallibleObserver),
+// turning a panic into an ErrWatcherPanic error.
+func notifyWatcher(w PatternObserver, derived Event, contributors []Event, originID string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %T on %s (%s): %v", ErrWatcherPanic, w, derived.ID, derived.EventType, r)
+		}
+	}()
+	if f, ok := w.(FallibleObserver); ok {
+		return f.OnMaterializedErr(derived, contributors, originID)
+	}
+	w.OnMaterialized(derived, contributors, originID)
+	return nil
+}
+
+// annotate merges props into anchor and matched (once per event) and returns the updated anchor.
+func (s *SynapseRuntime) annotate(anchor Event, matched []Event, props EventProps) (Event, error) {
+	seen := make(map[EventID]struct{}, len(matched)+1)
+	for _, ev := range append(append([]Event(nil), matched...), anchor) {
+		if _, ok := seen[ev.ID]; ok {
+			continue
+		}
+		seen[ev.ID] = struct{}{}
+
+		stored, err := s.Network.GetByID(ev.ID)
+		if err != nil {
+			return anchor, err
+		}
+		merged := make(EventProps, len(stored.Properties)+len(props))
+		for k, v := range stored.Properties {
+			merged[k] = v
+		}
+		for k, v := range props {
+			merged[k] = v
+		}
+		stored.Properties = merged
+
+		if err := s.updateEvent(stored); err != nil {
+			return anchor, err
+		}
+		if stored.ID == anchor.ID {
+			anchor = stored
 		}
 	}
+	return anchor, nil
+}
 
-	return derived, nil
+// link adds matched -> anchor edges labelled relation (once per matched event, never a self-loop).
+func (s *SynapseRuntime) link(anchor Event, matched []Event, relation string) error {
+	seen := make(map[EventID]struct{}, len(matched))
+	for _, ev := range matched {
+		if _, ok := seen[ev.ID]; ok || ev.ID == anchor.ID {
+			continue
+		}
+		seen[ev.ID] = struct{}{}
+
+		if err := s.Network.AddEdge(ev.ID, anchor.ID, relation); err != nil {
+			return err
+		}
+		if s.Memory != nil {
+			s.Memory.OnEdgeAdded(ev.ID, anchor.ID)
+		}
+	}
+	return nil
+}
+
+// updateEvent overwrites a stored event through EventUpdater and bumps its type revision
+// so cached cohorts pick up the change.
+func (s *SynapseRuntime) updateEvent(event Event) error {
+	updater, ok := s.Network.(EventUpdater)
+	if !ok {
+		return errors.New("network does not support replacing events")
+	}
+	if err := updater.UpdateEvent(event); err != nil {
+		return err
+	}
+	if s.Memory != nil {
+		s.Memory.OnEventAdded(event)
+	}
+	return nil
+}
+
+// Flush prepares for a clean shutdown: it waits until in-flight Ingest calls have finished,
+// then flushes Memory and every PatternWatcher implementing Flusher (which forward it to
+// their listeners, e.g. composition watchers and their decision logs).
+// Returns ctx.Err() if ctx ends while waiting; flush errors are joined.
+func (s *SynapseRuntime) Flush(ctx context.Context) error {
+	if err := s.waitIdle(ctx); err != nil {
+		return err
+	}
+
+	var errs []error
+	if f, ok := s.Memory.(Flusher); ok {
+		errs = append(errs, f.Flush(ctx))
+	}
+	for _, w := range s.PatternWatcher {
+		if f, ok := w.(Flusher); ok {
+			errs = append(errs, f.Flush(ctx))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *SynapseRuntime) beginIngest() {
+	s.ingestMu.Lock()
+	defer s.ingestMu.Unlock()
+	s.inflight++
+}
+
+func (s *SynapseRuntime) endIngest() {
+	s.ingestMu.Lock()
+	defer s.ingestMu.Unlock()
+	s.inflight--
+	if s.inflight == 0 && s.idle != nil {
+		close(s.idle)
+		s.idle = nil
+	}
+}
+
+// waitIdle blocks until no Ingest call is in flight or ctx ends.
+func (s *SynapseRuntime) waitIdle(ctx context.Context) error {
+	s.ingestMu.Lock()
+	if s.inflight == 0 {
+		s.ingestMu.Unlock()
+		return nil
+	}
+	if s.idle == nil {
+		s.idle = make(chan struct{})
+	}
+	idle := s.idle
+	s.ingestMu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// reevaluate re-runs rule for the existing derived events the ingested leaf could belong to,
+// after the leaf alone did not satisfy it.
+//
+// Each candidate (see reevaluationCandidates) is retracted and derived again, anchored at
+// the leaf first and then at its former contributors, newest first; anchors that already
+// joined a new derivation are skipped. A candidate nobody re-derives stays retracted.
+func (s *SynapseRuntime) reevaluate(
+	leaf Event,
+	rule Rule,
+	commit func(anchor Event, contributors []Event, rule Rule) error,
+) error {
+	candidates, err := s.reevaluationCandidates(leaf, rule)
+	if err != nil {
+		return err
+	}
+
+	for _, candidate := range candidates {
+		children, err := s.Network.Children(candidate.ID)
+		if err != nil {
+			return err
+		}
+		if err := s.retractDerived(candidate, children, rule.GetID()); err != nil {
+			return err
+		}
+
+		sort.SliceStable(children, func(i, j int) bool {
+			return children[i].Timestamp.After(children[j].Timestamp)
+		})
+		for _, anchor := range append([]Event{leaf}, children...) {
+			parents, err := s.Network.Parents(anchor.ID)
+			if err != nil {
+				return err
+			}
+			if len(parents) > 0 {
+				continue
+			}
+
+			ok, contributors, err := rule.Process(anchor)
+			if err != nil && !errors.Is(err, ErrNotSatisfied) {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			if err := commit(anchor, contributors, rule); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// reevaluationCandidates returns parentless events derived by rule (matched by edge relation)
+// with a contributor of the leaf's type. When the rule's condition is time-bounded, that
+// contributor must also lie within the widest window of the leaf. Oldest first.
+func (s *SynapseRuntime) reevaluationCandidates(leaf Event, rule Rule) ([]Event, error) {
+	var window time.Duration
+	var bounded bool
+	if r, ok := rule.(*DeriveEventRule); ok && r.Condition != nil {
+		window, bounded = r.Condition.maxTimeWindow()
+	}
+	relation := relationFor(rule)
+
+	derived, err := s.Network.GetByType(rule.GetActionTemplate().EventType)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []Event
+	for _, d := range derived {
+		out, err := s.Network.OutEdges(d.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(out) > 0 {
+			// Retracting d would orphan the events derived from it.
+			continue
+		}
+
+		in, err := s.Network.InEdges(d.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, edge := range in {
+			if edge.Relation != relation {
+				continue
+			}
+			child, err := s.Network.GetByID(edge.From)
+			if err != nil {
+				return nil, err
+			}
+			if child.EventType != leaf.EventType {
+				continue
+			}
+			if gap := leaf.Timestamp.Sub(child.Timestamp); bounded && (gap > window || gap < -window) {
+				continue
+			}
+			candidates = append(candidates, d)
+			break
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Timestamp.Before(candidates[j].Timestamp)
+	})
+	return candidates, nil
+}
+
+// retractDerived removes a derived event after undoing its materialization in the
+// pattern watchers and Memory (see MaterializationRetractor), mirroring materialize.
+// Watchers go first: they look up derived's signature in Memory.
+func (s *SynapseRuntime) retractDerived(derived Event, contributors []Event, originID string) error {
+	if s.Memory != nil {
+		for _, w := range s.PatternWatcher {
+			if r, ok := w.(MaterializationRetractor); ok {
+				r.OnRetracted(derived, contributors, originID)
+			}
+		}
+		if r, ok := s.Memory.(MaterializationRetractor); ok {
+			r.OnRetracted(derived, contributors, originID)
+		}
+	}
+	return s.retract(derived.ID)
+}
+
+// retract removes an event, through EvalNetwork when set so structural caches are invalidated.
+func (s *SynapseRuntime) retract(id EventID) error {
+	if s.ConflictResolver != nil {
+		if ev, err := s.Network.GetByID(id); err == nil {
+			s.content.forget(ContentHash(ev), id)
+		}
+	}
+	if s.EvalNetwork != nil {
+		return s.EvalNetwork.RemoveEvent(id)
+	}
+	return s.Network.RemoveEvent(id)
+}
+
+// PruneOlderThan removes leaf events (events without contributors) with a Timestamp before
+// cutoff, together with their edges, and returns how many were removed. Derived events are
+// kept, even once all their contributors are pruned (Validate then reports them as childless).
+func (s *SynapseRuntime) PruneOlderThan(cutoff time.Time) (int, error) {
+	old, err := s.Network.GetByTimeRange(time.Time{}, cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	// Collect first: removing a contributor must not turn a derived event into a leaf to prune.
+	var leaves []EventID
+	for _, ev := range old {
+		if !ev.Timestamp.Before(cutoff) {
+			continue
+		}
+		in, err := s.Network.InEdges(ev.ID)
+		if err != nil {
+			return 0, err
+		}
+		if len(in) == 0 {
+			leaves = append(leaves, ev.ID)
+		}
+	}
+
+	for i, id := range leaves {
+		if err := s.retract(id); err != nil {
+			return i, err
+		}
+	}
+	return len(leaves), nil
+}
+
+// ErrChildlessDerived marks a derived event without any contributor edge.
+var ErrChildlessDerived = errors.New("derived event has no contributors")
+
+// Validate checks graph invariants that materialization should guarantee.
+// Currently: every event whose type is produced by a registered DeriveNode rule
+// has at least one contributor (child) edge. All violations are reported, joined;
+// each wraps ErrChildlessDerived.
+func (s *SynapseRuntime) Validate() error {
+	derivedTypes := make(map[EventType]struct{})
+	s.rulesMu.RLock()
+	for _, rules := range s.rulesByType {
+		for _, rule := range rules {
+			if rule.GetActionType() == DeriveNode {
+				derivedTypes[rule.GetActionTemplate().EventType] = struct{}{}
+			}
+		}
+	}
+	s.rulesMu.RUnlock()
+
+	types := make([]string, 0, len(derivedTypes))
+	for t := range derivedTypes {
+		types = append(types, t)
+	}
+	types = stableSortStrings(types)
+
+	var errs []error
+	for _, t := range types {
+		events, err := s.Network.GetByType(t)
+		if err != nil {
+			return err
+		}
+		for _, ev := range events {
+			children, err := s.Network.Children(ev.ID)
+			if err != nil {
+				return err
+			}
+			if len(children) == 0 {
+				errs = append(errs, fmt.Errorf("%w: %s (%s)", ErrChildlessDerived, ev.ID, ev.EventType))
+			}
+		}
+	}
+	return errors.Join(errs...)
 }
 
 func (s *SynapseRuntime) GetNetwork() EventNetwork {
 	return s.Network
 }
 
-func (s *SynapseRuntime) HotMotifs(minCount int) []MotifKey {
+// HotMotif is a motif returned by HotMotifs together with its stats.
+type HotMotif struct {
+	Key MotifKey
+	MotifStats
+}
+
+// HotMotifs returns the motifs that occurred at least minCount times, most frequent first
+// (ties most recently seen first).
+func (s *SynapseRuntime) HotMotifs(minCount int) []HotMotif {
+	return s.hotMotifs(s.Memory.ListMotifs(), minCount)
+}
+
+// HotMotifsForType is HotMotifs restricted to the motifs deriving events of type t.
+func (s *SynapseRuntime) HotMotifsForType(t EventType, minCount int) []HotMotif {
+	return s.HotMotifsFor(t, "", minCount)
+}
+
+// HotMotifsFor is HotMotifs restricted to the motifs deriving events of type t in domain d.
+// An empty t or d matches any type or domain.
+func (s *SynapseRuntime) HotMotifsFor(t EventType, d EventDomain, minCount int) []HotMotif {
 	keys := s.Memory.ListMotifs()
-	out := make([]MotifKey, 0)
+	if t != "" {
+		keys = s.Memory.MotifsByDerivedType(t)
+	}
+	if d != "" {
+		inDomain := make([]MotifKey, 0, len(keys))
+		for _, k := range keys {
+			if k.DerivedDomain == d {
+				inDomain = append(inDomain, k)
+			}
+		}
+		keys = inDomain
+	}
+	return s.hotMotifs(keys, minCount)
+}
+
+func (s *SynapseRuntime) hotMotifs(keys []MotifKey, minCount int) []HotMotif {
+	out := make([]HotMotif, 0)
 	for _, k := range keys {
 		st, ok := s.Memory.GetMotifStats(k)
 		if ok && st.Count >= minCount {
-			out = append(out, k)
+			out = append(out, HotMotif{Key: k, MotifStats: st})
 		}
 	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if !a.LastSeen.Equal(b.LastSeen) {
+			return a.LastSeen.After(b.LastSeen)
+		}
+		return motifKeyLess(a.Key, b.Key)
+	})
 	return out
 }
 
+// TrendingMotifs returns the top k motifs by recency-weighted score.
+// Returns nil when Memory does not implement MotifTrendTracker.
+func (s *SynapseRuntime) TrendingMotifs(k int) []MotifKeyScore {
+	tracker, ok := s.Memory.(MotifTrendTracker)
+	if !ok {
+		return nil
+	}
+	return tracker.TrendingMotifs(k)
+}
+
+// ContributorRank scores an event by the derivations it powers.
+type ContributorRank struct {
+	Event Event
+	// Parents is the number of events derived directly from Event (out-degree)
+	Parents int
+	// Downstream is the number of distinct events derived from Event, transitively
+	Downstream int
+	// Score = Parents + Downstream, so direct fan-out counts twice
+	Score int
+}
+
+// TopContributors ranks events by Score (highest first; ties oldest first) and returns the top n,
+// or all contributors when n <= 0. Events that feed nothing are left out.
+// It walks the derivations of every event, so it is meant for offline impact analysis.
+func (s *SynapseRuntime) TopContributors(n int) []ContributorRank {
+	events, err := s.Network.AllEvents()
+	if err != nil {
+		return nil
+	}
+
+	var ranks []ContributorRank
+	for _, ev := range events {
+		parents, err := s.Network.Parents(ev.ID)
+		if err != nil || len(parents) == 0 {
+			continue
+		}
+		downstream, err := s.Network.Ancestors(ev.ID, math.MaxInt)
+		if err != nil {
+			continue
+		}
+		ranks = append(ranks, ContributorRank{
+			Event:      ev,
+			Parents:    len(parents),
+			Downstream: len(downstream),
+			Score:      len(parents) + len(downstream),
+		})
+	}
+
+	// events come oldest first, so a stable sort keeps ties oldest first
+	sort.SliceStable(ranks, func(i, j int) bool {
+		return ranks[i].Score > ranks[j].Score
+	})
+	if n > 0 && len(ranks) > n {
+		ranks = ranks[:n]
+	}
+	return ranks
+}
+
 func (s *SynapseRuntime) lookForPatterns(key MotifKey) (MotifKey, int) {
 	st, ok := s.Memory.GetMotifStats(key)
 	if ok {
@@ -181,15 +977,5 @@ func (s *SynapseRuntime) OnRecognize(motifKey MotifKey, count int) {
 }
 
 func buildMotifKey(derived Event, contributors []Event, ruleID string) MotifKey {
-	types := make([]string, 0, len(contributors))
-	for _, c := range contributors {
-		types = append(types, string(c.EventType))
-	}
-	types = stableSortStrings(types)
-	return MotifKey{
-		DerivedType:    derived.EventType,
-		DerivedDomain:  derived.EventDomain,
-		ContributorSig: joinWithSep(types, "|"),
-		RuleID:         ruleID,
-	}
+	return BuildMotifKey(derived, contributors, ruleID)
 }
@@ -1,7 +1,12 @@
 package event_network
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -138,7 +143,7 @@ func Test_CrossDomainEvents(t *testing.T) {
 		},
 	}
 
-	synapse := NewSynapse(configs)
+	synapse := newSynapseStoringDuplicates(configs)
 
 	synapse.RegisterRuleForTypes([]EventType{ZebrasMigration, UnusualBirdBehavior},
 		NewDeriveEventRule("2",
@@ -188,8 +193,9 @@ func Test_CrossDomainEvents(t *testing.T) {
 					HowManyOrMore: true,
 				},
 				TimeWindow: &TimeWindow{
-					Within:   8,
-					TimeUnit: Hour,
+					Within:    8,
+					TimeUnit:  Hour,
+					Direction: Both, // the two derived signals may arrive in either order
 				},
 			}).Or().HasPeers(MultipleAnimalUnexpectedBehavior, Conditions{
 				Counter: &Counter{
@@ -197,8 +203,9 @@ func Test_CrossDomainEvents(t *testing.T) {
 					HowManyOrMore: true,
 				},
 				TimeWindow: &TimeWindow{
-					Within:   8,
-					TimeUnit: Hour,
+					Within:    8,
+					TimeUnit:  Hour,
+					Direction: Both, // the two derived signals may arrive in either order
 				},
 			},
 			), getPotentialNaturalCatastrophicDerivedEventTemplate(),
@@ -323,7 +330,7 @@ func Test_CrossDomainEventsWithPatterns(t *testing.T) {
 		},
 	}
 
-	synapse := NewSynapse(configs)
+	synapse := newSynapseStoringDuplicates(configs)
 
 	// Set the synapse on composition watcher now that synapse is created
 	compositionWatcher.Synapse = synapse
@@ -460,6 +467,63 @@ func TestSynapseRuntime_HotMotifs(t *testing.T) {
 	require.LessOrEqual(t, len(hotMotifsHigh), len(hotMotifs))
 }
 
+func TestSynapseRuntime_HotMotifsForType(t *testing.T) {
+	synapse := newSynapseStoringDuplicates([]PatternConfig{})
+
+	peers := func(eventType EventType) *Condition {
+		return NewCondition().HasPeers(eventType, Conditions{
+			Counter: &Counter{HowMany: 1, HowManyOrMore: true},
+		})
+	}
+	synapse.RegisterRule(CpuStatusChanged, NewDeriveEventRule("cpu",
+		peers(CpuStatusChanged), EventTemplate{EventType: CpuCritical, EventDomain: InfraDomain}))
+	synapse.RegisterRule(MemoryStatusChanged, NewDeriveEventRule("memory",
+		peers(MemoryStatusChanged), EventTemplate{EventType: MemoryCritical, EventDomain: InfraDomain}))
+	synapse.RegisterRule(MinorTremors, NewDeriveEventRule("tremors",
+		peers(MinorTremors), getMinorTremorDerivedEventTemplate()))
+
+	for i := 0; i < 3; i++ {
+		_, err := synapse.Ingest(createCpuStatusChangedEvent(92, "critical"))
+		require.NoError(t, err)
+		_, err = synapse.Ingest(createMemoryStatusChangedEvent(91, "critical"))
+		require.NoError(t, err)
+		_, err = synapse.Ingest(createMinorTremorsEvent(time.Now()))
+		require.NoError(t, err)
+	}
+
+	cpu := synapse.HotMotifsForType(CpuCritical, 1)
+	require.NotEmpty(t, cpu)
+	for _, hot := range cpu {
+		require.Equal(t, CpuCritical, hot.Key.DerivedType)
+		require.Equal(t, "cpu", hot.Key.RuleID)
+		require.GreaterOrEqual(t, hot.Count, 1)
+		require.False(t, hot.LastSeen.IsZero())
+	}
+	for i := 1; i < len(cpu); i++ {
+		require.GreaterOrEqual(t, cpu[i-1].Count, cpu[i].Count, "most frequent first")
+	}
+
+	memory := synapse.HotMotifsForType(MemoryCritical, 1)
+	tremors := synapse.HotMotifsForType(HighFrequencyOfMinorTremors, 1)
+	require.NotEmpty(t, tremors)
+	all := synapse.HotMotifs(1)
+	require.Len(t, all, len(cpu)+len(memory)+len(tremors))
+	require.Empty(t, synapse.HotMotifsForType(CpuCritical, 100))
+	require.Empty(t, synapse.HotMotifsForType(CpuStatusChanged, 1))
+
+	t.Run("domain filter", func(t *testing.T) {
+		infra := synapse.HotMotifsFor("", InfraDomain, 1)
+		require.Len(t, infra, len(cpu)+len(memory))
+		for _, hot := range infra {
+			require.Equal(t, InfraDomain, hot.Key.DerivedDomain)
+		}
+		require.Equal(t, tremors, synapse.HotMotifsFor("", Geology, 1))
+		require.Equal(t, cpu, synapse.HotMotifsFor(CpuCritical, InfraDomain, 1))
+		require.Empty(t, synapse.HotMotifsFor(CpuCritical, Geology, 1))
+		require.Equal(t, all, synapse.HotMotifsFor("", "", 1))
+	})
+}
+
 func TestSynapseRuntime_OnRecognize(t *testing.T) {
 	synapse := NewSynapse([]PatternConfig{})
 
@@ -467,7 +531,7 @@ func TestSynapseRuntime_OnRecognize(t *testing.T) {
 	motifKey := MotifKey{
 		DerivedType:    CpuCritical,
 		DerivedDomain:  InfraDomain,
-		ContributorSig: "cpu_status_changed|cpu_status_changed",
+		ContributorSig: "cpu_status_changed@infra_domain|cpu_status_changed@infra_domain",
 		RuleID:         "test-rule",
 	}
 
@@ -519,6 +583,24 @@ func TestSynapseRuntime_Ingest_ErrorHandling(t *testing.T) {
 		require.Error(t, err)
 		require.NotContains(t, err.Error(), "ErrNotSatisfied")
 	})
+
+	t.Run("rejects events without type or domain", func(t *testing.T) {
+		synapse := NewSynapse([]PatternConfig{})
+
+		_, err := synapse.Ingest(Event{EventDomain: InfraDomain, Timestamp: time.Now()})
+		require.ErrorContains(t, err, "empty EventType")
+
+		_, err = synapse.Ingest(Event{EventType: CpuStatusChanged, Timestamp: time.Now()})
+		require.ErrorContains(t, err, "empty EventDomain")
+
+		leaves, err := synapse.GetNetwork().GetByType("")
+		require.NoError(t, err)
+		require.Empty(t, leaves)
+
+		id, err := synapse.Ingest(createCpuStatusChangedEvent(50, "ok"))
+		require.NoError(t, err)
+		require.NotEqual(t, uuid.Nil, id)
+	})
 }
 
 func TestSynapseRuntime_materializeFromTemplate_ErrorHandling(t *testing.T) {
@@ -538,7 +620,7 @@ func TestSynapseRuntime_materializeFromTemplate_ErrorHandling(t *testing.T) {
 		contributors := []Event{contributor}
 
 		// Should succeed with valid template
-		derived, err := synapse.materializeFromTemplate(template, contributors, "test-rule")
+		derived, _, err := synapse.materializeFromTemplate(template, contributors, "trigger", "test-rule")
 		require.NoError(t, err)
 		require.NotEmpty(t, derived.ID)
 	})
@@ -557,7 +639,7 @@ func TestSynapseRuntime_materializeFromTemplate_ErrorHandling(t *testing.T) {
 
 		contributors := []Event{contributor}
 
-		derived, err := synapse.materializeFromTemplate(template, contributors, "test-rule")
+		derived, _, err := synapse.materializeFromTemplate(template, contributors, "trigger", "test-rule")
 		require.NoError(t, err)
 		require.NotEmpty(t, derived.ID)
 	})
@@ -575,7 +657,7 @@ func TestSynapseRuntime_materializeFromTemplate_ErrorHandling(t *testing.T) {
 
 		contributors := []Event{contributor}
 
-		derived, err := synapse.materializeFromTemplate(template, contributors, "test-rule")
+		derived, _, err := synapse.materializeFromTemplate(template, contributors, "trigger", "test-rule")
 		require.NoError(t, err)
 		require.NotEmpty(t, derived.ID)
 	})
@@ -596,7 +678,7 @@ func TestSynapseRuntime_materializeFromTemplate_ErrorHandling(t *testing.T) {
 		contributors := []Event{contributor}
 
 		// Should fail when trying to add edge
-		_, err := synapse.materializeFromTemplate(template, contributors, "test-rule")
+		_, _, err := synapse.materializeFromTemplate(template, contributors, "trigger", "test-rule")
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "from event not found")
 	})
@@ -625,7 +707,7 @@ func TestSynapseRuntime_materializeFromTemplate_ErrorHandling(t *testing.T) {
 		contributors := []Event{contributor}
 
 		// Should succeed even without memory
-		derived, err := synapse.materializeFromTemplate(template, contributors, "test-rule")
+		derived, _, err := synapse.materializeFromTemplate(template, contributors, "trigger", "test-rule")
 		require.NoError(t, err)
 		require.NotEmpty(t, derived.ID)
 	})
@@ -648,7 +730,7 @@ func TestSynapseRuntime_materializeFromTemplate_ErrorHandling(t *testing.T) {
 
 		contributors := []Event{contributor1, contributor2}
 
-		derived, err := synapse.materializeFromTemplate(template, contributors, "test-rule")
+		derived, _, err := synapse.materializeFromTemplate(template, contributors, "trigger", "test-rule")
 		require.NoError(t, err)
 		require.NotEmpty(t, derived.ID)
 
@@ -666,7 +748,7 @@ func TestSynapseRuntime_lookForPatterns(t *testing.T) {
 	motifKey := MotifKey{
 		DerivedType:    CpuCritical,
 		DerivedDomain:  InfraDomain,
-		ContributorSig: "cpu_status_changed",
+		ContributorSig: "cpu_status_changed@infra_domain",
 		RuleID:         "test-rule",
 	}
 
@@ -887,7 +969,7 @@ func Test_AI_Safety_CrossDomain_Escalation_WithPatternComposition(t *testing.T)
 		},
 	}
 
-	synapse = NewSynapse(configs)
+	synapse = newSynapseStoringDuplicates(configs)
 
 	// Now that Synapse exists, attach the composition watcher.
 	// Listener can be nil/no-op if not needed; keeping a POC listener for visibility.
@@ -939,7 +1021,7 @@ func Test_AI_Safety_CrossDomain_Escalation_WithPatternComposition(t *testing.T)
 						HowMany:       1,
 						HowManyOrMore: true,
 					},
-					TimeWindow: &TimeWindow{Within: 6, TimeUnit: Hour},
+					TimeWindow: &TimeWindow{Within: 6, TimeUnit: Hour, Direction: Both},
 				}).
 				Or().
 				HasPeers(RiskyOutputCluster, Conditions{
@@ -947,7 +1029,7 @@ func Test_AI_Safety_CrossDomain_Escalation_WithPatternComposition(t *testing.T)
 						HowMany:       1,
 						HowManyOrMore: true,
 					},
-					TimeWindow: &TimeWindow{Within: 6, TimeUnit: Hour},
+					TimeWindow: &TimeWindow{Within: 6, TimeUnit: Hour, Direction: Both},
 				}),
 			EventTemplate{
 				EventType:   CrossDomainMisuseSignal,
@@ -967,7 +1049,7 @@ func Test_AI_Safety_CrossDomain_Escalation_WithPatternComposition(t *testing.T)
 						HowMany:       1,
 						HowManyOrMore: true,
 					},
-					TimeWindow: &TimeWindow{Within: 24, TimeUnit: Hour},
+					TimeWindow: &TimeWindow{Within: 24, TimeUnit: Hour, Direction: Both},
 				}).
 				Or().
 				HasPeers(RedTeamEvasionSuccess, Conditions{
@@ -975,7 +1057,7 @@ func Test_AI_Safety_CrossDomain_Escalation_WithPatternComposition(t *testing.T)
 						HowMany:       1,
 						HowManyOrMore: true,
 					},
-					TimeWindow: &TimeWindow{Within: 24, TimeUnit: Hour},
+					TimeWindow: &TimeWindow{Within: 24, TimeUnit: Hour, Direction: Both},
 				}),
 			EventTemplate{
 				EventType:   EmergentCapabilityIndicator,
@@ -995,7 +1077,7 @@ func Test_AI_Safety_CrossDomain_Escalation_WithPatternComposition(t *testing.T)
 						HowMany:       1,
 						HowManyOrMore: true,
 					},
-					TimeWindow: &TimeWindow{Within: 7, TimeUnit: Day},
+					TimeWindow: &TimeWindow{Within: 7, TimeUnit: Day, Direction: Both},
 				}).
 				Or().
 				HasPeers(ExternalIncidentReport, Conditions{
@@ -1003,7 +1085,7 @@ func Test_AI_Safety_CrossDomain_Escalation_WithPatternComposition(t *testing.T)
 						HowMany:       1,
 						HowManyOrMore: true,
 					},
-					TimeWindow: &TimeWindow{Within: 7, TimeUnit: Day},
+					TimeWindow: &TimeWindow{Within: 7, TimeUnit: Day, Direction: Both},
 				}),
 			EventTemplate{
 				EventType:   CredibleHarmTrajectory,
@@ -1023,7 +1105,7 @@ func Test_AI_Safety_CrossDomain_Escalation_WithPatternComposition(t *testing.T)
 						HowMany:       1,
 						HowManyOrMore: true,
 					},
-					TimeWindow: &TimeWindow{Within: 48, TimeUnit: Hour},
+					TimeWindow: &TimeWindow{Within: 48, TimeUnit: Hour, Direction: Both},
 				}).
 				Or().
 				HasPeers(PolicyExceptionRequest, Conditions{
@@ -1031,7 +1113,7 @@ func Test_AI_Safety_CrossDomain_Escalation_WithPatternComposition(t *testing.T)
 						HowMany:       1,
 						HowManyOrMore: true,
 					},
-					TimeWindow: &TimeWindow{Within: 48, TimeUnit: Hour},
+					TimeWindow: &TimeWindow{Within: 48, TimeUnit: Hour, Direction: Both},
 				}),
 			EventTemplate{
 				EventType:   GovernanceActionRequired,
@@ -1126,3 +1208,1018 @@ func (p *patternListenerWrapper) OnPatternRepeated(m PatternMatch) {
 		p.onPatternRepeated(m)
 	}
 }
+
+func TestSynapseRuntime_Validate_ChildlessDerived(t *testing.T) {
+	synapse := newSynapseStoringDuplicates([]PatternConfig{})
+	synapse.RegisterRule(MinorTremors, NewDeriveEventRule("tremors",
+		NewCondition().HasPeers(MinorTremors, Conditions{
+			Counter: &Counter{HowMany: 1, HowManyOrMore: true},
+		}),
+		getMinorTremorDerivedEventTemplate(),
+	))
+
+	now := time.Now()
+	_, err := synapse.Ingest(createMinorTremorsEvent(now))
+	require.NoError(t, err)
+	_, err = synapse.Ingest(createMinorTremorsEvent(now.Add(time.Minute)))
+	require.NoError(t, err)
+
+	derived, err := synapse.GetNetwork().GetByType(HighFrequencyOfMinorTremors)
+	require.NoError(t, err)
+	require.NotEmpty(t, derived)
+	require.NoError(t, synapse.Validate(), "materialized events have contributors")
+
+	// Bypass materialization: a derived-type event without contributor edges
+	orphanID, err := synapse.GetNetwork().AddEvent(Event{
+		EventType:   HighFrequencyOfMinorTremors,
+		EventDomain: Geology,
+		Timestamp:   now,
+	})
+	require.NoError(t, err)
+
+	err = synapse.Validate()
+	require.ErrorIs(t, err, ErrChildlessDerived)
+	require.ErrorContains(t, err, orphanID.String())
+	for _, ev := range derived {
+		require.NotContains(t, err.Error(), ev.ID.String())
+	}
+
+	// Leaf types are never flagged
+	_, err = synapse.GetNetwork().AddEvent(createMinorTremorsEvent(now))
+	require.NoError(t, err)
+	require.Len(t, strings.Split(synapse.Validate().Error(), "\n"), 1)
+}
+
+func TestSynapseRuntime_RuleRelationLabel(t *testing.T) {
+	newRule := func(id string) *DeriveEventRule {
+		return NewDeriveEventRule(id,
+			NewCondition().HasPeers(MinorTremors, Conditions{
+				Counter: &Counter{HowMany: 1, HowManyOrMore: true},
+			}),
+			getMinorTremorDerivedEventTemplate(),
+		)
+	}
+
+	t.Run("defaults to trigger with the rule ID", func(t *testing.T) {
+		synapse := newSynapseStoringDuplicates([]PatternConfig{})
+		synapse.RegisterRule(MinorTremors, newRule("tremors"))
+
+		now := time.Now()
+		_, err := synapse.Ingest(createMinorTremorsEvent(now))
+		require.NoError(t, err)
+		_, err = synapse.Ingest(createMinorTremorsEvent(now.Add(time.Minute)))
+		require.NoError(t, err)
+
+		counts := synapse.Network.(*InMemoryEventNetwork).RelationCounts()
+		require.Equal(t, map[string]int{"trigger:tremors": 2}, counts)
+	})
+
+	t.Run("explicit relation", func(t *testing.T) {
+		synapse := newSynapseStoringDuplicates([]PatternConfig{})
+		rule := newRule("tremors")
+		rule.Relation = "seismic_cluster"
+		synapse.RegisterRule(MinorTremors, rule)
+
+		now := time.Now()
+		_, err := synapse.Ingest(createMinorTremorsEvent(now))
+		require.NoError(t, err)
+		_, err = synapse.Ingest(createMinorTremorsEvent(now.Add(time.Minute)))
+		require.NoError(t, err)
+
+		counts := synapse.Network.(*InMemoryEventNetwork).RelationCounts()
+		require.Equal(t, map[string]int{"seismic_cluster": 2}, counts)
+	})
+}
+
+func TestSynapseRuntime_ReevaluateOnChange(t *testing.T) {
+	base := time.Date(2026, 4, 25, 5, 0, 0, 0, time.UTC)
+	build := func(reevaluate bool) *SynapseRuntime {
+		synapse := newSynapseStoringDuplicates([]PatternConfig{})
+		synapse.ReevaluateOnChange = reevaluate
+		synapse.RegisterRule(MinorTremors, NewDeriveEventRule("tremors",
+			NewCondition().HasPeers(MinorTremors, Conditions{
+				Counter:    &Counter{HowMany: 2, HowManyOrMore: true},
+				TimeWindow: &TimeWindow{Within: 1, TimeUnit: Hour},
+			}),
+			getMinorTremorDerivedEventTemplate(),
+		))
+		for _, offset := range []time.Duration{0, 10 * time.Minute, 20 * time.Minute} {
+			_, err := synapse.Ingest(createMinorTremorsEvent(base.Add(offset)))
+			require.NoError(t, err)
+		}
+		return synapse
+	}
+	cluster := func(synapse *SynapseRuntime) (Event, []Event) {
+		clusters, err := synapse.GetNetwork().GetByType(HighFrequencyOfMinorTremors)
+		require.NoError(t, err)
+		require.Len(t, clusters, 1)
+		members, err := synapse.GetNetwork().Children(clusters[0].ID)
+		require.NoError(t, err)
+		return clusters[0], members
+	}
+
+	t.Run("disabled: late contributor stays outside the cluster", func(t *testing.T) {
+		synapse := build(false)
+		before, _ := cluster(synapse)
+
+		lateID, err := synapse.Ingest(createMinorTremorsEvent(base.Add(5 * time.Minute)))
+		require.NoError(t, err)
+
+		after, members := cluster(synapse)
+		require.Equal(t, before.ID, after.ID)
+		require.Len(t, members, 3)
+		require.NotContains(t, collectIDs(members), lateID)
+	})
+
+	t.Run("enabled: late contributor joins the cluster", func(t *testing.T) {
+		synapse := build(true)
+		before, _ := cluster(synapse)
+
+		lateID, err := synapse.Ingest(createMinorTremorsEvent(base.Add(5 * time.Minute)))
+		require.NoError(t, err)
+
+		after, members := cluster(synapse)
+		require.NotEqual(t, before.ID, after.ID, "the cluster is re-derived")
+		require.Len(t, members, 4)
+		require.Contains(t, collectIDs(members), lateID)
+
+		_, err = synapse.GetNetwork().GetByID(before.ID)
+		require.Error(t, err, "the outdated cluster is retracted")
+		require.NoError(t, synapse.Validate())
+	})
+
+	t.Run("enabled: re-derivation does not inflate memory or re-fire watchers", func(t *testing.T) {
+		listener := &testPatternListener{}
+		synapse := newSynapseStoringDuplicates([]PatternConfig{{Depth: 1, MinCount: 2, PatternListener: listener}})
+		synapse.ReevaluateOnChange = true
+		synapse.RegisterRule(MinorTremors, NewDeriveEventRule("tremors",
+			NewCondition().HasPeers(MinorTremors, Conditions{
+				Counter:    &Counter{HowMany: 2, HowManyOrMore: true},
+				TimeWindow: &TimeWindow{Within: 1, TimeUnit: Hour},
+			}),
+			getMinorTremorDerivedEventTemplate(),
+		))
+		for _, offset := range []time.Duration{0, 10, 20, 5, 6, 7} {
+			_, err := synapse.Ingest(createMinorTremorsEvent(base.Add(offset * time.Minute)))
+			require.NoError(t, err)
+		}
+
+		survivor, _ := cluster(synapse)
+
+		memory := synapse.Memory.(*InMemoryStructuralMemory)
+		motifs := memory.MotifsByDerivedType(HighFrequencyOfMinorTremors)
+		require.Len(t, motifs, 1)
+		stats, ok := memory.GetMotifStats(motifs[0])
+		require.True(t, ok)
+		require.Equal(t, 1, stats.Count)
+		require.Len(t, stats.Instances, 1)
+		require.Equal(t, survivor.ID, stats.Instances[0].DerivedID)
+
+		for _, key := range memory.LineagesForType(HighFrequencyOfMinorTremors) {
+			lineage, ok := memory.GetLineageStats(key)
+			require.True(t, ok)
+			require.Equal(t, 1, lineage.Count)
+			require.Equal(t, map[string]int{"tremors": 1}, lineage.RuleCounts)
+		}
+		require.Len(t, memory.LineagesForType(HighFrequencyOfMinorTremors), memory.MaxSignatureDepth())
+		require.Empty(t, listener.All(), "each shape occurred once")
+	})
+
+	t.Run("enabled: contributors outside the window leave the cluster alone", func(t *testing.T) {
+		synapse := build(true)
+		before, _ := cluster(synapse)
+
+		_, err := synapse.Ingest(createMinorTremorsEvent(base.Add(5 * time.Hour)))
+		require.NoError(t, err)
+
+		after, members := cluster(synapse)
+		require.Equal(t, before.ID, after.ID)
+		require.Len(t, members, 3)
+	})
+}
+
+func TestSynapseRuntime_UnregisterRule(t *testing.T) {
+	peers := NewCondition().HasPeers(MinorTremors, Conditions{
+		Counter: &Counter{HowMany: 1, HowManyOrMore: true},
+	})
+	tremorTemplate := getMinorTremorDerivedEventTemplate()
+	alertTemplate := EventTemplate{EventType: "tremor_alert", EventDomain: Geology}
+
+	synapse := newSynapseStoringDuplicates([]PatternConfig{})
+	synapse.RegisterRule(MinorTremors, NewDeriveEventRule("tremors", peers, tremorTemplate))
+	synapse.RegisterRuleForTypes([]EventType{MinorTremors, "aftershock"}, NewDeriveEventRule("alert", peers, alertTemplate))
+
+	require.False(t, synapse.UnregisterRule(MinorTremors, "unknown"))
+	require.False(t, synapse.UnregisterRule("unknown_type", "tremors"))
+	require.True(t, synapse.UnregisterRule(MinorTremors, "tremors"))
+	require.False(t, synapse.UnregisterRule(MinorTremors, "tremors"), "already removed")
+
+	now := time.Now()
+	_, err := synapse.Ingest(createMinorTremorsEvent(now))
+	require.NoError(t, err)
+	_, err = synapse.Ingest(createMinorTremorsEvent(now.Add(time.Minute)))
+	require.NoError(t, err)
+
+	removed, err := synapse.GetNetwork().GetByType(HighFrequencyOfMinorTremors)
+	require.NoError(t, err)
+	require.Empty(t, removed, "unregistered rule no longer derives")
+	alerts, err := synapse.GetNetwork().GetByType("tremor_alert")
+	require.NoError(t, err)
+	require.Len(t, alerts, 1, "remaining rule still derives")
+
+	require.Equal(t, 2, synapse.UnregisterRuleForAllTypes("alert"))
+	require.Equal(t, 0, synapse.UnregisterRuleForAllTypes("alert"))
+
+	_, err = synapse.Ingest(createMinorTremorsEvent(now.Add(2 * time.Minute)))
+	require.NoError(t, err)
+	_, err = synapse.Ingest(createMinorTremorsEvent(now.Add(3 * time.Minute)))
+	require.NoError(t, err)
+	alerts, err = synapse.GetNetwork().GetByType("tremor_alert")
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+}
+
+// blockingRule derives an alert from every event, but only once release is closed.
+type blockingRule struct {
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (r *blockingRule) Process(event Event) (bool, []Event, error) {
+	close(r.entered)
+	<-r.release
+	return true, []Event{event}, nil
+}
+func (r *blockingRule) BindNetwork(EventNetwork)  {}
+func (r *blockingRule) GetActionType() ActionType { return DeriveNode }
+func (r *blockingRule) GetActionTemplate() EventTemplate {
+	return EventTemplate{EventType: "tremor_alert", EventDomain: Geology}
+}
+func (r *blockingRule) GetID() string { return "blocking" }
+
+// cascadeRule derives template from every event, calling onProcess first.
+type cascadeRule struct {
+	template  EventTemplate
+	onProcess func()
+}
+
+func (r *cascadeRule) Process(event Event) (bool, []Event, error) {
+	if r.onProcess != nil {
+		r.onProcess()
+	}
+	return true, []Event{event}, nil
+}
+func (r *cascadeRule) BindNetwork(EventNetwork)         {}
+func (r *cascadeRule) GetActionType() ActionType        { return DeriveNode }
+func (r *cascadeRule) GetActionTemplate() EventTemplate { return r.template }
+func (r *cascadeRule) GetID() string                    { return "cascade-" + r.template.EventType }
+
+func TestSynapseRuntime_IngestCtx(t *testing.T) {
+	t.Run("cancel aborts mid-cascade", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		synapse := NewSynapse([]PatternConfig{})
+		synapse.RegisterRule(MinorTremors, &cascadeRule{template: EventTemplate{EventType: "tremor_alert", EventDomain: Geology}})
+		// The second level cancels while it is being evaluated, so its derivation is never materialized
+		synapse.RegisterRule("tremor_alert", &cascadeRule{template: EventTemplate{EventType: "tremor_escalation", EventDomain: Geology}, onProcess: cancel})
+		synapse.RegisterRule("tremor_escalation", &cascadeRule{template: EventTemplate{EventType: "tremor_emergency", EventDomain: Geology}})
+
+		_, err := synapse.IngestCtx(ctx, createMinorTremorsEvent(time.Now()))
+		require.ErrorIs(t, err, context.Canceled)
+
+		network := synapse.GetNetwork()
+		for eventType, want := range map[EventType]int{MinorTremors: 1, "tremor_alert": 1, "tremor_escalation": 0, "tremor_emergency": 0} {
+			events, err := network.GetByType(eventType)
+			require.NoError(t, err)
+			require.Len(t, events, want, eventType)
+		}
+		require.NoError(t, synapse.Validate(), "every derived event that was stored is fully linked")
+	})
+
+	t.Run("cancelled context stores nothing", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		synapse := NewSynapse([]PatternConfig{})
+		_, err := synapse.IngestCtx(ctx, createMinorTremorsEvent(time.Now()))
+		require.ErrorIs(t, err, context.Canceled)
+
+		events, err := synapse.GetNetwork().GetByType(MinorTremors)
+		require.NoError(t, err)
+		require.Empty(t, events)
+	})
+}
+
+func TestSynapseRuntime_Flush(t *testing.T) {
+	t.Run("waits for in-flight ingests", func(t *testing.T) {
+		synapse := NewSynapse([]PatternConfig{})
+		rule := &blockingRule{entered: make(chan struct{}), release: make(chan struct{})}
+		synapse.RegisterRule(MinorTremors, rule)
+
+		ingested := make(chan error, 1)
+		go func() {
+			_, err := synapse.Ingest(createMinorTremorsEvent(time.Now()))
+			ingested <- err
+		}()
+		<-rule.entered
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, synapse.Flush(ctx), context.DeadlineExceeded)
+
+		flushed := make(chan error, 1)
+		go func() { flushed <- synapse.Flush(context.Background()) }()
+		close(rule.release)
+
+		require.NoError(t, <-flushed)
+		require.NoError(t, <-ingested)
+		alerts, err := synapse.GetNetwork().GetByType("tremor_alert")
+		require.NoError(t, err)
+		require.Len(t, alerts, 1, "the in-flight derivation is not lost")
+	})
+
+	t.Run("idle runtime flushes immediately", func(t *testing.T) {
+		require.NoError(t, NewSynapse([]PatternConfig{}).Flush(context.Background()))
+	})
+
+	t.Run("flushes composition decision logs", func(t *testing.T) {
+		composite := NewCompositePatternListener(nil)
+		synapse := NewSynapse([]PatternConfig{{Depth: 1, MinCount: 1, PatternListener: composite}})
+
+		spec := PatternCompositionSpec{
+			RequiredPatterns: map[PatternIdentifier]struct{}{
+				{EventType: HighFrequencyOfMinorTremors, EventDomain: Geology}: {},
+			},
+			DerivedEventTemplate: EventTemplate{
+				EventType:   PotentialNaturalCatastrophic,
+				EventDomain: NaturalDisasterWarningSystem,
+			},
+			CompositionID: "buffered",
+		}
+		var out bytes.Buffer
+		log := bufio.NewWriterSize(&out, 64*1024)
+		watcher := NewPatternCompositionWatcher(spec, synapse, &testCompositionListener{})
+		watcher.DecisionLog = log
+		composite.AddCompositionWatcher(watcher)
+
+		watcher.OnPatternRepeated(newTestPatternMatch(HighFrequencyOfMinorTremors, Geology, time.Now()))
+		require.Zero(t, out.Len(), "decision is still buffered")
+
+		require.NoError(t, synapse.Flush(context.Background()))
+		require.Contains(t, out.String(), `"composition_id":"buffered"`)
+		require.Zero(t, log.Buffered())
+	})
+}
+
+func TestSynapseRuntime_RegisterRuleDuringIngest(t *testing.T) {
+	// Run with -race: rules are (un)registered while another goroutine ingests
+	peers := NewCondition().HasPeers(MinorTremors, Conditions{
+		Counter: &Counter{HowMany: 1, HowManyOrMore: true},
+	})
+	synapse := NewSynapse([]PatternConfig{})
+	synapse.RegisterRule(MinorTremors, NewDeriveEventRule("tremors", peers, getMinorTremorDerivedEventTemplate()))
+
+	const n = 50
+	done := make(chan error, 1)
+	go func() {
+		now := time.Now()
+		for i := 0; i < n; i++ {
+			if _, err := synapse.Ingest(createMinorTremorsEvent(now.Add(time.Duration(i) * time.Second))); err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+
+	alert := EventTemplate{EventType: "tremor_alert", EventDomain: Geology}
+	for i := 0; i < n; i++ {
+		id := fmt.Sprintf("alert-%d", i)
+		require.NoError(t, synapse.RegisterRuleE(MinorTremors, NewDeriveEventRule(id, peers, alert)))
+		_, ok := synapse.GetRule(id)
+		require.True(t, ok)
+		if i%2 == 1 {
+			require.True(t, synapse.UnregisterRule(MinorTremors, id))
+		}
+		_ = synapse.ListRules()
+	}
+	require.NoError(t, <-done)
+
+	require.Len(t, synapse.ListRules()[MinorTremors], 1+n/2)
+}
+
+func TestSynapseRuntime_PruneOlderThan(t *testing.T) {
+	peers := NewCondition().HasPeers(MinorTremors, Conditions{
+		Counter: &Counter{HowMany: 1, HowManyOrMore: true},
+	})
+	synapse := newSynapseStoringDuplicates([]PatternConfig{})
+	synapse.RegisterRule(MinorTremors, NewDeriveEventRule("tremors", peers, getMinorTremorDerivedEventTemplate()))
+
+	dayOne := time.Date(2026, 4, 24, 10, 0, 0, 0, time.UTC)
+	dayTwo := dayOne.Add(24 * time.Hour)
+	for _, day := range []time.Time{dayOne, dayTwo} {
+		for _, at := range []time.Time{day, day.Add(5 * time.Minute)} {
+			_, err := synapse.Ingest(createMinorTremorsEvent(at))
+			require.NoError(t, err)
+		}
+	}
+	_, err := synapse.Ingest(Event{EventType: CpuStatusChanged, EventDomain: InfraDomain, Timestamp: dayOne.Add(time.Hour)})
+	require.NoError(t, err)
+
+	network := synapse.GetNetwork()
+	derived, err := network.GetByType(HighFrequencyOfMinorTremors)
+	require.NoError(t, err)
+	require.Len(t, derived, 2)
+
+	pruned, err := synapse.PruneOlderThan(time.Date(2026, 4, 25, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Equal(t, 3, pruned, "both day-one tremors and the cpu event")
+
+	tremors, err := network.GetByType(MinorTremors)
+	require.NoError(t, err)
+	require.Len(t, tremors, 2)
+	for _, ev := range tremors {
+		require.False(t, ev.Timestamp.Before(dayTwo))
+	}
+	cpu, err := network.GetByType(CpuStatusChanged)
+	require.NoError(t, err)
+	require.Empty(t, cpu)
+
+	remaining, err := network.GetByType(HighFrequencyOfMinorTremors)
+	require.NoError(t, err)
+	require.ElementsMatch(t, eventIDs(derived), eventIDs(remaining), "derived events are kept")
+
+	// The day-one derivation lost its contributors; the day-two one is intact
+	for _, d := range remaining {
+		children, err := network.Children(d.ID)
+		require.NoError(t, err)
+		if d.Timestamp.Before(dayTwo) {
+			require.Empty(t, children)
+		} else {
+			require.Len(t, children, 2)
+		}
+	}
+	require.ErrorIs(t, synapse.Validate(), ErrChildlessDerived, "the day-one derivation is reported childless")
+}
+
+func TestSynapseRuntime_RulePriority(t *testing.T) {
+	// Both rules claim the same peers; whichever runs first links them, leaving nothing for the other
+	peers := NewCondition().HasPeers(MinorTremors, Conditions{
+		Counter: &Counter{HowMany: 1, HowManyOrMore: true},
+	})
+	derive := func(coarsePriority int) []EventType {
+		synapse := newSynapseStoringDuplicates([]PatternConfig{})
+		synapse.RegisterRule(MinorTremors, NewDeriveEventRule("fine", peers,
+			EventTemplate{EventType: "fine", EventDomain: "alerts"}))
+		coarse := NewDeriveEventRule("coarse", peers,
+			EventTemplate{EventType: "coarse", EventDomain: "alerts"})
+		coarse.Priority = coarsePriority
+		synapse.RegisterRule(MinorTremors, coarse)
+
+		now := time.Now()
+		_, err := synapse.Ingest(createMinorTremorsEvent(now))
+		require.NoError(t, err)
+		_, err = synapse.Ingest(createMinorTremorsEvent(now.Add(time.Minute)))
+		require.NoError(t, err)
+
+		derived, err := synapse.GetNetwork().GetByDomain("alerts")
+		require.NoError(t, err)
+		types := make([]EventType, 0, len(derived))
+		for _, ev := range derived {
+			types = append(types, ev.EventType)
+		}
+		return types
+	}
+
+	require.Equal(t, []EventType{"fine"}, derive(0), "equal priorities keep registration order")
+	require.Equal(t, []EventType{"coarse"}, derive(10), "higher priority runs first")
+}
+
+func TestSynapseRuntime_ConflictResolver(t *testing.T) {
+	base := time.Date(2026, 4, 25, 5, 0, 0, 0, time.UTC)
+	tremor := func(at time.Time, magnitude float64) Event {
+		ev := createMinorTremorsEvent(at)
+		ev.Properties = EventProps{"magnitude": magnitude}
+		return ev
+	}
+	ingestTwice := func(resolver ConflictResolver, second Event) (*SynapseRuntime, EventID, EventID) {
+		synapse := NewSynapse([]PatternConfig{})
+		synapse.ConflictResolver = resolver
+		first, err := synapse.Ingest(tremor(base, 2.1))
+		require.NoError(t, err)
+		again, err := synapse.Ingest(second)
+		require.NoError(t, err)
+		return synapse, first, again
+	}
+	stored := func(synapse *SynapseRuntime) []Event {
+		events, err := synapse.GetNetwork().GetByType(MinorTremors)
+		require.NoError(t, err)
+		return events
+	}
+
+	t.Run("no resolver stores every ingest", func(t *testing.T) {
+		synapse, first, again := ingestTwice(nil, tremor(base.Add(time.Minute), 2.1))
+		require.NotEqual(t, first, again)
+		require.Len(t, stored(synapse), 2)
+	})
+
+	t.Run("NewSynapse keeps the existing event by default", func(t *testing.T) {
+		synapse := NewSynapse([]PatternConfig{})
+		first, err := synapse.Ingest(tremor(base, 2.1))
+		require.NoError(t, err)
+		again, err := synapse.Ingest(tremor(base.Add(time.Minute), 2.1))
+		require.NoError(t, err)
+		require.Equal(t, first, again)
+		require.Len(t, stored(synapse), 1)
+	})
+
+	t.Run("numbers decoded from JSON collide with int literals", func(t *testing.T) {
+		ev := createMinorTremorsEvent(base)
+		ev.Properties = EventProps{"magnitude": 3, "station": map[string]any{"id": int64(7)}}
+		decoded := createMinorTremorsEvent(base.Add(time.Minute))
+		decoded.Properties = EventProps{"magnitude": float64(3), "station": map[string]any{"id": float64(7)}}
+		require.Equal(t, ContentHash(ev), ContentHash(decoded))
+
+		synapse := NewSynapse([]PatternConfig{})
+		first, err := synapse.Ingest(ev)
+		require.NoError(t, err)
+		again, err := synapse.Ingest(decoded)
+		require.NoError(t, err)
+		require.Equal(t, first, again)
+	})
+
+	t.Run("keep existing", func(t *testing.T) {
+		synapse, first, again := ingestTwice(KeepExisting, tremor(base.Add(time.Minute), 2.1))
+		require.Equal(t, first, again)
+		events := stored(synapse)
+		require.Len(t, events, 1)
+		require.Equal(t, base, events[0].Timestamp)
+	})
+
+	t.Run("replace with incoming", func(t *testing.T) {
+		synapse, first, again := ingestTwice(ReplaceWithIncoming, tremor(base.Add(time.Minute), 2.1))
+		require.Equal(t, first, again)
+		events := stored(synapse)
+		require.Len(t, events, 1)
+		require.Equal(t, base.Add(time.Minute), events[0].Timestamp)
+		byID, err := synapse.GetNetwork().GetByID(first)
+		require.NoError(t, err)
+		require.Equal(t, base.Add(time.Minute), byID.Timestamp)
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		merge := func(existing, incoming Event) (Event, bool) {
+			merged, _ := MergeProperties(existing, incoming)
+			merged.Properties["deliveries"] = 2
+			return merged, true
+		}
+		synapse, first, again := ingestTwice(merge, tremor(base.Add(-time.Minute), 2.1))
+		require.Equal(t, first, again)
+		events := stored(synapse)
+		require.Len(t, events, 1)
+		require.Equal(t, base, events[0].Timestamp, "the later timestamp wins")
+		require.Equal(t, EventProps{"magnitude": 2.1, "deliveries": 2}, events[0].Properties)
+	})
+
+	t.Run("different content is not a collision", func(t *testing.T) {
+		synapse, first, again := ingestTwice(KeepExisting, tremor(base.Add(time.Minute), 3.4))
+		require.NotEqual(t, first, again)
+		require.Len(t, stored(synapse), 2)
+	})
+
+	t.Run("concurrent duplicates are stored once", func(t *testing.T) {
+		synapse := NewSynapse([]PatternConfig{})
+		synapse.ConflictResolver = KeepExisting
+
+		const workers = 16
+		ids := make(chan EventID, workers)
+		errs := make(chan error, workers)
+		var wg sync.WaitGroup
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				id, err := synapse.Ingest(tremor(base.Add(time.Duration(i)*time.Second), 2.1))
+				errs <- err
+				ids <- id
+			}(i)
+		}
+		wg.Wait()
+		close(errs)
+		close(ids)
+
+		for err := range errs {
+			require.NoError(t, err)
+		}
+		events := stored(synapse)
+		require.Len(t, events, 1)
+		for id := range ids {
+			require.Equal(t, events[0].ID, id)
+		}
+	})
+
+	t.Run("index limit forgets the oldest content", func(t *testing.T) {
+		synapse := NewSynapse([]PatternConfig{})
+		synapse.ConflictResolver = KeepExisting
+		synapse.ContentIndexLimit = 2
+
+		for _, magnitude := range []float64{1, 2, 3} {
+			_, err := synapse.Ingest(tremor(base, magnitude))
+			require.NoError(t, err)
+		}
+		require.Len(t, synapse.content.ids, 2)
+
+		// 1 was forgotten and is stored again; 3 is still deduplicated
+		_, err := synapse.Ingest(tremor(base, 1))
+		require.NoError(t, err)
+		_, err = synapse.Ingest(tremor(base, 3))
+		require.NoError(t, err)
+		require.Len(t, stored(synapse), 4)
+		require.Len(t, synapse.content.ids, 2)
+	})
+
+	t.Run("retracted events leave the index", func(t *testing.T) {
+		synapse := NewSynapse([]PatternConfig{})
+		synapse.ConflictResolver = KeepExisting
+		_, err := synapse.Ingest(tremor(base, 2.1))
+		require.NoError(t, err)
+
+		removed, err := synapse.PruneOlderThan(base.Add(time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, 1, removed)
+		require.Empty(t, synapse.content.ids)
+	})
+}
+
+func TestSynapseRuntime_IngestWithResult(t *testing.T) {
+	synapse := NewSynapse([]PatternConfig{})
+	synapse.RegisterRule(CpuStatusChanged, NewDeriveEventRule("cpu_status_critical",
+		NewCondition().HasPeers(CpuStatusChanged, Conditions{
+			Counter: &Counter{HowMany: 1, HowManyOrMore: true},
+		}),
+		EventTemplate{EventType: CpuCritical, EventDomain: InfraDomain},
+	))
+
+	first, err := synapse.IngestWithResult(createCpuStatusChangedEvent(91, "critical"))
+	require.NoError(t, err)
+	require.NotEqual(t, uuid.UUID{}, first.EventID)
+	require.Empty(t, first.Derived)
+	require.Empty(t, first.RuleIDs)
+
+	second, err := synapse.IngestWithResult(createCpuStatusChangedEvent(95, "critical"))
+	require.NoError(t, err)
+	require.Len(t, second.Derived, 1)
+	require.Equal(t, EventType(CpuCritical), second.Derived[0].EventType)
+	require.Equal(t, []string{"cpu_status_critical"}, second.RuleIDs)
+
+	children, err := synapse.GetNetwork().Children(second.Derived[0].ID)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []EventID{first.EventID, second.EventID}, collectIDs(children))
+}
+
+// panickingObserver is a misbehaving pattern watcher
+type panickingObserver struct{}
+
+func (panickingObserver) OnMaterialized(Event, []Event, string) {
+	panic("watcher bug")
+}
+
+func TestSynapseRuntime_WatcherPanicRecovered(t *testing.T) {
+	synapse := NewSynapse([]PatternConfig{})
+	recorder := &recordingObserver{}
+	synapse.PatternWatcher = append(synapse.PatternWatcher, panickingObserver{}, recorder)
+	synapse.RegisterRule(CpuStatusChanged, NewDeriveEventRule("cpu_status_critical",
+		NewCondition().HasPeers(CpuStatusChanged, Conditions{
+			Counter: &Counter{HowMany: 1, HowManyOrMore: true},
+		}),
+		EventTemplate{EventType: CpuCritical, EventDomain: InfraDomain},
+	))
+
+	_, err := synapse.Ingest(createCpuStatusChangedEvent(91, "critical"))
+	require.NoError(t, err)
+	result, err := synapse.IngestWithResult(createCpuStatusChangedEvent(95, "critical"))
+	require.NoError(t, err)
+	require.Len(t, result.Derived, 1)
+
+	require.Len(t, result.WatcherErrors, 1)
+	require.ErrorIs(t, result.WatcherErrors[0], ErrWatcherPanic)
+	require.ErrorContains(t, result.WatcherErrors[0], "watcher bug")
+	require.Len(t, recorder.ofType(CpuCritical), 1, "later watchers still run")
+
+	t.Run("MaterializeWithoutRules returns the stored event", func(t *testing.T) {
+		derived, err := synapse.MaterializeWithoutRules(Event{
+			EventType:   ServerNodeChangeStatus,
+			EventDomain: InfraDomain,
+			Timestamp:   time.Now(),
+		}, []EventID{result.Derived[0].ID}, "trigger", "manual")
+		require.ErrorIs(t, err, ErrWatcherPanic)
+		_, gerr := synapse.GetNetwork().GetByID(derived.ID)
+		require.NoError(t, gerr)
+		require.Len(t, recorder.ofType(ServerNodeChangeStatus), 1)
+	})
+}
+
+func TestMultiObserver_IsolatesPanics(t *testing.T) {
+	recorder := &recordingObserver{}
+	multi := NewMultiObserver(panickingObserver{}, nil, recorder)
+	derived := Event{ID: uuid.New(), EventType: CpuCritical, EventDomain: InfraDomain}
+
+	err := multi.OnMaterializedErr(derived, nil, "rule")
+	require.ErrorIs(t, err, ErrWatcherPanic)
+	require.Len(t, recorder.ofType(CpuCritical), 1, "the recording observer still sees the materialization")
+
+	require.NotPanics(t, func() {
+		multi.OnMaterialized(derived, nil, "rule")
+	})
+	require.Len(t, recorder.ofType(CpuCritical), 2)
+
+	t.Run("registered as one watcher", func(t *testing.T) {
+		recorder := &recordingObserver{}
+		synapse := NewSynapse([]PatternConfig{})
+		synapse.PatternWatcher = []PatternObserver{NewMultiObserver(panickingObserver{}, recorder)}
+		synapse.RegisterRule(CpuStatusChanged, &cascadeRule{
+			template: EventTemplate{EventType: CpuCritical, EventDomain: InfraDomain},
+		})
+
+		result, err := synapse.IngestWithResult(createCpuStatusChangedEvent(95, "critical"))
+		require.NoError(t, err)
+		require.Len(t, result.WatcherErrors, 1)
+		require.ErrorIs(t, result.WatcherErrors[0], ErrWatcherPanic)
+		require.Len(t, recorder.ofType(CpuCritical), 1)
+	})
+}
+
+func TestSynapseRuntime_TopContributors(t *testing.T) {
+	// ladder level_0 -> level_1 -> level_2 -> level_3, plus a side leaf feeding level_3 only
+	net, ids := buildLinearChain(t, 4)
+	side, err := net.AddEvent(Event{EventType: "side", EventDomain: InfraDomain})
+	require.NoError(t, err)
+	require.NoError(t, net.AddEdge(side, ids[3], "trigger"))
+
+	synapse := NewSynapse([]PatternConfig{})
+	synapse.Network = net
+
+	ranks := synapse.TopContributors(0)
+	require.Len(t, ranks, 4, "the top of the ladder feeds nothing")
+	require.Equal(t, ids[0], ranks[0].Event.ID, "the leaf feeding the whole chain ranks first")
+	require.Equal(t, ContributorRank{Event: ranks[0].Event, Parents: 1, Downstream: 3, Score: 4}, ranks[0])
+	require.Equal(t, ids[1], ranks[1].Event.ID)
+	require.Equal(t, 3, ranks[1].Score)
+
+	top := synapse.TopContributors(2)
+	require.Len(t, top, 2)
+	require.Equal(t, []EventID{ids[0], ids[1]}, []EventID{top[0].Event.ID, top[1].Event.ID})
+}
+
+func TestSynapseRuntime_IngestBatch(t *testing.T) {
+	base := time.Date(2026, 4, 25, 5, 0, 0, 0, time.UTC)
+	newSynapse := func() *SynapseRuntime {
+		synapse := newSynapseStoringDuplicates([]PatternConfig{})
+		// pairs of tremors within an hour
+		synapse.RegisterRule(MinorTremors, NewDeriveEventRule("tremor_pair",
+			NewCondition().HasPeers(MinorTremors, Conditions{
+				Counter:    &Counter{HowMany: 1},
+				TimeWindow: &TimeWindow{Within: 1, TimeUnit: Hour},
+			}),
+			getMinorTremorDerivedEventTemplate(),
+		))
+		return synapse
+	}
+	pairs := func(synapse *SynapseRuntime) [][]time.Time {
+		derived, err := synapse.GetNetwork().GetByType(HighFrequencyOfMinorTremors)
+		require.NoError(t, err)
+		var out [][]time.Time
+		for _, d := range derived {
+			children, err := synapse.GetNetwork().Children(d.ID)
+			require.NoError(t, err)
+			pair := []time.Time{children[0].Timestamp, children[1].Timestamp}
+			sort.Slice(pair, func(i, j int) bool { return pair[i].Before(pair[j]) })
+			out = append(out, pair)
+		}
+		return out
+	}
+
+	offsets := []time.Duration{30 * time.Minute, 0, 20 * time.Minute, 10 * time.Minute}
+	events := make([]Event, len(offsets))
+	for i, offset := range offsets {
+		events[i] = createMinorTremorsEvent(base.Add(offset))
+	}
+
+	// In caller order the windows only look back, so 0m pairs with 20m and the rest never pair
+	sequential := newSynapse()
+	for _, ev := range events {
+		_, err := sequential.Ingest(ev)
+		require.NoError(t, err)
+	}
+	require.Equal(t, [][]time.Time{{base, base.Add(20 * time.Minute)}}, pairs(sequential))
+
+	batch := newSynapse()
+	ids, err := batch.IngestBatch(events)
+	require.NoError(t, err)
+	require.Len(t, ids, len(events))
+	for i, id := range ids {
+		stored, err := batch.GetNetwork().GetByID(id)
+		require.NoError(t, err)
+		require.Equal(t, events[i].Timestamp, stored.Timestamp, "IDs are in input order")
+	}
+	require.ElementsMatch(t, [][]time.Time{
+		{base, base.Add(10 * time.Minute)},
+		{base.Add(20 * time.Minute), base.Add(30 * time.Minute)},
+	}, pairs(batch), "derivations match the time-sorted outcome")
+
+	_, err = newSynapse().IngestBatch([]Event{createMinorTremorsEvent(base), {EventDomain: Geology}})
+	require.ErrorContains(t, err, "event 1")
+}
+
+func TestSynapseRuntime_AnnotateRule(t *testing.T) {
+	synapse := NewSynapse([]PatternConfig{})
+	synapse.RegisterRule(CpuStatusChanged, NewAnnotateRule("review_repeats",
+		NewCondition().HasPeers(CpuStatusChanged, Conditions{
+			Counter: &Counter{HowMany: 1, HowManyOrMore: true},
+		}),
+		EventProps{"reviewed": true, "level": "triaged"},
+	))
+
+	first, err := synapse.Ingest(createCpuStatusChangedEvent(91, "critical"))
+	require.NoError(t, err)
+	stored, err := synapse.GetNetwork().GetByID(first)
+	require.NoError(t, err)
+	require.NotContains(t, stored.Properties, "reviewed", "no peers yet")
+
+	second, err := synapse.Ingest(createCpuStatusChangedEvent(95, "critical"))
+	require.NoError(t, err)
+
+	for id, percentage := range map[EventID]float64{first: 91, second: 95} {
+		stored, err := synapse.GetNetwork().GetByID(id)
+		require.NoError(t, err)
+		require.Equal(t, EventProps{"percentage": percentage, "level": "triaged", "reviewed": true}, stored.Properties)
+	}
+
+	all, err := synapse.GetNetwork().GetByTimeRange(time.Time{}, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, all, 2, "no node is created")
+	parents, err := synapse.GetNetwork().Parents(first)
+	require.NoError(t, err)
+	require.Empty(t, parents, "no edge is created")
+
+	// Cached peer cohorts see the annotation
+	ok, peers, err := NewExpression(synapse.EvalNetwork, &stored).
+		HasPeers(CpuStatusChanged, Conditions{PropertyValues: map[string]any{"reviewed": true}}).
+		Eval()
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Len(t, peers, 1)
+}
+
+func TestSynapseRuntime_DeriveEdgeRule(t *testing.T) {
+	synapse := NewSynapse([]PatternConfig{})
+	synapse.RegisterRule(CpuStatusChanged, NewDeriveEdgeRule("correlate",
+		NewCondition().HasPeers(CpuStatusChanged, Conditions{
+			Counter: &Counter{HowMany: 2, HowManyOrMore: true},
+		}),
+		"correlated",
+	))
+
+	first, err := synapse.Ingest(createCpuStatusChangedEvent(91, "critical"))
+	require.NoError(t, err)
+	second, err := synapse.Ingest(createCpuStatusChangedEvent(93, "critical"))
+	require.NoError(t, err)
+	third, err := synapse.Ingest(createCpuStatusChangedEvent(95, "critical"))
+	require.NoError(t, err)
+
+	in, err := synapse.GetNetwork().InEdges(third)
+	require.NoError(t, err)
+	require.Len(t, in, 2)
+	for _, edge := range in {
+		require.Equal(t, "correlated", edge.Relation)
+		require.Contains(t, []EventID{first, second}, edge.From)
+	}
+	out, err := synapse.GetNetwork().OutEdges(first)
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+	require.Equal(t, third, out[0].To)
+	require.Equal(t, "correlated", out[0].Relation)
+
+	siblings, err := synapse.GetNetwork().Siblings(first)
+	require.NoError(t, err)
+	require.Equal(t, []EventID{second}, collectIDs(siblings))
+
+	all, err := synapse.GetNetwork().GetByTimeRange(time.Time{}, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, all, 3, "no node is created")
+}
+
+func TestSynapseRuntime_ListRules(t *testing.T) {
+	peers := NewCondition().HasPeers(MinorTremors, Conditions{
+		Counter: &Counter{HowMany: 1, HowManyOrMore: true},
+	})
+	tremors := NewDeriveEventRule("tremors", peers, getMinorTremorDerivedEventTemplate())
+	alert := NewDeriveEventRule("alert", peers, EventTemplate{EventType: "tremor_alert", EventDomain: Geology})
+	alert.Priority = 1
+
+	synapse := NewSynapse([]PatternConfig{})
+	require.Empty(t, synapse.ListRules())
+
+	synapse.RegisterRule(MinorTremors, tremors)
+	synapse.RegisterRuleForTypes([]EventType{MinorTremors, "aftershock"}, alert)
+
+	require.Equal(t, map[EventType][]string{
+		MinorTremors: {"alert", "tremors"},
+		"aftershock": {"alert"},
+	}, synapse.ListRules())
+
+	rule, ok := synapse.GetRule("alert")
+	require.True(t, ok)
+	require.Same(t, alert, rule)
+	_, ok = synapse.GetRule("unknown")
+	require.False(t, ok)
+}
+
+func TestSynapseRuntime_RegisterRuleE_DuplicateID(t *testing.T) {
+	peers := NewCondition().HasPeers(MinorTremors, Conditions{
+		Counter: &Counter{HowMany: 1, HowManyOrMore: true},
+	})
+	newRule := func(id string) *DeriveEventRule {
+		return NewDeriveEventRule(id, peers, getMinorTremorDerivedEventTemplate())
+	}
+
+	synapse := NewSynapse([]PatternConfig{})
+	require.NoError(t, synapse.RegisterRuleE(MinorTremors, newRule("2")))
+
+	err := synapse.RegisterRuleE(MinorTremors, newRule("2"))
+	require.ErrorIs(t, err, ErrDuplicateRule)
+	require.EqualError(t, err, `duplicate rule ID: rule "2" is already registered for minor_tremors`)
+
+	// Same ID under another type is fine
+	require.NoError(t, synapse.RegisterRuleE("aftershock", newRule("2")))
+
+	// A multi-type registration conflicting on one type registers nowhere
+	err = synapse.RegisterRuleForTypesE([]EventType{"swarm", MinorTremors}, newRule("2"))
+	require.ErrorIs(t, err, ErrDuplicateRule)
+	require.Equal(t, map[EventType][]string{
+		MinorTremors: {"2"},
+		"aftershock": {"2"},
+	}, synapse.ListRules())
+}
+
+func TestSynapseRuntime_FullStats(t *testing.T) {
+	composite := NewCompositePatternListener(nil)
+	synapse := NewSynapse([]PatternConfig{{
+		Depth:           1,
+		MinCount:        1,
+		PatternListener: composite,
+		Spec:            WatchSpec{DerivedTypes: map[EventType]struct{}{CpuCritical: {}}},
+	}})
+	synapse.RegisterRule(CpuStatusChanged, NewDeriveEventRule("cpu_critical",
+		NewCondition().HasPeers(CpuStatusChanged, Conditions{
+			Counter: &Counter{HowMany: 1, HowManyOrMore: true},
+		}),
+		EventTemplate{EventType: CpuCritical, EventDomain: InfraDomain},
+	))
+
+	triggerRules := false
+	compositions := &testCompositionListener{}
+	composite.AddCompositionWatcher(NewPatternCompositionWatcher(PatternCompositionSpec{
+		RequiredPatterns: map[PatternIdentifier]struct{}{
+			{EventType: CpuCritical, EventDomain: InfraDomain}: {},
+		},
+		DerivedEventTemplate: EventTemplate{EventType: ServerNodeChangeStatus, EventDomain: InfraDomain},
+		CompositionID:        "cpu-escalation",
+		TriggerRules:         &triggerRules,
+	}, synapse, compositions))
+
+	empty := synapse.FullStats()
+	require.Zero(t, empty.Network.Events)
+	require.Zero(t, empty.Memory.Lineages)
+	require.Equal(t, []WatcherStats{{Depth: 1, MinCount: 1}}, empty.Watchers)
+	require.Equal(t, []CompositionStats{{CompositionID: "cpu-escalation"}}, empty.Compositions)
+
+	for _, value := range []float64{91, 95} {
+		_, err := synapse.Ingest(createCpuStatusChangedEvent(value, "critical"))
+		require.NoError(t, err)
+	}
+	require.Equal(t, 1, compositions.Count())
+
+	// two cpu events -> cpu_critical -> composed server_node_change_status
+	stats := synapse.FullStats()
+	require.Equal(t, NetworkStats{
+		Events: 4,
+		Edges:  3,
+		EventsByType: map[EventType]int{
+			CpuStatusChanged:       2,
+			CpuCritical:            1,
+			ServerNodeChangeStatus: 1,
+		},
+		EventsByDomain: map[EventDomain]int{InfraDomain: 4},
+		EdgesByRelation: map[string]int{
+			"trigger:cpu_critical": 2,
+			"pattern_composition":  1,
+		},
+	}, stats.Network)
+	require.Equal(t, 2, stats.Memory.Motifs, "cpu_critical and the composition")
+	require.Positive(t, stats.Memory.Lineages)
+	require.Equal(t, synapse.Memory.GlobalRev(), stats.Memory.GlobalRev)
+	require.Equal(t, []WatcherStats{{Depth: 1, MinCount: 1, Fired: 1}}, stats.Watchers)
+	require.Equal(t, []CompositionStats{{CompositionID: "cpu-escalation", Fired: 1}}, stats.Compositions)
+}
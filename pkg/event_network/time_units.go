@@ -7,6 +7,7 @@ type TimeUnit string
 const (
 	Year        TimeUnit = "year"
 	Month       TimeUnit = "month"
+	Week        TimeUnit = "week"
 	Day         TimeUnit = "day"
 	Hour        TimeUnit = "hour"
 	Minute      TimeUnit = "minute"
@@ -15,14 +16,50 @@ const (
 	Microsecond TimeUnit = "microsecond"
 )
 
+// ToDuration converts n units to a duration, with 30-day months and 365-day years.
+// Use a TimeUnitConverter for other calendars.
 func (m TimeUnit) ToDuration(n int) time.Duration {
-	switch m {
+	return TimeUnitConverter{}.ToDuration(m, n)
+}
+
+// Default calendar lengths used by TimeUnit.ToDuration.
+const (
+	DefaultDaysPerMonth = 30
+	DefaultDaysPerYear  = 365
+)
+
+// TimeUnitConverter converts TimeUnits to durations with configurable month and year
+// lengths, e.g. 28-day billing cycles. The zero value uses the defaults.
+type TimeUnitConverter struct {
+	// DaysPerMonth is the length of a Month; <= 0 means DefaultDaysPerMonth.
+	DaysPerMonth int
+	// DaysPerYear is the length of a Year; <= 0 means DefaultDaysPerYear.
+	DaysPerYear int
+}
+
+// WithDaysPerMonth returns a copy of c with d-day months.
+func (c TimeUnitConverter) WithDaysPerMonth(d int) TimeUnitConverter {
+	c.DaysPerMonth = d
+	return c
+}
+
+// WithDaysPerYear returns a copy of c with d-day years.
+func (c TimeUnitConverter) WithDaysPerYear(d int) TimeUnitConverter {
+	c.DaysPerYear = d
+	return c
+}
+
+func (c TimeUnitConverter) ToDuration(unit TimeUnit, n int) time.Duration {
+	const day = time.Hour * 24
+	switch unit {
 	case Year:
-		return time.Hour * 24 * 365 * time.Duration(n)
+		return day * time.Duration(orDefault(c.DaysPerYear, DefaultDaysPerYear)) * time.Duration(n)
 	case Month:
-		return time.Hour * 24 * 30 * time.Duration(n)
+		return day * time.Duration(orDefault(c.DaysPerMonth, DefaultDaysPerMonth)) * time.Duration(n)
+	case Week:
+		return day * 7 * time.Duration(n)
 	case Day:
-		return time.Hour * 24 * time.Duration(n)
+		return day * time.Duration(n)
 	case Hour:
 		return time.Hour * time.Duration(n)
 	case Minute:
@@ -37,3 +74,10 @@ func (m TimeUnit) ToDuration(n int) time.Duration {
 		return 0
 	}
 }
+
+func orDefault(v, def int) int {
+	if v <= 0 {
+		return def
+	}
+	return v
+}
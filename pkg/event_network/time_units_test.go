@@ -11,6 +11,7 @@ import (
 func TestTimeUnitConstants(t *testing.T) {
 	require.Equal(t, TimeUnit("year"), Year)
 	require.Equal(t, TimeUnit("month"), Month)
+	require.Equal(t, TimeUnit("week"), Week)
 	require.Equal(t, TimeUnit("day"), Day)
 	require.Equal(t, TimeUnit("hour"), Hour)
 	require.Equal(t, TimeUnit("minute"), Minute)
@@ -61,6 +62,27 @@ func TestTimeUnit_ToDuration_Month(t *testing.T) {
 	}
 }
 
+// Tests for Week conversion
+func TestTimeUnit_ToDuration_Week(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    int
+		expected time.Duration
+	}{
+		{"One week", 1, time.Hour * 24 * 7},
+		{"Two weeks", 2, time.Hour * 24 * 7 * 2},
+		{"Fifty-two weeks", 52, time.Hour * 24 * 7 * 52},
+		{"Zero weeks", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Week.ToDuration(tt.input)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
 // Tests for Day conversion
 func TestTimeUnit_ToDuration_Day(t *testing.T) {
 	tests := []struct {
@@ -254,6 +276,11 @@ func TestTimeUnit_Equivalence(t *testing.T) {
 	oneDay := Day.ToDuration(1)
 	require.Equal(t, oneDay, twentyFourHours)
 
+	// Test that 7 days equals 1 week
+	sevenDays := Day.ToDuration(7)
+	oneWeek := Week.ToDuration(1)
+	require.Equal(t, oneWeek, sevenDays)
+
 	// Test that 1000 milliseconds equals 1 second
 	thousandMilliseconds := Millisecond.ToDuration(1000)
 	oneSecond := Second.ToDuration(1)
@@ -269,13 +296,15 @@ func TestTimeUnit_Ordering(t *testing.T) {
 	// Verify that larger time units produce larger durations for same input
 	oneYear := Year.ToDuration(1)
 	oneMonth := Month.ToDuration(1)
+	oneWeek := Week.ToDuration(1)
 	oneDay := Day.ToDuration(1)
 	oneHour := Hour.ToDuration(1)
 	oneMinute := Minute.ToDuration(1)
 	oneSecond := Second.ToDuration(1)
 
 	require.Greater(t, oneYear, oneMonth)
-	require.Greater(t, oneMonth, oneDay)
+	require.Greater(t, oneMonth, oneWeek)
+	require.Greater(t, oneWeek, oneDay)
 	require.Greater(t, oneDay, oneHour)
 	require.Greater(t, oneHour, oneMinute)
 	require.Greater(t, oneMinute, oneSecond)
@@ -289,13 +318,35 @@ func TestTimeUnit_ReceiverMethodPattern(t *testing.T) {
 	require.Equal(t, time.Hour*3, result)
 
 	// Test with different units
-	units := []TimeUnit{Year, Month, Day, Hour, Minute, Second, Millisecond, Microsecond}
+	units := []TimeUnit{Year, Month, Week, Day, Hour, Minute, Second, Millisecond, Microsecond}
 	for _, u := range units {
 		result := u.ToDuration(1)
 		require.NotEqual(t, time.Duration(0), result)
 	}
 }
 
+func TestTimeUnitConverter(t *testing.T) {
+	day := time.Hour * 24
+
+	// The zero value matches TimeUnit.ToDuration
+	for _, u := range []TimeUnit{Year, Month, Week, Day, Hour, Minute, Second, Millisecond, Microsecond} {
+		require.Equal(t, u.ToDuration(3), TimeUnitConverter{}.ToDuration(u, 3))
+	}
+
+	billing := TimeUnitConverter{}.WithDaysPerMonth(28)
+	require.Equal(t, 28*day, billing.ToDuration(Month, 1))
+	require.Equal(t, 3*28*day, billing.ToDuration(Month, 3))
+	require.Equal(t, 365*day, billing.ToDuration(Year, 1), "years keep the default")
+	require.Equal(t, 7*day, billing.ToDuration(Week, 1))
+
+	lunar := billing.WithDaysPerYear(13 * 28)
+	require.Equal(t, 364*day, lunar.ToDuration(Year, 1))
+	require.Equal(t, 28*day, lunar.ToDuration(Month, 1))
+	require.Equal(t, 365*day, billing.ToDuration(Year, 1), "With* returns a copy")
+
+	require.Equal(t, 30*day, TimeUnitConverter{}.WithDaysPerMonth(0).ToDuration(Month, 1))
+}
+
 // Benchmark tests for conversion performance
 func BenchmarkTimeUnit_ToDuration_Year(b *testing.B) {
 	for i := 0; i < b.N; i++ {